@@ -6,6 +6,7 @@ import (
 	"os"
 
 	"github.com/st3v3nmw/sourcerer-mcp/internal/analyzer"
+	"github.com/st3v3nmw/sourcerer-mcp/internal/index"
 )
 
 func main() {
@@ -23,7 +24,7 @@ func main() {
 	types := []string{"memory", "docs", "src", "tests"}
 
 	for _, fileType := range types {
-		results, err := a.SemanticSearch(context.Background(), "*", []string{fileType})
+		results, err := a.SemanticSearch(context.Background(), "*", index.SearchOptions{FileTypes: []string{fileType}})
 		if err != nil {
 			fmt.Printf("Error searching type %s: %v\n", fileType, err)
 			continue
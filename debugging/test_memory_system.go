@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"time"
 	"github.com/st3v3nmw/sourcerer-mcp/internal/analyzer"
+	"github.com/st3v3nmw/sourcerer-mcp/internal/index"
 )
 
 func main() {
@@ -48,20 +49,20 @@ func main() {
 // After indexing, before searching - add this:
 fmt.Println("\n3.5. Testing what file_types find the content...")
 
-docsResults, _ := a.SemanticSearch(context.Background(), "design choices", []string{"docs"})
+docsResults, _ := a.SemanticSearch(context.Background(), "design choices", index.SearchOptions{FileTypes: []string{"docs"}})
 fmt.Printf("   Searching file_types=[\"docs\"]: Found %d results\n", len(docsResults))
 
-memResults, _ := a.SemanticSearch(context.Background(), "design choices", []string{"memory"})  
+memResults, _ := a.SemanticSearch(context.Background(), "design choices", index.SearchOptions{FileTypes: []string{"memory"}})  
 fmt.Printf("   Searching file_types=[\"memory\"]: Found %d results\n", len(memResults))
 
 
-srcResults, _ := a.SemanticSearch(context.Background(), "design choices", []string{"src"})
+srcResults, _ := a.SemanticSearch(context.Background(), "design choices", index.SearchOptions{FileTypes: []string{"src"}})
 fmt.Printf("   Searching file_types=[\"src\"]: Found %d results\n", len(srcResults))
 
 
 
 // After line "3.5. Testing what file_types find the content..."
-memResults, _ = a.SemanticSearch(context.Background(), "design choices", []string{"memory"})
+memResults, _ = a.SemanticSearch(context.Background(), "design choices", index.SearchOptions{FileTypes: []string{"memory"}})
 fmt.Printf("   Memory results: %d\n", len(memResults))
 for i, r := range memResults {
     preview := r
@@ -71,7 +72,7 @@ for i, r := range memResults {
     fmt.Printf("     [%d] %s\n", i+1, preview)
 }
 
-srcResults, _ = a.SemanticSearch(context.Background(), "design choices", []string{"src"})
+srcResults, _ = a.SemanticSearch(context.Background(), "design choices", index.SearchOptions{FileTypes: []string{"src"}})
 fmt.Printf("   Src results: %d\n", len(srcResults))
 for i, r := range srcResults {
     preview := r
@@ -85,7 +86,7 @@ for i, r := range srcResults {
 
 	// Test semantic search for memory
 	fmt.Println("\n4. Testing semantic search for project decisions...")
-	results, err := a.SemanticSearch(context.Background(), "why did we choose Python", []string{"memory"})
+	results, err := a.SemanticSearch(context.Background(), "why did we choose Python", index.SearchOptions{FileTypes: []string{"memory"}})
 	if err != nil {
 		fmt.Printf("❌ FAILED: Search failed: %v\n", err)
 		os.Exit(1)
@@ -106,7 +107,7 @@ for i, r := range srcResults {
 	
 	// Test broader search
 	fmt.Println("\n6. Testing broader semantic search (all file types)...")
-	allResults, err := a.SemanticSearch(context.Background(), "Python fuzzer", []string{"src", "docs", "memory"})
+	allResults, err := a.SemanticSearch(context.Background(), "Python fuzzer", index.SearchOptions{FileTypes: []string{"src", "docs", "memory"}})
 	if err != nil {
 		fmt.Printf("❌ FAILED: Broader search failed: %v\n", err)
 		os.Exit(1)
@@ -2,10 +2,13 @@ package analyzer
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/st3v3nmw/sourcerer-mcp/internal/fs"
@@ -22,6 +25,8 @@ type Analyzer struct {
 	indexMu       sync.RWMutex
 	nPendingFiles int
 	lastIndexedAt time.Time
+
+	lastSearchChange atomic.Uint64 // index change sequence at the last search
 }
 
 func New(ctx context.Context, workspaceRoot string) (*Analyzer, error) {
@@ -84,7 +89,11 @@ func (a *Analyzer) processFiles(ctx context.Context, filePaths []string) {
 
 	a.nPendingFiles = len(filePaths)
 	for _, filePath := range filePaths {
-		a.chunk(ctx, filePath)
+		// Files the embedder couldn't take are queued and retried by the index
+		err := a.chunk(ctx, filePath)
+		if err != nil && !errors.Is(err, index.ErrEmbeddingQueued) {
+			fmt.Fprintf(os.Stderr, "Sourcerer: failed to index %s: %v\n", filePath, err)
+		}
 
 		a.nPendingFiles = max(a.nPendingFiles-1, 0)
 	}
@@ -93,7 +102,7 @@ func (a *Analyzer) processFiles(ctx context.Context, filePaths []string) {
 }
 
 func (a *Analyzer) getParser(filePath string) (*parser.Parser, error) {
-	lang := languages.detect(filepath.Ext(filePath))
+	lang := languages.detect(filePath)
 	parser, exists := a.parsers[lang]
 	if exists {
 		return parser, nil
@@ -113,6 +122,8 @@ func (a *Analyzer) chunk(ctx context.Context, filePath string) error {
 		return err
 	}
 
+	file.Language = string(languages.detect(filePath))
+
 	err = a.index.Index(ctx, file)
 	if err != nil {
 		return err
@@ -121,14 +132,22 @@ func (a *Analyzer) chunk(ctx context.Context, filePath string) error {
 	return nil
 }
 
-func (a *Analyzer) SemanticSearch(ctx context.Context, query string, fileTypes []string) ([]string, error) {
+func (a *Analyzer) SemanticSearch(ctx context.Context, query string, opts index.SearchOptions) ([]string, error) {
+	a.flushPendingChanges()
+	a.lastSearchChange.Store(a.index.LatestChange())
+	return a.index.Search(ctx, query, opts)
+}
+
+// ChangesSinceLastSearch returns the chunks that were added, modified, moved
+// or removed since the last semantic search
+func (a *Analyzer) ChangesSinceLastSearch() []index.ChunkChange {
 	a.flushPendingChanges()
-	return a.index.Search(ctx, query, fileTypes)
+	return a.index.ChangesSince(a.lastSearchChange.Load())
 }
 
-func (a *Analyzer) FindSimilarChunks(ctx context.Context, chunkID string) ([]string, error) {
+func (a *Analyzer) FindSimilarChunks(ctx context.Context, chunkID string, opts index.SearchOptions) ([]string, error) {
 	a.flushPendingChanges()
-	return a.index.FindSimilarChunks(ctx, chunkID)
+	return a.index.FindSimilarChunks(ctx, chunkID, opts)
 }
 
 func (a *Analyzer) flushPendingChanges() {
@@ -153,7 +172,7 @@ func (a *Analyzer) getSingleChunkCode(ctx context.Context, id string) string {
 	}
 
 	err := a.chunk(ctx, parts[0])
-	if err != nil {
+	if err != nil && !errors.Is(err, index.ErrEmbeddingQueued) {
 		return fmt.Sprintf("== %s ==\n\n<processing error: %v>\n\n", id, err)
 	}
 
@@ -162,14 +181,49 @@ func (a *Analyzer) getSingleChunkCode(ctx context.Context, id string) string {
 		return fmt.Sprintf("== %s ==\n\n<error getting source: %v>\n\n", id, err)
 	}
 
-	var lineInfo string
-	if chunk.StartLine == chunk.EndLine {
-		lineInfo = fmt.Sprintf(" [line %d]", chunk.StartLine)
-	} else {
-		lineInfo = fmt.Sprintf(" [lines %d-%d]", chunk.StartLine, chunk.EndLine)
+	code := fmt.Sprintf("== %s [%s] ==\n\n%s\n\n", id, chunk.Lines(), chunk.Source)
+	for _, counterpart := range a.counterparts(ctx, chunk) {
+		code += fmt.Sprintf("See also: %s [%s]\n\n", counterpart.ID(), counterpart.Lines())
 	}
 
-	return fmt.Sprintf("== %s%s ==\n\n%s\n\n", id, lineInfo, chunk.Source)
+	return code
+}
+
+// counterparts returns the declaration of a C or C++ chunk in a header when
+// the chunk is from an implementation file, and its definitions otherwise
+func (a *Analyzer) counterparts(ctx context.Context, chunk *parser.Chunk) []*parser.Chunk {
+	lang := languages.detect(chunk.File)
+	if lang != C && lang != Cpp {
+		return nil
+	}
+
+	chunks, err := a.index.ChunksWithPath(ctx, chunk.Path)
+	if err != nil {
+		return nil
+	}
+
+	var counterparts []*parser.Chunk
+	for _, other := range chunks {
+		otherLang := languages.detect(other.File)
+		if otherLang != C && otherLang != Cpp {
+			continue
+		}
+
+		if isHeader(other.File) != isHeader(chunk.File) {
+			counterparts = append(counterparts, other)
+		}
+	}
+
+	return counterparts
+}
+
+func isHeader(filePath string) bool {
+	switch filepath.Ext(filePath) {
+	case ".h", ".hh", ".hpp":
+		return true
+	default:
+		return false
+	}
 }
 
 func (a *Analyzer) GetIndexStatus() (int, time.Time) {
@@ -185,6 +239,18 @@ func (a *Analyzer) GetIndexStatus() (int, time.Time) {
 	return pendingFiles, lastIndexedAt
 }
 
+// Rebuilding reports whether the index is being re-embedded after the
+// embedding model changed
+func (a *Analyzer) Rebuilding() bool {
+	return a.index.Rebuilding()
+}
+
+// EmbeddingBacklog returns the parsed files waiting for the embedder to
+// become available again
+func (a *Analyzer) EmbeddingBacklog() index.Backlog {
+	return a.index.EmbeddingBacklog()
+}
+
 func (a *Analyzer) Close() {
 	if a.watcher != nil {
 		a.watcher.Close()
@@ -193,4 +259,9 @@ func (a *Analyzer) Close() {
 	for _, parser := range a.parsers {
 		parser.Close()
 	}
+
+	err := a.index.Close()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Sourcerer: failed to close index: %v\n", err)
+	}
 }
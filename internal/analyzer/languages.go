@@ -3,6 +3,7 @@ package analyzer
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
 
 	"github.com/st3v3nmw/sourcerer-mcp/internal/parser"
 )
@@ -10,10 +11,16 @@ import (
 type Language string
 
 const (
+	C           Language = "c"
+	Cpp         Language = "cpp"
+	CSharp      Language = "csharp"
 	Go          Language = "go"
+	Java        Language = "java"
 	JavaScript  Language = "javascript"
 	Markdown    Language = "markdown"
 	Python      Language = "python"
+	Ruby        Language = "ruby"
+	Rust        Language = "rust"
 	TypeScript  Language = "typescript"
 	UnknownLang Language = "unknown"
 )
@@ -22,6 +29,7 @@ type ParserFactory func(workspaceRoot string) (*parser.Parser, error)
 
 type registry struct {
 	extensions map[string]Language
+	fileNames  map[string]Language // files without an extension, e.g. Rakefile
 	factories  map[Language]ParserFactory
 }
 
@@ -35,7 +43,12 @@ func (r *registry) supportedExts() []string {
 }
 
 func (r *registry) detect(filePath string) Language {
-	lang, exists := r.extensions[filepath.Ext(filePath)]
+	lang, exists := r.fileNames[filepath.Base(filePath)]
+	if exists {
+		return lang
+	}
+
+	lang, exists = r.extensions[filepath.Ext(filePath)]
 	if !exists {
 		return UnknownLang
 	}
@@ -52,19 +65,51 @@ func (r *registry) createParser(workspaceRoot string, lang Language) (*parser.Pa
 	return factory(workspaceRoot)
 }
 
+// register adds a language by its extensions, which can also be the full
+// names of files without an extension, e.g. Rakefile
 func (r *registry) register(lang Language, extensions []string, factory ParserFactory) {
 	r.factories[lang] = factory
 	for _, ext := range extensions {
-		r.extensions[ext] = lang
+		if strings.HasPrefix(ext, ".") {
+			r.extensions[ext] = lang
+		} else {
+			r.fileNames[ext] = lang
+		}
 	}
 }
 
 var languages = &registry{
 	extensions: map[string]Language{},
+	fileNames:  map[string]Language{},
 	factories:  map[Language]ParserFactory{},
 }
 
 func init() {
+	languages.register(
+		C,
+		[]string{".c"},
+		func(workspaceRoot string) (*parser.Parser, error) {
+			return parser.NewCParser(workspaceRoot)
+		},
+	)
+
+	// Headers are parsed as C++, which also handles C headers
+	languages.register(
+		Cpp,
+		[]string{".h", ".cc", ".cpp", ".hpp", ".hh"},
+		func(workspaceRoot string) (*parser.Parser, error) {
+			return parser.NewCppParser(workspaceRoot)
+		},
+	)
+
+	languages.register(
+		CSharp,
+		[]string{".cs"},
+		func(workspaceRoot string) (*parser.Parser, error) {
+			return parser.NewCSharpParser(workspaceRoot)
+		},
+	)
+
 	languages.register(
 		Go,
 		[]string{".go"},
@@ -73,6 +118,14 @@ func init() {
 		},
 	)
 
+	languages.register(
+		Java,
+		[]string{".java"},
+		func(workspaceRoot string) (*parser.Parser, error) {
+			return parser.NewJavaParser(workspaceRoot)
+		},
+	)
+
 	languages.register(
 		JavaScript,
 		[]string{".js", ".jsx", ".mjs"},
@@ -97,6 +150,22 @@ func init() {
 		},
 	)
 
+	languages.register(
+		Ruby,
+		[]string{".rb", "Rakefile", "Gemfile"},
+		func(workspaceRoot string) (*parser.Parser, error) {
+			return parser.NewRubyParser(workspaceRoot)
+		},
+	)
+
+	languages.register(
+		Rust,
+		[]string{".rs"},
+		func(workspaceRoot string) (*parser.Parser, error) {
+			return parser.NewRustParser(workspaceRoot)
+		},
+	)
+
 	languages.register(
 		TypeScript,
 		[]string{".ts", ".tsx"},
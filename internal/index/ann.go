@@ -0,0 +1,611 @@
+package index
+
+import (
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	defaultEfSearch = 64
+	// compactAfter is how many removed nodes a graph keeps as tombstones
+	// before it's rebuilt from its live nodes, as long as they're at least a
+	// quarter of the graph
+	compactAfter = 256
+	// buildLogInterval is how many documents are inserted between progress
+	// reports of a graph build
+	buildLogInterval = 50000
+	// graphVersion changes when the format graphs are saved in, or the
+	// metadata they keep, does
+	graphVersion = 2
+)
+
+// annKeys are the metadata keys the graph keeps to filter on, the rest of a
+// document's metadata is read from the store for the results returned
+var annKeys = []string{"file", "type", "language"}
+
+// quantizedStore is implemented by vector stores that keep embeddings in a
+// lossy format, whose quantizer the graph keeps its embeddings with
+type quantizedStore interface {
+	storeQuantizer() quantizer
+}
+
+// ANNConfig enables an approximate nearest-neighbour (HNSW) index over the
+// vector store, for workspaces where scanning every embedding per query is
+// too slow
+type ANNConfig struct {
+	Enabled bool
+	// EfSearch is how many candidates a query explores, raising it finds more
+	// of the exact nearest neighbours at the cost of latency
+	EfSearch int
+	// Dir is where graphs are saved between runs, they're rebuilt from their
+	// stores on every start if it's empty
+	Dir string
+}
+
+// ANNConfigFromEnv reads the ANN index configuration from the environment.
+// SOURCERER_ANN=hnsw enables it, SOURCERER_ANN_EF sets its search breadth.
+func ANNConfigFromEnv() ANNConfig {
+	cfg := ANNConfig{Enabled: os.Getenv("SOURCERER_ANN") == "hnsw"}
+
+	ef, err := strconv.Atoi(os.Getenv("SOURCERER_ANN_EF"))
+	if err == nil && ef > 0 {
+		cfg.EfSearch = ef
+	}
+
+	return cfg
+}
+
+// WithANN wraps a vector db so its stores answer queries from an in-memory
+// HNSW graph. Graphs saved in cfg.Dir are loaded when their stores are
+// opened, others are built from the stores in the background, and queries
+// scan the store until then. Graphs are saved after they're built and when
+// the db is closed.
+func WithANN(db VectorDB, cfg ANNConfig) VectorDB {
+	if cfg.EfSearch <= 0 {
+		cfg.EfSearch = defaultEfSearch
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &annDB{
+		VectorDB: db,
+		efSearch: cfg.EfSearch,
+		dir:      cfg.Dir,
+		stores:   map[string]*annStore{},
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+type annDB struct {
+	VectorDB
+	efSearch int
+	dir      string
+
+	// Graph builds run in the background until Close cancels them
+	ctx    context.Context
+	cancel context.CancelFunc
+	builds sync.WaitGroup
+
+	mu     sync.Mutex
+	stores map[string]*annStore
+}
+
+func (d *annDB) Open(name string, metadata map[string]string) (VectorStore, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if store, exists := d.stores[name]; exists {
+		return store, nil
+	}
+
+	store, err := d.VectorDB.Open(name, metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.wrap(name, store), nil
+}
+
+func (d *annDB) Get(name string) VectorStore {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if store, exists := d.stores[name]; exists {
+		return store
+	}
+
+	store := d.VectorDB.Get(name)
+	if store == nil {
+		return nil
+	}
+
+	return d.wrap(name, store)
+}
+
+func (d *annDB) Drop(name string) error {
+	d.mu.Lock()
+	delete(d.stores, name)
+	d.mu.Unlock()
+
+	if d.dir != "" {
+		os.Remove(d.graphPath(name))
+	}
+
+	return d.VectorDB.Drop(name)
+}
+
+// Close stops the graph builds in progress, saves the graphs that changed
+// since they were saved and closes the wrapped db
+func (d *annDB) Close() error {
+	d.cancel()
+	d.builds.Wait()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var errs []error
+	for _, store := range d.stores {
+		errs = append(errs, store.save())
+	}
+
+	return errors.Join(append(errs, d.VectorDB.Close())...)
+}
+
+// goBuild runs a graph build in the background with a context Close cancels
+func (d *annDB) goBuild(build func(ctx context.Context)) {
+	d.builds.Add(1)
+	go func() {
+		defer d.builds.Done()
+		build(d.ctx)
+	}()
+}
+
+func (d *annDB) graphPath(name string) string {
+	return filepath.Join(d.dir, name+".graph")
+}
+
+func (d *annDB) wrap(name string, store VectorStore) *annStore {
+	var q quantizer = float32Quantizer{}
+	if quantized, ok := store.(quantizedStore); ok {
+		q = quantized.storeQuantizer()
+	}
+
+	s := &annStore{VectorStore: store, db: d, efSearch: d.efSearch, quantizer: q, graph: newHNSW(q)}
+	d.stores[name] = s
+
+	if d.dir != "" {
+		s.path = d.graphPath(name)
+		graph, err := loadGraph(s.path, q, store.Count())
+		if err == nil {
+			s.graph = graph
+			s.saved = true
+			return s
+		}
+
+		if !errors.Is(err, os.ErrNotExist) {
+			fmt.Fprintf(os.Stderr, "Sourcerer: rebuilding ANN index: %v\n", err)
+		}
+	}
+
+	if store.Count() > 0 {
+		s.startBuild()
+		d.goBuild(func(ctx context.Context) {
+			docs, err := store.List(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					fmt.Fprintf(os.Stderr, "Sourcerer: failed to build ANN index, searching exhaustively: %v\n", err)
+				}
+				return
+			}
+
+			s.build(ctx, docs)
+		})
+	}
+
+	return s
+}
+
+// annStore keeps an HNSW graph over a VectorStore's documents, updated by
+// Add and Delete
+type annStore struct {
+	VectorStore
+	db        *annDB
+	efSearch  int
+	quantizer quantizer
+	path      string // where the graph is saved, empty if it isn't
+
+	mu    sync.RWMutex
+	graph *hnsw
+	// saved is set while the graph matches the one saved at path. The saved
+	// graph is removed before the store is first written to after that, so
+	// it's never loaded for a store it doesn't match.
+	saved bool
+
+	// While the graph is being built, writes go to it directly and the build
+	// skips the documents they replaced or removed
+	building     bool
+	touched      map[string]bool
+	removedWhere []map[string]string
+}
+
+func (s *annStore) Add(ctx context.Context, docs []Document) error {
+	s.unsave()
+
+	err := s.VectorStore.Add(ctx, docs)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, doc := range docs {
+		s.graph.insert(doc.ID, doc.Embedding, graphMetadata(doc.Metadata))
+		if s.building {
+			s.touched[doc.ID] = true
+		}
+	}
+
+	s.compactIfNeeded()
+	return nil
+}
+
+func (s *annStore) Delete(ctx context.Context, where map[string]string, ids ...string) error {
+	// The graph can't tell which documents match filters on keys it doesn't keep
+	if len(ids) == 0 && !graphFilters(where) {
+		docs, err := s.VectorStore.GetWhere(ctx, where)
+		if err != nil {
+			return err
+		}
+
+		for _, doc := range docs {
+			ids = append(ids, doc.ID)
+		}
+
+		if len(ids) == 0 {
+			return nil
+		}
+	}
+
+	s.unsave()
+
+	err := s.VectorStore.Delete(ctx, where, ids...)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(ids) == 0 {
+		for id, node := range s.graph.ids {
+			if matchesWhere(s.graph.nodes[node].metadata, where) {
+				ids = append(ids, id)
+			}
+		}
+
+		if s.building {
+			s.removedWhere = append(s.removedWhere, where)
+		}
+	}
+
+	for _, id := range ids {
+		s.graph.remove(id)
+		if s.building {
+			s.touched[id] = true
+		}
+	}
+
+	s.compactIfNeeded()
+	return nil
+}
+
+func (s *annStore) Query(ctx context.Context, embedding []float32, n int, where map[string]string) ([]Result, error) {
+	return s.QueryMatching(ctx, embedding, n, where, nil)
+}
+
+func (s *annStore) QueryMatching(
+	ctx context.Context,
+	embedding []float32,
+	n int,
+	where map[string]string,
+	match func(map[string]string) bool,
+) ([]Result, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// Exhaustive search is as cheap when most of the store is asked for
+	live := s.graph.len()
+	if s.building || n <= 0 || 2*n >= live || !graphFilters(where) {
+		return s.queryStore(ctx, embedding, n, where, match)
+	}
+
+	query := normalized(embedding)
+	matches := func(metadata map[string]string) bool {
+		return matchesWhere(metadata, where) && (match == nil || match(metadata))
+	}
+
+	// Filters can drop most of what the graph search finds, widen it until
+	// enough matches are found, then fall back to scanning
+	var found []candidate
+	for ef := max(s.efSearch, n); ; ef *= 4 {
+		if ef >= live {
+			found = s.graph.scan(query, n, matches)
+			break
+		}
+
+		found = s.graph.search(query, n, ef, matches)
+		if len(found) == n {
+			break
+		}
+	}
+
+	results := make([]Result, 0, len(found))
+	for _, c := range found {
+		// The document can be deleted from the store before it's removed from
+		// the graph
+		doc, err := s.VectorStore.Get(ctx, s.graph.nodes[c.node].id)
+		if err != nil {
+			continue
+		}
+
+		results = append(results, Result{ID: doc.ID, Metadata: doc.Metadata, Similarity: c.similarity})
+	}
+
+	return results, nil
+}
+
+// queryStore searches the wrapped store exhaustively. Since it can only
+// filter on where, it ranks every document matching where when match
+// filters them further.
+func (s *annStore) queryStore(
+	ctx context.Context,
+	embedding []float32,
+	n int,
+	where map[string]string,
+	match func(map[string]string) bool,
+) ([]Result, error) {
+	if match == nil || n <= 0 {
+		return s.VectorStore.Query(ctx, embedding, n, where)
+	}
+
+	results, err := s.VectorStore.Query(ctx, embedding, s.VectorStore.Count(), where)
+	if err != nil {
+		return nil, err
+	}
+
+	kept := results[:0]
+	for _, result := range results {
+		if len(kept) == n {
+			break
+		}
+
+		if match(result.Metadata) {
+			kept = append(kept, result)
+		}
+	}
+
+	return kept, nil
+}
+
+// graphMetadata keeps the metadata the graph filters on
+func graphMetadata(metadata map[string]string) map[string]string {
+	kept := make(map[string]string, len(annKeys))
+	for _, key := range annKeys {
+		value, exists := metadata[key]
+		if exists {
+			kept[key] = value
+		}
+	}
+
+	return kept
+}
+
+// graphFilters reports whether the graph keeps every key where filters on
+func graphFilters(where map[string]string) bool {
+	for key := range where {
+		if !slices.Contains(annKeys, key) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// compactIfNeeded rebuilds the graph without its tombstones once they make
+// up enough of it to slow searches down
+func (s *annStore) compactIfNeeded() {
+	deleted := s.graph.deleted
+	if s.building || deleted < compactAfter || 4*deleted < len(s.graph.nodes) {
+		return
+	}
+
+	docs := make([]*Document, 0, s.graph.len())
+	for id, node := range s.graph.ids {
+		embedding, _ := s.quantizer.decode(s.graph.nodes[node].code)
+		docs = append(docs, &Document{
+			ID:        id,
+			Metadata:  s.graph.nodes[node].metadata,
+			Embedding: embedding,
+		})
+	}
+
+	s.graph = newHNSW(s.quantizer)
+	s.startBuildLocked()
+	s.db.goBuild(func(ctx context.Context) { s.build(ctx, docs) })
+}
+
+func (s *annStore) startBuild() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.startBuildLocked()
+}
+
+func (s *annStore) startBuildLocked() {
+	s.building = true
+	s.touched = map[string]bool{}
+	s.removedWhere = nil
+}
+
+// build inserts documents into the graph, one at a time so writes aren't
+// held up, and hands queries over to the graph when it's done. A build
+// stopped by ctx leaves the graph unsaved, it's rebuilt on the next start.
+func (s *annStore) build(ctx context.Context, docs []*Document) {
+	start := time.Now()
+	fmt.Fprintf(os.Stderr, "Sourcerer: building ANN index of %d chunks, searching exhaustively until it's done\n", len(docs))
+
+	for i, doc := range docs {
+		if ctx.Err() != nil {
+			return
+		}
+
+		s.mu.Lock()
+		// Documents whose stored code is malformed have no embedding to
+		// link them by
+		if !s.stale(doc) && len(doc.Embedding) > 0 {
+			s.graph.insert(doc.ID, doc.Embedding, graphMetadata(doc.Metadata))
+		}
+		s.mu.Unlock()
+
+		if (i+1)%buildLogInterval == 0 {
+			fmt.Fprintf(os.Stderr, "Sourcerer: built ANN index of %d/%d chunks\n", i+1, len(docs))
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.building = false
+	s.touched = nil
+	s.removedWhere = nil
+
+	fmt.Fprintf(os.Stderr, "Sourcerer: built ANN index of %d chunks in %s\n", len(docs), time.Since(start).Round(time.Millisecond))
+
+	err := s.saveLocked()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Sourcerer: %v\n", err)
+	}
+}
+
+// unsave removes the saved graph before the store is written to
+func (s *annStore) unsave() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.saved {
+		os.Remove(s.path)
+		s.saved = false
+	}
+}
+
+// save writes the graph to its path if it changed since it was saved and
+// it's complete
+func (s *annStore) save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.saveLocked()
+}
+
+func (s *annStore) saveLocked() error {
+	if s.path == "" || s.saved || s.building {
+		return nil
+	}
+
+	err := saveGraph(s.path, s.graph, s.VectorStore.Count())
+	if err != nil {
+		return fmt.Errorf("failed to save ANN index: %w", err)
+	}
+
+	s.saved = true
+	return nil
+}
+
+// savedGraph is the on-disk form of an hnsw graph
+type savedGraph struct {
+	Version int
+	Count   int // documents in the store when the graph was saved
+	Graph   hnswSnapshot
+}
+
+// saveGraph writes a graph next to its store, to a temporary file first so
+// an interrupted save doesn't leave a partial one
+func saveGraph(path string, graph *hnsw, count int) error {
+	err := os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(path + ".tmp")
+	if err != nil {
+		return err
+	}
+
+	err = gob.NewEncoder(file).Encode(savedGraph{Version: graphVersion, Count: count, Graph: graph.snapshot()})
+	err = errors.Join(err, file.Close())
+	if err != nil {
+		os.Remove(path + ".tmp")
+		return err
+	}
+
+	return os.Rename(path+".tmp", path)
+}
+
+// loadGraph reads a saved graph, failing if it doesn't match a store holding
+// count documents
+func loadGraph(path string, q quantizer, count int) (*hnsw, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var saved savedGraph
+	err = gob.NewDecoder(file).Decode(&saved)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read saved graph: %w", err)
+	}
+
+	if saved.Version != graphVersion || saved.Count != count {
+		return nil, errors.New("saved graph is out of date")
+	}
+
+	for _, node := range saved.Graph.Nodes {
+		err = q.check(node.Code)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't read saved graph: %w", err)
+		}
+	}
+
+	graph := hnswFromSnapshot(q, saved.Graph)
+	if graph.len() != count {
+		return nil, errors.New("saved graph is out of date")
+	}
+
+	return graph, nil
+}
+
+// stale reports whether a document was replaced or removed after the build
+// started
+func (s *annStore) stale(doc *Document) bool {
+	if s.touched[doc.ID] {
+		return true
+	}
+
+	for _, where := range s.removedWhere {
+		if matchesWhere(doc.Metadata, where) {
+			return true
+		}
+	}
+
+	return false
+}
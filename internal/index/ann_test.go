@@ -0,0 +1,371 @@
+package index_test
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/st3v3nmw/sourcerer-mcp/internal/index"
+	"github.com/stretchr/testify/suite"
+)
+
+type ANNTestSuite struct {
+	suite.Suite
+	ctx   context.Context
+	rng   *rand.Rand
+	exact index.VectorStore
+	ann   index.VectorStore
+}
+
+func (s *ANNTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.rng = rand.New(rand.NewPCG(7, 11))
+	dir := s.T().TempDir()
+
+	db, err := index.NewVectorDB("sqlite", dir)
+	s.Require().NoError(err)
+	s.ann, err = index.WithANN(db, index.ANNConfig{Enabled: true}).Open("code", nil)
+	s.Require().NoError(err)
+
+	// A second connection to the same database answers queries exhaustively
+	raw, err := index.NewVectorDB("sqlite", dir)
+	s.Require().NoError(err)
+	s.exact = raw.Get("code")
+	s.Require().NotNil(s.exact)
+
+	var docs []index.Document
+	for i := range 2000 {
+		kind := "function"
+		if i%10 == 0 {
+			kind = "type"
+		}
+
+		docs = append(docs, index.Document{
+			ID:        fmt.Sprintf("file%d.go::F%d", i%100, i),
+			Content:   "func",
+			Embedding: s.vector(),
+			Metadata:  map[string]string{"file": fmt.Sprintf("file%d.go", i%100), "type": kind},
+		})
+	}
+
+	for start := 0; start < len(docs); start += 500 {
+		s.Require().NoError(s.ann.Add(s.ctx, docs[start:start+500]))
+	}
+}
+
+func (s *ANNTestSuite) vector() []float32 {
+	v := make([]float32, 16)
+	for i := range v {
+		v[i] = float32(s.rng.NormFloat64())
+	}
+
+	return v
+}
+
+// recall returns the fraction of the exact top n results the ANN index finds
+func (s *ANNTestSuite) recall(n int, where map[string]string) float64 {
+	hits, total := 0, 0
+	for range 50 {
+		query := s.vector()
+
+		expected, err := s.exact.Query(s.ctx, query, n, where)
+		s.Require().NoError(err)
+
+		found, err := s.ann.Query(s.ctx, query, n, where)
+		s.Require().NoError(err)
+		s.Require().Len(found, len(expected))
+
+		ids := map[string]bool{}
+		for i, result := range found {
+			ids[result.ID] = true
+			if i > 0 {
+				s.GreaterOrEqual(found[i-1].Similarity, result.Similarity)
+			}
+			for key, value := range where {
+				s.Equal(value, result.Metadata[key])
+			}
+		}
+
+		for _, result := range expected {
+			if ids[result.ID] {
+				hits++
+			}
+		}
+		total += len(expected)
+	}
+
+	return float64(hits) / float64(total)
+}
+
+func (s *ANNTestSuite) TestRecall() {
+	s.GreaterOrEqual(s.recall(10, nil), 0.9)
+}
+
+func (s *ANNTestSuite) TestFilteredRecall() {
+	s.GreaterOrEqual(s.recall(10, map[string]string{"type": "type"}), 0.9)
+	s.Equal(1.0, s.recall(5, map[string]string{"file": "file3.go"}))
+}
+
+func (s *ANNTestSuite) TestMatchingQueriesFilterInGraph() {
+	// Like a directory filter, matching 11 of the 100 files
+	match := func(metadata map[string]string) bool {
+		return strings.HasPrefix(metadata["file"], "file1")
+	}
+
+	filtering, ok := s.ann.(interface {
+		QueryMatching(context.Context, []float32, int, map[string]string, func(map[string]string) bool) ([]index.Result, error)
+	})
+	s.Require().True(ok)
+
+	hits, total := 0, 0
+	for range 50 {
+		query := s.vector()
+
+		all, err := s.exact.Query(s.ctx, query, s.exact.Count(), nil)
+		s.Require().NoError(err)
+		var expected []index.Result
+		for _, result := range all {
+			if match(result.Metadata) && len(expected) < 10 {
+				expected = append(expected, result)
+			}
+		}
+
+		found, err := filtering.QueryMatching(s.ctx, query, 10, nil, match)
+		s.Require().NoError(err)
+		s.Require().Len(found, 10)
+
+		ids := map[string]bool{}
+		for _, result := range found {
+			s.True(match(result.Metadata))
+			ids[result.ID] = true
+		}
+
+		for _, result := range expected {
+			if ids[result.ID] {
+				hits++
+			}
+		}
+		total += len(expected)
+	}
+
+	s.GreaterOrEqual(float64(hits)/float64(total), 0.9)
+}
+
+func (s *ANNTestSuite) TestDeletesAreNotReturned() {
+	s.Require().NoError(s.ann.Delete(s.ctx, nil, "file1.go::F1", "file2.go::F2"))
+	s.Require().NoError(s.ann.Delete(s.ctx, map[string]string{"file": "file5.go"}))
+
+	for range 20 {
+		results, err := s.ann.Query(s.ctx, s.vector(), 50, nil)
+		s.Require().NoError(err)
+		s.Require().Len(results, 50)
+
+		for _, result := range results {
+			s.NotEqual("file1.go::F1", result.ID)
+			s.NotEqual("file2.go::F2", result.ID)
+			s.NotEqual("file5.go", result.Metadata["file"])
+		}
+	}
+
+	s.GreaterOrEqual(s.recall(10, nil), 0.9)
+}
+
+func (s *ANNTestSuite) TestReplacedDocumentsAreRequeried() {
+	query := s.vector()
+	s.Require().NoError(s.ann.Add(s.ctx, []index.Document{{
+		ID:        "file7.go::F7",
+		Content:   "func",
+		Embedding: query,
+		Metadata:  map[string]string{"file": "file7.go", "type": "function"},
+	}}))
+
+	results, err := s.ann.Query(s.ctx, query, 3, nil)
+	s.Require().NoError(err)
+	s.Require().NotEmpty(results)
+	s.Equal("file7.go::F7", results[0].ID)
+	s.InDelta(1, results[0].Similarity, 1e-5)
+}
+
+func (s *ANNTestSuite) TestCompaction() {
+	for i := range 100 {
+		if i%2 == 0 {
+			s.Require().NoError(s.ann.Delete(s.ctx, map[string]string{"file": fmt.Sprintf("file%d.go", i)}))
+		}
+	}
+
+	// Half the graph is tombstones now, results stay exact while it's rebuilt
+	// and approximate afterwards
+	s.GreaterOrEqual(s.recall(10, nil), 0.9)
+	s.Equal(1000, s.ann.Count())
+}
+
+func (s *ANNTestSuite) TestQuantizedScoresMatchExhaustiveSearch() {
+	for _, quantization := range []string{index.QuantizationInt8, index.QuantizationBinary} {
+		s.Run(quantization, func() {
+			dir := s.T().TempDir()
+			metadata := map[string]string{"quantization": quantization}
+
+			db, err := index.NewVectorDB("sqlite", dir)
+			s.Require().NoError(err)
+			ann, err := index.WithANN(db, index.ANNConfig{Enabled: true}).Open("code", metadata)
+			s.Require().NoError(err)
+
+			raw, err := index.NewVectorDB("sqlite", dir)
+			s.Require().NoError(err)
+			exact := raw.Get("code")
+			s.Require().NotNil(exact)
+
+			var docs []index.Document
+			for i := range 1000 {
+				docs = append(docs, index.Document{
+					ID:        fmt.Sprintf("file%d.go::F%d", i%100, i),
+					Content:   "func",
+					Embedding: s.vector(),
+					Metadata:  map[string]string{"file": fmt.Sprintf("file%d.go", i%100), "type": "function"},
+				})
+			}
+			s.Require().NoError(ann.Add(s.ctx, docs))
+
+			for range 20 {
+				query := s.vector()
+
+				expected, err := exact.Query(s.ctx, query, 10, nil)
+				s.Require().NoError(err)
+				scores := map[string]float32{}
+				for _, result := range expected {
+					scores[result.ID] = result.Similarity
+				}
+
+				found, err := ann.Query(s.ctx, query, 10, nil)
+				s.Require().NoError(err)
+				s.Require().Len(found, 10)
+
+				shared := 0
+				for _, result := range found {
+					score, exists := scores[result.ID]
+					if exists {
+						s.InDelta(score, result.Similarity, 1e-5)
+						shared++
+					}
+
+					s.Equal("function", result.Metadata["type"])
+				}
+				s.Positive(shared)
+			}
+		})
+	}
+}
+
+// docs returns n random documents, numbered from first
+func (s *ANNTestSuite) docs(first, n int) []index.Document {
+	var docs []index.Document
+	for i := first; i < first+n; i++ {
+		docs = append(docs, index.Document{
+			ID:        fmt.Sprintf("file%d.go::F%d", i%100, i),
+			Content:   "func",
+			Embedding: s.vector(),
+			Metadata:  map[string]string{"file": fmt.Sprintf("file%d.go", i%100), "type": "function"},
+		})
+	}
+
+	return docs
+}
+
+func (s *ANNTestSuite) TestSavedGraphIsLoaded() {
+	dir := s.T().TempDir()
+	cfg := index.ANNConfig{Enabled: true, Dir: filepath.Join(dir, "ann")}
+	graph := filepath.Join(cfg.Dir, "code.graph")
+
+	db, err := index.NewVectorDB("sqlite", dir)
+	s.Require().NoError(err)
+	annDB := index.WithANN(db, cfg)
+	store, err := annDB.Open("code", nil)
+	s.Require().NoError(err)
+	docs := s.docs(0, 1000)
+	s.Require().NoError(store.Add(s.ctx, docs))
+	s.Require().NoError(annDB.Close())
+	s.FileExists(graph)
+
+	db, err = index.NewVectorDB("sqlite", dir)
+	s.Require().NoError(err)
+	annDB = index.WithANN(db, cfg)
+	defer annDB.Close()
+	store, err = annDB.Open("code", nil)
+	s.Require().NoError(err)
+
+	for _, doc := range docs[:20] {
+		found, err := store.Query(s.ctx, doc.Embedding, 1, nil)
+		s.Require().NoError(err)
+		s.Require().Len(found, 1)
+		s.Equal(doc.ID, found[0].ID)
+	}
+
+	// A crash after this write mustn't leave the old graph behind
+	s.Require().NoError(store.Add(s.ctx, s.docs(1000, 1)))
+	s.NoFileExists(graph)
+}
+
+func (s *ANNTestSuite) TestOutdatedGraphIsRebuilt() {
+	dir := s.T().TempDir()
+	cfg := index.ANNConfig{Enabled: true, Dir: filepath.Join(dir, "ann")}
+
+	db, err := index.NewVectorDB("sqlite", dir)
+	s.Require().NoError(err)
+	annDB := index.WithANN(db, cfg)
+	store, err := annDB.Open("code", nil)
+	s.Require().NoError(err)
+	s.Require().NoError(store.Add(s.ctx, s.docs(0, 1000)))
+	s.Require().NoError(annDB.Close())
+
+	// The store changes while the graph isn't kept up to date
+	raw, err := index.NewVectorDB("sqlite", dir)
+	s.Require().NoError(err)
+	added := s.docs(1000, 1)
+	s.Require().NoError(raw.Get("code").Add(s.ctx, added))
+	s.Require().NoError(raw.Close())
+
+	db, err = index.NewVectorDB("sqlite", dir)
+	s.Require().NoError(err)
+	annDB = index.WithANN(db, cfg)
+	defer annDB.Close()
+	store, err = annDB.Open("code", nil)
+	s.Require().NoError(err)
+
+	found, err := store.Query(s.ctx, added[0].Embedding, 1, nil)
+	s.Require().NoError(err)
+	s.Require().Len(found, 1)
+	s.Equal(added[0].ID, found[0].ID)
+}
+
+func (s *ANNTestSuite) TestCloseStopsBuild() {
+	dir := s.T().TempDir()
+	cfg := index.ANNConfig{Enabled: true, Dir: filepath.Join(dir, "ann")}
+
+	raw, err := index.NewVectorDB("sqlite", dir)
+	s.Require().NoError(err)
+	store, err := raw.Open("code", nil)
+	s.Require().NoError(err)
+	s.Require().NoError(store.Add(s.ctx, s.docs(0, 2000)))
+	s.Require().NoError(raw.Close())
+
+	db, err := index.NewVectorDB("sqlite", dir)
+	s.Require().NoError(err)
+	annDB := index.WithANN(db, cfg)
+	_, err = annDB.Open("code", nil)
+	s.Require().NoError(err)
+
+	start := time.Now()
+	s.Require().NoError(annDB.Close())
+	s.Less(time.Since(start), time.Second)
+
+	// The unfinished graph isn't saved
+	s.NoFileExists(filepath.Join(cfg.Dir, "code.graph"))
+}
+
+func TestANNTestSuite(t *testing.T) {
+	suite.Run(t, new(ANNTestSuite))
+}
@@ -0,0 +1,210 @@
+package index
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// maxChangeLog is how many chunk changes are kept for agents to catch up on
+const maxChangeLog = 1024
+
+// ChangeKind describes how a chunk changed between two indexings of its file
+type ChangeKind string
+
+const (
+	ChunkAdded    ChangeKind = "added"
+	ChunkRemoved  ChangeKind = "removed"
+	ChunkModified ChangeKind = "modified" // same ID, different content
+	ChunkMoved    ChangeKind = "moved"    // same ID and content, different position
+)
+
+// ChunkChange records a chunk mutation applied to the index
+type ChunkChange struct {
+	Seq       uint64
+	ID        string
+	File      string
+	Kind      ChangeKind
+	StartLine uint
+	EndLine   uint
+	At        time.Time
+}
+
+// chunkDiff is the set of mutations that turns the stored chunks of a file
+// into its freshly parsed chunks
+type chunkDiff struct {
+	upserts  []Document  // added, modified and moved chunks
+	replaced []*Document // stored versions of modified and moved chunks
+	removed  []*Document
+	changes  []ChunkChange
+}
+
+func (d *chunkDiff) empty() bool {
+	return len(d.upserts) == 0 && len(d.removed) == 0
+}
+
+// diffChunks compares the stored documents of a file against the parsed ones
+// by chunk ID and content
+func diffChunks(stored []*Document, parsed []Document) *chunkDiff {
+	diff := &chunkDiff{}
+
+	previous := make(map[string]*Document, len(stored))
+	for _, doc := range stored {
+		previous[doc.ID] = doc
+	}
+
+	for _, doc := range parsed {
+		old, exists := previous[doc.ID]
+		delete(previous, doc.ID)
+
+		var kind ChangeKind
+		switch {
+		case !exists:
+			kind = ChunkAdded
+		case old.Content != doc.Content:
+			kind = ChunkModified
+		case !samePosition(old.Metadata, doc.Metadata):
+			kind = ChunkMoved
+		case !sameRefreshedMetadata(old.Metadata, doc.Metadata):
+			// Refreshing what the chunk is embedded with isn't a chunk change
+			diff.upserts = append(diff.upserts, doc)
+			diff.replaced = append(diff.replaced, old)
+			continue
+		default:
+			continue
+		}
+
+		if exists {
+			diff.replaced = append(diff.replaced, old)
+		}
+
+		diff.upserts = append(diff.upserts, doc)
+		diff.changes = append(diff.changes, newChunkChange(doc.ID, doc.Metadata, kind))
+	}
+
+	for _, doc := range stored {
+		if _, gone := previous[doc.ID]; gone {
+			diff.removed = append(diff.removed, doc)
+			diff.changes = append(diff.changes, newChunkChange(doc.ID, doc.Metadata, ChunkRemoved))
+		}
+	}
+
+	return diff
+}
+
+func samePosition(a, b map[string]string) bool {
+	for _, key := range []string{"startLine", "startColumn", "endLine", "endColumn"} {
+		if a[key] != b[key] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// refreshedMetadata are the metadata keys that chunks are updated in place
+// for when they change, without counting as a chunk change
+var refreshedMetadata = []string{"language", "kind", "doc", "codeStart"}
+
+func sameRefreshedMetadata(a, b map[string]string) bool {
+	for _, key := range refreshedMetadata {
+		value, exists := a[key]
+		if !exists || value != b[key] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func newChunkChange(id string, metadata map[string]string, kind ChangeKind) ChunkChange {
+	startLine, _ := strconv.Atoi(metadata["startLine"])
+	endLine, _ := strconv.Atoi(metadata["endLine"])
+
+	return ChunkChange{
+		ID:        id,
+		File:      metadata["file"],
+		Kind:      kind,
+		StartLine: uint(startLine),
+		EndLine:   uint(endLine),
+	}
+}
+
+// changeLog is a bounded, sequenced record of the chunk changes applied to
+// the index, so callers can ask what changed since a point in time
+type changeLog struct {
+	mu      sync.RWMutex
+	seq     uint64
+	entries []ChunkChange
+}
+
+func (l *changeLog) record(changes []ChunkChange) {
+	if len(changes) == 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	for _, change := range changes {
+		l.seq++
+		change.Seq = l.seq
+		change.At = now
+		l.entries = append(l.entries, change)
+	}
+
+	if overflow := len(l.entries) - maxChangeLog; overflow > 0 {
+		l.entries = append([]ChunkChange(nil), l.entries[overflow:]...)
+	}
+}
+
+func (l *changeLog) latest() uint64 {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.seq
+}
+
+// since returns the net change of every chunk touched after seq, in the order
+// they were last changed. A chunk that was added and then removed again is
+// left out, one that was removed and re-added is reported as modified.
+func (l *changeLog) since(seq uint64) []ChunkChange {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	net := map[string]ChunkChange{}
+	for _, change := range l.entries {
+		if change.Seq <= seq {
+			continue
+		}
+
+		prev, exists := net[change.ID]
+		if exists {
+			switch {
+			case prev.Kind == ChunkAdded && change.Kind == ChunkRemoved:
+				delete(net, change.ID)
+				continue
+			case prev.Kind == ChunkAdded:
+				change.Kind = ChunkAdded
+			case prev.Kind == ChunkRemoved && change.Kind == ChunkAdded,
+				prev.Kind == ChunkModified && change.Kind == ChunkMoved:
+				change.Kind = ChunkModified
+			}
+		}
+
+		net[change.ID] = change
+	}
+
+	changes := make([]ChunkChange, 0, len(net))
+	for _, change := range net {
+		changes = append(changes, change)
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Seq < changes[j].Seq
+	})
+
+	return changes
+}
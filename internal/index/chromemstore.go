@@ -0,0 +1,178 @@
+package index
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"runtime"
+
+	"github.com/philippgille/chromem-go"
+)
+
+// errNotEmbedded is returned by chromem if it's asked to embed a document
+// itself, every document is embedded by the index before it's stored
+var errNotEmbedded = errors.New("document must be embedded before it's stored")
+
+func notEmbedded(context.Context, string) ([]float32, error) {
+	return nil, errNotEmbedded
+}
+
+// chromemDB keeps vector stores in a persistent chromem-go db
+type chromemDB struct {
+	db *chromem.DB
+}
+
+func newChromemDB(dir string) (VectorDB, error) {
+	db, err := chromem.NewPersistentDB(filepath.Join(dir, "db"), false)
+	if err != nil {
+		return nil, err
+	}
+
+	return &chromemDB{db: db}, nil
+}
+
+func (d *chromemDB) Open(name string, metadata map[string]string) (VectorStore, error) {
+	collection, err := d.db.GetOrCreateCollection(name, metadata, notEmbedded)
+	if err != nil {
+		return nil, err
+	}
+
+	return &chromemStore{collection: collection}, nil
+}
+
+func (d *chromemDB) Get(name string) VectorStore {
+	collection := d.db.GetCollection(name, notEmbedded)
+	if collection == nil {
+		return nil
+	}
+
+	return &chromemStore{collection: collection}
+}
+
+func (d *chromemDB) Names() []string {
+	var names []string
+	for name := range d.db.ListCollections() {
+		names = append(names, name)
+	}
+
+	return names
+}
+
+// Close is a no-op, chromem writes every change to disk as it's made
+func (d *chromemDB) Close() error {
+	return nil
+}
+
+func (d *chromemDB) Drop(name string) error {
+	return d.db.DeleteCollection(name)
+}
+
+// chromemStore is a VectorStore backed by a chromem-go collection
+type chromemStore struct {
+	collection *chromem.Collection
+}
+
+func (s *chromemStore) Name() string {
+	return s.collection.Name
+}
+
+func (s *chromemStore) Add(ctx context.Context, docs []Document) error {
+	if len(docs) == 0 {
+		return nil
+	}
+
+	chromemDocs := make([]chromem.Document, len(docs))
+	for i, doc := range docs {
+		chromemDocs[i] = chromem.Document{
+			ID:        doc.ID,
+			Metadata:  doc.Metadata,
+			Embedding: doc.Embedding,
+			Content:   doc.Content,
+		}
+	}
+
+	return s.collection.AddDocuments(ctx, chromemDocs, runtime.NumCPU())
+}
+
+func (s *chromemStore) Delete(ctx context.Context, where map[string]string, ids ...string) error {
+	if len(ids) > 0 {
+		return s.collection.Delete(ctx, nil, nil, ids...)
+	}
+
+	if len(where) == 0 {
+		return errors.New("delete needs ids or a where filter")
+	}
+
+	return s.collection.Delete(ctx, where, nil)
+}
+
+func (s *chromemStore) Query(ctx context.Context, embedding []float32, n int, where map[string]string) ([]Result, error) {
+	// chromem-go rejects requests for more results than it holds
+	n = min(n, s.collection.Count())
+	if n <= 0 {
+		return nil, nil
+	}
+
+	results, err := s.collection.QueryEmbedding(ctx, embedding, n, where, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]Result, len(results))
+	for i, result := range results {
+		matches[i] = Result{ID: result.ID, Metadata: result.Metadata, Similarity: result.Similarity}
+	}
+
+	return matches, nil
+}
+
+func (s *chromemStore) Get(ctx context.Context, id string) (Document, error) {
+	doc, err := s.collection.GetByID(ctx, id)
+	if err != nil {
+		return Document{}, err
+	}
+
+	return fromChromem(&doc), nil
+}
+
+func (s *chromemStore) GetWhere(ctx context.Context, where map[string]string) ([]*Document, error) {
+	docs, err := s.collection.GetByMetadata(ctx, where)
+	if err != nil {
+		return nil, err
+	}
+
+	return fromChromemAll(docs), nil
+}
+
+func (s *chromemStore) List(ctx context.Context) ([]*Document, error) {
+	docs, err := s.collection.ListDocumentsShallow(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list documents: %w", err)
+	}
+
+	return fromChromemAll(docs), nil
+}
+
+func (s *chromemStore) Count() int {
+	return s.collection.Count()
+}
+
+func fromChromem(doc *chromem.Document) Document {
+	return Document{
+		ID:        doc.ID,
+		Metadata:  doc.Metadata,
+		Embedding: doc.Embedding,
+		Content:   doc.Content,
+	}
+}
+
+func fromChromemAll(docs []*chromem.Document) []*Document {
+	result := make([]*Document, len(docs))
+	for i, doc := range docs {
+		converted := fromChromem(doc)
+		result[i] = &converted
+	}
+
+	return result
+}
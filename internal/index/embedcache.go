@@ -0,0 +1,240 @@
+package index
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/cespare/xxhash"
+)
+
+// embeddingCacheSlack is how many vectors of removed chunks are kept around
+// (e.g. for files that are deleted and re-created) before pruning
+const embeddingCacheSlack = 4096
+
+// contentHash keys an embedding by the text that was embedded and the model
+// that embedded it, so vectors are never reused across models
+func contentHash(model, text string) string {
+	return fmt.Sprintf("%x", xxhash.Sum64String(model+"\x00"+text))
+}
+
+// embeddingCache maps content hashes to embeddings. It's seeded from the
+// documents persisted in the vector db, which store their content hash as
+// metadata, so it survives restarts without duplicating vectors on disk.
+// Embeddings of quantized stores are cached in the store's format, so the
+// cache doesn't bring back the memory quantizing them saved.
+type embeddingCache struct {
+	quantizer quantizer // nil if embeddings are cached at full precision
+
+	mu      sync.RWMutex
+	vectors map[string][]float32
+	codes   map[string][]byte
+}
+
+func newEmbeddingCache(format string) *embeddingCache {
+	c := &embeddingCache{
+		vectors: map[string][]float32{},
+		codes:   map[string][]byte{},
+	}
+
+	if format != QuantizationNone {
+		c.quantizer, _ = newQuantizer(format)
+	}
+
+	return c
+}
+
+// get returns the embedding of a content hash, approximated from its code if
+// the cache is quantized
+func (c *embeddingCache) get(hash string) ([]float32, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.quantizer != nil {
+		code, exists := c.codes[hash]
+		if !exists {
+			return nil, false
+		}
+
+		// A code that can't be decoded is embedded again
+		vector, err := c.quantizer.decode(code)
+		return vector, err == nil
+	}
+
+	vector, exists := c.vectors[hash]
+	return vector, exists
+}
+
+func (c *embeddingCache) put(hash string, vector []float32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.quantizer != nil {
+		c.codes[hash] = c.quantizer.encode(normalized(vector))
+		return
+	}
+
+	c.vectors[hash] = vector
+}
+
+func (c *embeddingCache) len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return len(c.vectors) + len(c.codes)
+}
+
+// reset replaces the cache contents with the vectors of the given documents
+func (c *embeddingCache) reset(docs []*Document) {
+	vectors := map[string][]float32{}
+	codes := map[string][]byte{}
+	for _, doc := range docs {
+		hash := doc.Metadata["contentHash"]
+		if hash == "" || len(doc.Embedding) == 0 {
+			continue
+		}
+
+		if c.quantizer != nil {
+			codes[hash] = c.quantizer.encode(normalized(doc.Embedding))
+		} else {
+			vectors[hash] = doc.Embedding
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.vectors = vectors
+	c.codes = codes
+}
+
+// embedDocuments fills in the embeddings of docs, reusing cached vectors for
+// text that was already embedded by the current model and only sending new
+// or changed chunks to the embedder
+func (idx *Index) embedDocuments(ctx context.Context, docs []Document) error {
+	model := idx.embedder.Provider() + "/" + idx.embedder.Model()
+
+	texts := make([]string, len(docs))
+	var misses []int
+	for i := range docs {
+		text, err := idx.templates.render(&docs[i])
+		if err != nil {
+			return err
+		}
+
+		texts[i] = text
+		hash := contentHash(model, text)
+		docs[i].Metadata["contentHash"] = hash
+
+		vector, cached := idx.embeddings.get(hash)
+		if cached {
+			docs[i].Embedding = vector
+			continue
+		}
+
+		misses = append(misses, i)
+	}
+
+	if len(misses) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	var errOnce sync.Once
+	var embedErr error
+	semaphore := make(chan struct{}, runtime.NumCPU())
+
+	for _, batch := range embedBatches(misses, idx.embedder) {
+		wg.Add(1)
+		go func(batch []int) {
+			defer wg.Done()
+
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			if ctx.Err() != nil {
+				return
+			}
+
+			batchTexts := make([]string, len(batch))
+			for j, i := range batch {
+				batchTexts[j] = texts[i]
+			}
+
+			vectors, err := embedTexts(ctx, idx.embedder, batchTexts)
+			if err != nil {
+				errOnce.Do(func() {
+					embedErr = &embedderError{fmt.Errorf("couldn't embed %s: %w", docs[batch[0]].ID, err)}
+					cancel()
+				})
+				return
+			}
+
+			for j, i := range batch {
+				docs[i].Embedding = vectors[j]
+				idx.embeddings.put(docs[i].Metadata["contentHash"], vectors[j])
+			}
+		}(batch)
+	}
+
+	wg.Wait()
+
+	return embedErr
+}
+
+// embedBatches groups the indexes of texts into batches the embedder can
+// embed in one request
+func embedBatches(indexes []int, e Embedder) [][]int {
+	size := 1
+	batcher, ok := e.(batchEmbedder)
+	if ok {
+		size = max(batcher.BatchSize(), 1)
+	}
+
+	var batches [][]int
+	for start := 0; start < len(indexes); start += size {
+		batches = append(batches, indexes[start:min(start+size, len(indexes))])
+	}
+
+	return batches
+}
+
+// embedTexts embeds a batch of texts, in one request if the embedder
+// supports it
+func embedTexts(ctx context.Context, e Embedder, texts []string) ([][]float32, error) {
+	batcher, ok := e.(batchEmbedder)
+	if ok {
+		return batcher.EmbedBatch(ctx, texts)
+	}
+
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vector, err := e.Embed(ctx, text)
+		if err != nil {
+			return nil, err
+		}
+
+		vectors[i] = vector
+	}
+
+	return vectors, nil
+}
+
+// pruneEmbeddings drops cached vectors that no stored chunk uses anymore
+// once too many have piled up
+func (idx *Index) pruneEmbeddings(ctx context.Context) {
+	if idx.embeddings.len() <= idx.collection.Count()+embeddingCacheSlack {
+		return
+	}
+
+	docs, err := idx.collection.List(ctx)
+	if err != nil {
+		return
+	}
+
+	idx.embeddings.reset(docs)
+}
@@ -0,0 +1,166 @@
+package index_test
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync/atomic"
+	"testing"
+
+	"github.com/st3v3nmw/sourcerer-mcp/internal/index"
+	"github.com/st3v3nmw/sourcerer-mcp/internal/parser"
+	"github.com/stretchr/testify/suite"
+)
+
+// countingEmbedder records how many texts were sent to the embedder
+type countingEmbedder struct {
+	index.Embedder
+	calls *atomic.Int64
+}
+
+func (e *countingEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	e.calls.Add(1)
+	return e.Embedder.Embed(ctx, text)
+}
+
+var embedCalls atomic.Int64
+
+func init() {
+	index.RegisterEmbedder("counting", func(cfg index.EmbedderConfig) (index.Embedder, error) {
+		inner, err := index.NewEmbedder(index.EmbedderConfig{Provider: "test"})
+		if err != nil {
+			return nil, err
+		}
+
+		return &countingEmbedder{Embedder: inner, calls: &embedCalls}, nil
+	})
+}
+
+type EmbeddingCacheTestSuite struct {
+	suite.Suite
+	ctx           context.Context
+	workspaceRoot string
+}
+
+func (s *EmbeddingCacheTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.workspaceRoot = s.T().TempDir()
+}
+
+func (s *EmbeddingCacheTestSuite) newIndex() *index.Index {
+	idx, err := openIndex(s.T(), s.ctx, s.workspaceRoot, index.Config{
+		Embedder: index.EmbedderConfig{Provider: "counting"},
+	})
+	s.Require().NoError(err)
+
+	// Don't count the embedder probe made when opening the index
+	embedCalls.Store(0)
+
+	return idx
+}
+
+func (s *EmbeddingCacheTestSuite) file(sources ...string) *parser.File {
+	file := &parser.File{Path: "main.go"}
+	for i, source := range sources {
+		file.Chunks = append(file.Chunks, &parser.Chunk{
+			File:      file.Path,
+			Path:      string(rune('A' + i)),
+			Type:      "src",
+			Source:    source,
+			StartLine: uint(i + 1),
+			EndLine:   uint(i + 1),
+		})
+	}
+
+	return file
+}
+
+func (s *EmbeddingCacheTestSuite) TestOnlyChangedChunksAreEmbedded() {
+	idx := s.newIndex()
+
+	s.Require().NoError(idx.Index(s.ctx, s.file("func a() {}", "func b() {}", "func c() {}")))
+	s.Equal(int64(3), embedCalls.Load())
+
+	s.Require().NoError(idx.Index(s.ctx, s.file("func a() {}", "func b() { changed() }", "func c() {}")))
+	s.Equal(int64(4), embedCalls.Load())
+}
+
+func (s *EmbeddingCacheTestSuite) TestCacheSurvivesRestart() {
+	idx := s.newIndex()
+	s.Require().NoError(idx.Index(s.ctx, s.file("func a() {}", "func b() {}")))
+	s.Equal(int64(2), embedCalls.Load())
+	s.Require().NoError(idx.Close())
+
+	restarted := s.newIndex()
+	s.Require().NoError(restarted.Index(s.ctx, s.file("func a() {}", "func b() {}")))
+	s.Zero(embedCalls.Load())
+}
+
+func (s *EmbeddingCacheTestSuite) TestRemovedFileReusesVectors() {
+	idx := s.newIndex()
+	s.Require().NoError(idx.Index(s.ctx, s.file("func a() {}")))
+	s.Require().NoError(idx.Remove(s.ctx, "main.go"))
+
+	s.Require().NoError(idx.Index(s.ctx, s.file("func a() {}")))
+	s.Equal(int64(1), embedCalls.Load())
+}
+
+// heapAfterOpen returns how much heap an index holding chunks of the given
+// quantization keeps after being reopened
+func (s *EmbeddingCacheTestSuite) heapAfterOpen(quantization string, chunks int) uint64 {
+	workspaceRoot := s.T().TempDir()
+	cfg := index.Config{
+		Embedder:     index.EmbedderConfig{Provider: "test"},
+		Store:        "sqlite",
+		Quantization: quantization,
+	}
+
+	idx, err := openIndex(s.T(), s.ctx, workspaceRoot, cfg)
+	s.Require().NoError(err)
+
+	file := &parser.File{Path: "main.go"}
+	for i := range chunks {
+		file.Chunks = append(file.Chunks, &parser.Chunk{
+			File:      file.Path,
+			Path:      fmt.Sprintf("F%d", i),
+			Type:      "src",
+			Source:    fmt.Sprintf("func F%d() { return %d }", i, i),
+			StartLine: uint(i + 1),
+			EndLine:   uint(i + 1),
+		})
+	}
+	s.Require().NoError(idx.Index(s.ctx, file))
+	s.Require().NoError(idx.Close())
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	reopened, err := openIndex(s.T(), s.ctx, workspaceRoot, cfg)
+	s.Require().NoError(err)
+
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+	runtime.KeepAlive(reopened)
+
+	return after.HeapAlloc - min(before.HeapAlloc, after.HeapAlloc)
+}
+
+func (s *EmbeddingCacheTestSuite) TestQuantizedCacheSavesMemory() {
+	const chunks = 4000
+
+	fullHeap := s.heapAfterOpen(index.QuantizationNone, chunks)
+	int8Heap := s.heapAfterOpen(index.QuantizationInt8, chunks)
+	binaryHeap := s.heapAfterOpen(index.QuantizationBinary, chunks)
+	s.T().Logf("heap per chunk: float32 %d B, int8 %d B, binary %d B", fullHeap/chunks, int8Heap/chunks, binaryHeap/chunks)
+
+	// The test embedder's vectors take 1 KiB at full precision, their int8
+	// codes a quarter of that and their binary ones a thirty-second
+	const vectorSize = 1024
+	s.Greater(fullHeap-min(fullHeap, int8Heap), uint64(chunks*vectorSize/2))
+	s.Greater(fullHeap-min(fullHeap, binaryHeap), uint64(chunks*vectorSize*3/4))
+}
+
+func TestEmbeddingCacheTestSuite(t *testing.T) {
+	suite.Run(t, new(EmbeddingCacheTestSuite))
+}
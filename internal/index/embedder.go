@@ -0,0 +1,234 @@
+package index
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cespare/xxhash"
+)
+
+const (
+	defaultOpenAIURL      = "https://api.openai.com/v1"
+	defaultOpenAIModel    = "text-embedding-3-small"
+	defaultOllamaEndpoint = "http://localhost:11434/api"
+	defaultOllamaModel    = "nomic-embed-text"
+	testEmbeddingDims     = 256
+)
+
+// Embedder turns chunk text into vectors for the vector store
+type Embedder interface {
+	Provider() string // provider name, e.g. "openai" or "ollama"
+	Model() string    // embedding model used by the provider
+	Embed(ctx context.Context, text string) ([]float32, error)
+}
+
+// queryEmbedder is implemented by embedders that embed search queries
+// differently from the documents they're matched against
+type queryEmbedder interface {
+	EmbedQuery(ctx context.Context, text string) ([]float32, error)
+}
+
+// corpusObserver is implemented by embedders that learn statistics from the
+// indexed corpus; delta is +1 when a document is added and -1 when removed
+type corpusObserver interface {
+	observe(text string, delta int)
+}
+
+// EmbedderConfig selects and configures an embedding provider
+type EmbedderConfig struct {
+	Provider string // registered provider name
+	Model    string // optional, falls back to the provider's default
+	BaseURL  string // API endpoint for self-hosted or OpenAI-compatible providers
+	APIKey   string
+
+	// Optional overrides of the provider's request pacing and batching
+	RequestsPerSecond float64
+	Concurrency       int // requests in flight at once
+	BatchSize         int // texts per request
+
+	// fallback is set when no provider was configured and none could be
+	// picked from the environment
+	fallback bool
+}
+
+// EmbedderFactory creates an Embedder from its configuration
+type EmbedderFactory func(cfg EmbedderConfig) (Embedder, error)
+
+type embedderRegistry struct {
+	factories map[string]EmbedderFactory
+}
+
+func (r *embedderRegistry) register(provider string, factory EmbedderFactory) {
+	r.factories[provider] = factory
+}
+
+func (r *embedderRegistry) providers() []string {
+	providers := make([]string, 0, len(r.factories))
+	for provider := range r.factories {
+		providers = append(providers, provider)
+	}
+
+	sort.Strings(providers)
+	return providers
+}
+
+func (r *embedderRegistry) create(cfg EmbedderConfig) (Embedder, error) {
+	factory, exists := r.factories[cfg.Provider]
+	if !exists {
+		return nil, fmt.Errorf(
+			"unknown embedding provider %q (available: %s)",
+			cfg.Provider, strings.Join(r.providers(), ", "),
+		)
+	}
+
+	return factory(cfg)
+}
+
+var embedders = &embedderRegistry{
+	factories: map[string]EmbedderFactory{},
+}
+
+// RegisterEmbedder makes an embedding provider available to NewEmbedder
+func RegisterEmbedder(provider string, factory EmbedderFactory) {
+	embedders.register(provider, factory)
+}
+
+// NewEmbedder creates the Embedder for the configured provider
+func NewEmbedder(cfg EmbedderConfig) (Embedder, error) {
+	return embedders.create(cfg)
+}
+
+// EmbedderConfigFromEnv reads the embedding provider configuration from the
+// environment. SOURCERER_EMBEDDER picks the provider explicitly; without it
+// OpenAI is used when OPENAI_API_KEY is set and local Ollama otherwise.
+func EmbedderConfigFromEnv() EmbedderConfig {
+	cfg := EmbedderConfig{
+		Provider: os.Getenv("SOURCERER_EMBEDDER"),
+		Model:    os.Getenv("SOURCERER_EMBEDDING_MODEL"),
+		BaseURL:  os.Getenv("SOURCERER_EMBEDDING_URL"),
+		APIKey:   os.Getenv("SOURCERER_EMBEDDING_API_KEY"),
+	}
+
+	cfg.RequestsPerSecond, _ = strconv.ParseFloat(os.Getenv("SOURCERER_EMBEDDING_RPS"), 64)
+	cfg.Concurrency, _ = strconv.Atoi(os.Getenv("SOURCERER_EMBEDDING_CONCURRENCY"))
+	cfg.BatchSize, _ = strconv.Atoi(os.Getenv("SOURCERER_EMBEDDING_BATCH_SIZE"))
+
+	if cfg.Provider == "" {
+		cfg.Provider = "ollama"
+		cfg.fallback = true
+		if os.Getenv("OPENAI_API_KEY") != "" {
+			cfg.Provider = "openai"
+			cfg.fallback = false
+		}
+	}
+
+	switch cfg.Provider {
+	case "openai", "openai-compat":
+		if cfg.APIKey == "" {
+			cfg.APIKey = os.Getenv("OPENAI_API_KEY")
+		}
+	case "ollama":
+		if cfg.BaseURL == "" {
+			cfg.BaseURL = os.Getenv("OLLAMA_ENDPOINT")
+		}
+		if cfg.Model == "" {
+			cfg.Model = os.Getenv("OLLAMA_MODEL")
+		}
+	}
+
+	return cfg
+}
+
+// embedMinSimilarity returns the similarity below which results are
+// considered irrelevant for the embedder's vectors
+func embedMinSimilarity(e Embedder) float32 {
+	floor, ok := e.(interface{ MinSimilarity() float32 })
+	if ok {
+		return floor.MinSimilarity()
+	}
+
+	return minSimilarity
+}
+
+// testEmbedder produces deterministic bag-of-words vectors without any
+// external service, so the index can be exercised in tests
+type testEmbedder struct {
+	dims int
+}
+
+func (e *testEmbedder) Provider() string {
+	return "test"
+}
+
+func (e *testEmbedder) Model() string {
+	return fmt.Sprintf("bow-%d", e.dims)
+}
+
+func (e *testEmbedder) Embed(_ context.Context, text string) ([]float32, error) {
+	vector := make([]float32, e.dims)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !('a' <= r && r <= 'z' || '0' <= r && r <= '9' || r == '_')
+	})
+
+	for _, word := range words {
+		vector[xxhash.Sum64String(word)%uint64(e.dims)]++
+	}
+
+	if len(words) == 0 {
+		vector[0] = 1
+	}
+
+	return vector, nil
+}
+
+func init() {
+	embedders.register("openai", func(cfg EmbedderConfig) (Embedder, error) {
+		if cfg.APIKey == "" {
+			return nil, errors.New("openai embeddings require OPENAI_API_KEY")
+		}
+
+		model := cmp.Or(cfg.Model, defaultOpenAIModel)
+		return newOpenAIEmbedder("openai", cmp.Or(cfg.BaseURL, defaultOpenAIURL), cfg, model), nil
+	})
+
+	embedders.register("openai-compat", func(cfg EmbedderConfig) (Embedder, error) {
+		if cfg.BaseURL == "" || cfg.Model == "" {
+			return nil, errors.New(
+				"openai-compat embeddings require SOURCERER_EMBEDDING_URL and SOURCERER_EMBEDDING_MODEL",
+			)
+		}
+
+		return newOpenAIEmbedder("openai-compat", cfg.BaseURL, cfg, cfg.Model), nil
+	})
+
+	embedders.register("ollama", func(cfg EmbedderConfig) (Embedder, error) {
+		endpoint := cmp.Or(cfg.BaseURL, defaultOllamaEndpoint)
+		model := cmp.Or(cfg.Model, defaultOllamaModel)
+
+		// Stdout carries the MCP protocol, so setup hints go to stderr
+		if cfg.fallback && (cfg.BaseURL == "" || cfg.Model == "") {
+			fmt.Fprintf(os.Stderr, "No OPENAI_API_KEY found. Using local Ollama embeddings with defaults:\n")
+			fmt.Fprintf(os.Stderr, "  Endpoint: %s\n", endpoint)
+			fmt.Fprintf(os.Stderr, "  Model: %s\n", model)
+			fmt.Fprintf(os.Stderr, "Ensure Ollama is running with the embedding model installed:\n")
+			fmt.Fprintf(os.Stderr, "  ollama pull %s\n", model)
+			fmt.Fprintf(os.Stderr, "To customize: set OLLAMA_ENDPOINT and/or OLLAMA_MODEL environment variables.\n\n")
+		}
+
+		return newOllamaEmbedder(endpoint, cfg, model), nil
+	})
+
+	embedders.register("lexical", func(cfg EmbedderConfig) (Embedder, error) {
+		return NewLexicalEmbedder(lexicalDims), nil
+	})
+
+	embedders.register("test", func(cfg EmbedderConfig) (Embedder, error) {
+		return &testEmbedder{dims: testEmbeddingDims}, nil
+	})
+}
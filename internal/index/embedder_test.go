@@ -0,0 +1,68 @@
+package index_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/st3v3nmw/sourcerer-mcp/internal/index"
+	"github.com/stretchr/testify/suite"
+)
+
+type EmbedderTestSuite struct {
+	suite.Suite
+}
+
+func (s *EmbedderTestSuite) TestConfigFromEnvDefaultsToOllama() {
+	s.T().Setenv("SOURCERER_EMBEDDER", "")
+	s.T().Setenv("OPENAI_API_KEY", "")
+	s.T().Setenv("OLLAMA_MODEL", "mxbai-embed-large")
+
+	cfg := index.EmbedderConfigFromEnv()
+	s.Equal("ollama", cfg.Provider)
+	s.Equal("mxbai-embed-large", cfg.Model)
+}
+
+func (s *EmbedderTestSuite) TestConfigFromEnvPrefersOpenAIKey() {
+	s.T().Setenv("SOURCERER_EMBEDDER", "")
+	s.T().Setenv("OPENAI_API_KEY", "sk-test")
+
+	cfg := index.EmbedderConfigFromEnv()
+	s.Equal("openai", cfg.Provider)
+	s.Equal("sk-test", cfg.APIKey)
+}
+
+func (s *EmbedderTestSuite) TestConfigFromEnvExplicitProvider() {
+	s.T().Setenv("SOURCERER_EMBEDDER", "openai-compat")
+	s.T().Setenv("SOURCERER_EMBEDDING_URL", "http://localhost:8080/v1")
+	s.T().Setenv("SOURCERER_EMBEDDING_MODEL", "bge-small")
+
+	embedder, err := index.NewEmbedder(index.EmbedderConfigFromEnv())
+	s.Require().NoError(err)
+	s.Equal("openai-compat", embedder.Provider())
+	s.Equal("bge-small", embedder.Model())
+}
+
+func (s *EmbedderTestSuite) TestUnknownProvider() {
+	_, err := index.NewEmbedder(index.EmbedderConfig{Provider: "nope"})
+	s.ErrorContains(err, `unknown embedding provider "nope"`)
+}
+
+func (s *EmbedderTestSuite) TestOpenAIRequiresKey() {
+	_, err := index.NewEmbedder(index.EmbedderConfig{Provider: "openai"})
+	s.Error(err)
+}
+
+func (s *EmbedderTestSuite) TestTestEmbedderIsDeterministic() {
+	embedder, err := index.NewEmbedder(index.EmbedderConfig{Provider: "test"})
+	s.Require().NoError(err)
+
+	a, err := embedder.Embed(context.Background(), "flush pending changes")
+	s.Require().NoError(err)
+	b, err := embedder.Embed(context.Background(), "flush pending changes")
+	s.Require().NoError(err)
+	s.Equal(a, b)
+}
+
+func TestEmbedderTestSuite(t *testing.T) {
+	suite.Run(t, new(EmbedderTestSuite))
+}
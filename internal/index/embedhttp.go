@@ -0,0 +1,341 @@
+package index
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// batchEmbedder is implemented by embedders that can embed several texts in
+// one request
+type batchEmbedder interface {
+	EmbedBatch(ctx context.Context, texts []string) ([][]float32, error)
+	BatchSize() int
+}
+
+// providerDefaults are the batch size and rate limits of the HTTP providers.
+// Local Ollama gets overwhelmed by parallel requests, so it's sent one batch
+// at a time.
+var providerDefaults = map[string]struct {
+	batchSize int
+	limits    rateLimits
+}{
+	"openai":        {batchSize: 128, limits: rateLimits{RequestsPerSecond: 50, Burst: 10, Concurrency: 4}},
+	"openai-compat": {batchSize: 32, limits: rateLimits{RequestsPerSecond: 10, Burst: 4, Concurrency: 4}},
+	"ollama":        {batchSize: 32, limits: rateLimits{Concurrency: 1}},
+}
+
+// httpStatusError is an error response from an embedding or rerank API
+type httpStatusError struct {
+	status     int
+	message    string
+	retryAfter time.Duration // 0 if the response didn't say
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("request failed with status %d: %s", e.status, e.message)
+}
+
+func (e *httpStatusError) retryable() bool {
+	return e.status == http.StatusTooManyRequests || e.status >= 500
+}
+
+type singleAttemptKey struct{}
+
+// withSingleAttempt makes embedding requests sent with the returned context
+// fail on the first error instead of being retried
+func withSingleAttempt(ctx context.Context) context.Context {
+	return context.WithValue(ctx, singleAttemptKey{}, true)
+}
+
+// embedClient posts embedding or rerank requests to an endpoint, pacing them
+// with the endpoint's rate limiter and retrying transient failures
+type embedClient struct {
+	http    *http.Client
+	limiter *rateLimiter
+	retry   retryPolicy
+}
+
+func newEmbedClient(provider, endpoint string, cfg EmbedderConfig) *embedClient {
+	limits := providerDefaults[provider].limits
+	if cfg.RequestsPerSecond > 0 {
+		limits.RequestsPerSecond = cfg.RequestsPerSecond
+		limits.Burst = max(limits.Burst, 1)
+	}
+	if cfg.Concurrency > 0 {
+		limits.Concurrency = cfg.Concurrency
+	}
+
+	return &embedClient{
+		http:    &http.Client{Timeout: 2 * time.Minute},
+		limiter: limiterFor(provider+" "+endpoint, limits),
+		retry:   defaultRetryPolicy,
+	}
+}
+
+func (c *embedClient) post(ctx context.Context, url, apiKey string, request, response any) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	attempts := c.retry.attempts
+	if ctx.Value(singleAttemptKey{}) != nil {
+		attempts = 1
+	}
+
+	for attempt := 0; ; attempt++ {
+		err = c.send(ctx, url, apiKey, body, response)
+		if err == nil || ctx.Err() != nil || attempt == attempts-1 || !retryable(err) {
+			return err
+		}
+
+		delay := c.retry.backoff(attempt)
+
+		// A Retry-After longer than the backoff would stall indexing
+		var status *httpStatusError
+		if errors.As(err, &status) && status.retryAfter > 0 {
+			delay = min(status.retryAfter, c.retry.max)
+			c.limiter.pause(delay)
+		}
+
+		err = sleep(ctx, delay)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (c *embedClient) send(ctx context.Context, url, apiKey string, body []byte, response any) error {
+	release, err := c.limiter.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return &httpStatusError{
+			status:     resp.StatusCode,
+			message:    strings.TrimSpace(string(data)),
+			retryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		}
+	}
+
+	err = json.Unmarshal(data, response)
+	if err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
+
+// retryable reports whether a request could succeed if it's sent again.
+// Refused connections aren't retried, the server isn't running.
+func retryable(err error) bool {
+	var status *httpStatusError
+	if errors.As(err, &status) {
+		return status.retryable()
+	}
+
+	return transient(err) && !errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// transient reports whether err is a network failure or a timeout, which may
+// not happen again, unlike a response that can't be decoded or used
+func transient(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, context.DeadlineExceeded)
+}
+
+// parseRetryAfter reads a Retry-After header, in seconds or as an HTTP date
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	seconds, err := strconv.Atoi(value)
+	if err == nil {
+		return time.Duration(max(seconds, 0)) * time.Second
+	}
+
+	date, err := http.ParseTime(value)
+	if err == nil {
+		return max(time.Until(date), 0)
+	}
+
+	return 0
+}
+
+// openAIEmbedder uses the OpenAI embeddings API, which OpenAI-compatible
+// servers (vLLM, llama.cpp, LM Studio, ...) also implement
+type openAIEmbedder struct {
+	provider  string
+	model     string
+	url       string
+	apiKey    string
+	batchSize int
+	client    *embedClient
+}
+
+func newOpenAIEmbedder(provider, baseURL string, cfg EmbedderConfig, model string) *openAIEmbedder {
+	url := strings.TrimSuffix(baseURL, "/") + "/embeddings"
+	return &openAIEmbedder{
+		provider:  provider,
+		model:     model,
+		url:       url,
+		apiKey:    cfg.APIKey,
+		batchSize: batchSize(provider, cfg),
+		client:    newEmbedClient(provider, url, cfg),
+	}
+}
+
+func (e *openAIEmbedder) Provider() string {
+	return e.provider
+}
+
+func (e *openAIEmbedder) Model() string {
+	return e.model
+}
+
+func (e *openAIEmbedder) BatchSize() int {
+	return e.batchSize
+}
+
+func (e *openAIEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	return embedOne(ctx, e, text)
+}
+
+func (e *openAIEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	var response struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+
+	err := e.client.post(ctx, e.url, e.apiKey, map[string]any{"model": e.model, "input": texts}, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	vectors := make([][]float32, len(texts))
+	for _, item := range response.Data {
+		if item.Index < 0 || item.Index >= len(texts) {
+			return nil, fmt.Errorf("embedding response has an out of range index %d", item.Index)
+		}
+
+		vectors[item.Index] = item.Embedding
+	}
+
+	return vectors, checkVectors(vectors)
+}
+
+// ollamaEmbedder uses Ollama's /api/embed endpoint
+type ollamaEmbedder struct {
+	model     string
+	url       string
+	batchSize int
+	client    *embedClient
+}
+
+func newOllamaEmbedder(endpoint string, cfg EmbedderConfig, model string) *ollamaEmbedder {
+	url := strings.TrimSuffix(endpoint, "/") + "/embed"
+	return &ollamaEmbedder{
+		model:     model,
+		url:       url,
+		batchSize: batchSize("ollama", cfg),
+		client:    newEmbedClient("ollama", url, cfg),
+	}
+}
+
+func (e *ollamaEmbedder) Provider() string {
+	return "ollama"
+}
+
+func (e *ollamaEmbedder) Model() string {
+	return e.model
+}
+
+func (e *ollamaEmbedder) BatchSize() int {
+	return e.batchSize
+}
+
+func (e *ollamaEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	return embedOne(ctx, e, text)
+}
+
+func (e *ollamaEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	var response struct {
+		Embeddings [][]float32 `json:"embeddings"`
+	}
+
+	err := e.client.post(ctx, e.url, "", map[string]any{"model": e.model, "input": texts}, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(response.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("got %d embeddings for %d texts", len(response.Embeddings), len(texts))
+	}
+
+	return response.Embeddings, checkVectors(response.Embeddings)
+}
+
+func batchSize(provider string, cfg EmbedderConfig) int {
+	if cfg.BatchSize > 0 {
+		return cfg.BatchSize
+	}
+
+	return providerDefaults[provider].batchSize
+}
+
+func embedOne(ctx context.Context, e batchEmbedder, text string) ([]float32, error) {
+	vectors, err := e.EmbedBatch(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+
+	return vectors[0], nil
+}
+
+func checkVectors(vectors [][]float32) error {
+	for i, vector := range vectors {
+		if len(vector) == 0 {
+			return fmt.Errorf("no embedding returned for text %d", i)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,415 @@
+package index_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/st3v3nmw/sourcerer-mcp/internal/index"
+	"github.com/stretchr/testify/suite"
+)
+
+type batchEmbedder interface {
+	EmbedBatch(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+type HTTPEmbedderTestSuite struct {
+	suite.Suite
+	ctx context.Context
+
+	mu       sync.Mutex
+	batches  [][]string
+	requests atomic.Int32
+	// respond overrides the response to the nth request (counting from 1)
+	respond func(n int32, w http.ResponseWriter) bool
+}
+
+func (s *HTTPEmbedderTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.batches = nil
+	s.requests.Store(0)
+	s.respond = nil
+}
+
+// server fakes an OpenAI-style or Ollama embeddings endpoint, embedding each
+// text as its length
+func (s *HTTPEmbedderTestSuite) server(ollama bool) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := s.requests.Add(1)
+		if s.respond != nil && s.respond(n, w) {
+			return
+		}
+
+		var request struct {
+			Model string   `json:"model"`
+			Input []string `json:"input"`
+		}
+		s.Require().NoError(json.NewDecoder(r.Body).Decode(&request))
+
+		s.mu.Lock()
+		s.batches = append(s.batches, request.Input)
+		s.mu.Unlock()
+
+		if ollama {
+			s.Equal("/api/embed", r.URL.Path)
+
+			var embeddings [][]float32
+			for _, text := range request.Input {
+				embeddings = append(embeddings, []float32{float32(len(text)), 1})
+			}
+			json.NewEncoder(w).Encode(map[string]any{"embeddings": embeddings})
+			return
+		}
+
+		s.Equal("/v1/embeddings", r.URL.Path)
+		s.Equal("Bearer key", r.Header.Get("Authorization"))
+
+		// Items can come back in any order, they carry their index
+		var data []map[string]any
+		for i := len(request.Input) - 1; i >= 0; i-- {
+			data = append(data, map[string]any{
+				"index":     i,
+				"embedding": []float32{float32(len(request.Input[i])), 1},
+			})
+		}
+		json.NewEncoder(w).Encode(map[string]any{"data": data})
+	}))
+	s.T().Cleanup(server.Close)
+
+	return server
+}
+
+func (s *HTTPEmbedderTestSuite) openAICompat(cfg index.EmbedderConfig) index.Embedder {
+	cfg.Provider = "openai-compat"
+	cfg.BaseURL = s.server(false).URL + "/v1"
+	cfg.Model = "bge-small"
+	cfg.APIKey = "key"
+
+	embedder, err := index.NewEmbedder(cfg)
+	s.Require().NoError(err)
+
+	return embedder
+}
+
+func (s *HTTPEmbedderTestSuite) TestBatchesOpenAI() {
+	embedder := s.openAICompat(index.EmbedderConfig{BatchSize: 2})
+
+	vectors, err := embedder.(batchEmbedder).EmbedBatch(s.ctx, []string{"a", "bb", "ccc"})
+	s.Require().NoError(err)
+	s.Equal([][]float32{{1, 1}, {2, 1}, {3, 1}}, vectors)
+	s.Equal([][]string{{"a", "bb", "ccc"}}, s.batches)
+
+	vector, err := embedder.Embed(s.ctx, "dddd")
+	s.Require().NoError(err)
+	s.Equal([]float32{4, 1}, vector)
+}
+
+func (s *HTTPEmbedderTestSuite) TestBatchesOllama() {
+	embedder, err := index.NewEmbedder(index.EmbedderConfig{
+		Provider: "ollama",
+		BaseURL:  s.server(true).URL + "/api",
+		Model:    "nomic-embed-text",
+	})
+	s.Require().NoError(err)
+
+	vectors, err := embedder.(batchEmbedder).EmbedBatch(s.ctx, []string{"a", "bb"})
+	s.Require().NoError(err)
+	s.Equal([][]float32{{1, 1}, {2, 1}}, vectors)
+}
+
+func (s *HTTPEmbedderTestSuite) TestIndexBatchesChunks() {
+	embedder, err := index.NewEmbedder(index.EmbedderConfig{
+		Provider:  "ollama",
+		BaseURL:   s.server(true).URL + "/api",
+		Model:     "nomic-embed-text",
+		BatchSize: 2,
+	})
+	s.Require().NoError(err)
+	index.RegisterEmbedder("fake-ollama", func(index.EmbedderConfig) (index.Embedder, error) {
+		return embedder, nil
+	})
+
+	idx, err := openIndex(s.T(), s.ctx, s.T().TempDir(), index.Config{
+		Embedder: index.EmbedderConfig{Provider: "fake-ollama"},
+	})
+	s.Require().NoError(err)
+	s.Require().NoError(idx.Index(s.ctx, testFile()))
+
+	// The startup probe, then the file's chunks two at a time
+	s.Require().NotEmpty(s.batches)
+	s.Equal([]string{"sourcerer"}, s.batches[0])
+	chunks := 0
+	for _, batch := range s.batches[1:] {
+		s.LessOrEqual(len(batch), 2)
+		chunks += len(batch)
+	}
+	s.Equal(len(testFile().Chunks), chunks)
+	s.Less(len(s.batches)-1, chunks)
+}
+
+func (s *HTTPEmbedderTestSuite) TestRetriesServerErrors() {
+	s.respond = func(n int32, w http.ResponseWriter) bool {
+		if n <= 2 {
+			http.Error(w, "EOF", http.StatusInternalServerError)
+			return true
+		}
+
+		return false
+	}
+
+	vector, err := s.openAICompat(index.EmbedderConfig{}).Embed(s.ctx, "a")
+	s.Require().NoError(err)
+	s.Equal([]float32{1, 1}, vector)
+	s.Equal(int32(3), s.requests.Load())
+}
+
+func (s *HTTPEmbedderTestSuite) TestHonoursRetryAfter() {
+	s.respond = func(n int32, w http.ResponseWriter) bool {
+		if n == 1 {
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, "slow down", http.StatusTooManyRequests)
+			return true
+		}
+
+		return false
+	}
+
+	start := time.Now()
+	_, err := s.openAICompat(index.EmbedderConfig{}).Embed(s.ctx, "a")
+	s.Require().NoError(err)
+	s.GreaterOrEqual(time.Since(start), time.Second)
+	s.Equal(int32(2), s.requests.Load())
+}
+
+func (s *HTTPEmbedderTestSuite) TestDoesNotRetryClientErrors() {
+	s.respond = func(n int32, w http.ResponseWriter) bool {
+		http.Error(w, "unknown model", http.StatusBadRequest)
+		return true
+	}
+
+	_, err := s.openAICompat(index.EmbedderConfig{}).Embed(s.ctx, "a")
+	s.ErrorContains(err, "unknown model")
+	s.Equal(int32(1), s.requests.Load())
+}
+
+func (s *HTTPEmbedderTestSuite) TestDoesNotRetryMalformedResponses() {
+	s.respond = func(n int32, w http.ResponseWriter) bool {
+		w.Write([]byte("<html>proxy error</html>"))
+		return true
+	}
+
+	_, err := s.openAICompat(index.EmbedderConfig{}).Embed(s.ctx, "a")
+	s.ErrorContains(err, "failed to decode response")
+	s.Equal(int32(1), s.requests.Load())
+}
+
+func (s *HTTPEmbedderTestSuite) TestRetriesDroppedConnections() {
+	s.respond = func(n int32, w http.ResponseWriter) bool {
+		if n > 1 {
+			return false
+		}
+
+		conn, _, err := w.(http.Hijacker).Hijack()
+		s.Require().NoError(err)
+		conn.Close()
+		return true
+	}
+
+	vector, err := s.openAICompat(index.EmbedderConfig{}).Embed(s.ctx, "a")
+	s.Require().NoError(err)
+	s.Equal([]float32{1, 1}, vector)
+	s.Equal(int32(2), s.requests.Load())
+}
+
+func (s *HTTPEmbedderTestSuite) TestGivesUp() {
+	s.respond = func(n int32, w http.ResponseWriter) bool {
+		http.Error(w, "overloaded", http.StatusServiceUnavailable)
+		return true
+	}
+
+	ctx, cancel := context.WithTimeout(s.ctx, 300*time.Millisecond)
+	defer cancel()
+
+	_, err := s.openAICompat(index.EmbedderConfig{}).Embed(ctx, "a")
+	s.Error(err)
+}
+
+func (s *HTTPEmbedderTestSuite) TestRateLimit() {
+	embedder := s.openAICompat(index.EmbedderConfig{RequestsPerSecond: 20, Concurrency: 4})
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := embedder.Embed(s.ctx, "a")
+			s.NoError(err)
+		}()
+	}
+	wg.Wait()
+
+	// openai-compat allows a burst of 4, the other 4 wait 50ms each
+	s.GreaterOrEqual(time.Since(start), 150*time.Millisecond)
+	s.Equal(int32(8), s.requests.Load())
+}
+
+func (s *HTTPEmbedderTestSuite) TestRateLimitPerConfiguration() {
+	server := s.server(false)
+	embedder := func(rps float64) index.Embedder {
+		embedder, err := index.NewEmbedder(index.EmbedderConfig{
+			Provider:          "openai-compat",
+			BaseURL:           server.URL + "/v1",
+			Model:             "bge-small",
+			APIKey:            "key",
+			RequestsPerSecond: rps,
+			Concurrency:       4,
+		})
+		s.Require().NoError(err)
+
+		return embedder
+	}
+
+	// The first embedder's limits don't apply to the second one
+	_, err := embedder(1000).Embed(s.ctx, "a")
+	s.Require().NoError(err)
+
+	paced := embedder(20)
+	start := time.Now()
+	for range 6 {
+		_, err := paced.Embed(s.ctx, "a")
+		s.Require().NoError(err)
+	}
+
+	// A burst of 4, then 50ms for each of the other 2
+	s.GreaterOrEqual(time.Since(start), 100*time.Millisecond)
+}
+
+// indexed returns an index of testFile embedded by the fake server
+func (s *HTTPEmbedderTestSuite) indexed() *index.Index {
+	idx, err := openIndex(s.T(), s.ctx, s.T().TempDir(), index.Config{
+		Embedder: index.EmbedderConfig{
+			Provider: "openai-compat",
+			BaseURL:  s.server(false).URL + "/v1",
+			Model:    "bge-small",
+			APIKey:   "key",
+		},
+		QueryTimeout: time.Second,
+	})
+	s.Require().NoError(err)
+	s.Require().NoError(idx.Index(s.ctx, testFile()))
+
+	return idx
+}
+
+func (s *HTTPEmbedderTestSuite) TestSearchDoesNotRetryQueryEmbedding() {
+	idx := s.indexed()
+
+	requests := s.requests.Load()
+	s.respond = func(n int32, w http.ResponseWriter) bool {
+		http.Error(w, "overloaded", http.StatusServiceUnavailable)
+		return true
+	}
+
+	start := time.Now()
+	results, err := idx.Search(s.ctx, "ParseConfig", index.SearchOptions{FileTypes: []string{"src"}})
+	s.ErrorIs(err, index.ErrDegraded)
+	s.NotEmpty(results)
+	s.Less(time.Since(start), time.Second)
+	s.Equal(requests+1, s.requests.Load())
+}
+
+func (s *HTTPEmbedderTestSuite) TestSearchGivesUpOnSlowQueryEmbedding() {
+	idx := s.indexed()
+
+	hang := make(chan struct{})
+	defer close(hang)
+	s.respond = func(n int32, w http.ResponseWriter) bool {
+		<-hang
+		return true
+	}
+
+	start := time.Now()
+	results, err := idx.Search(s.ctx, "ParseConfig", index.SearchOptions{FileTypes: []string{"src"}})
+	s.ErrorIs(err, index.ErrDegraded)
+	s.NotEmpty(results)
+	s.Less(time.Since(start), 3*time.Second)
+}
+
+func (s *HTTPEmbedderTestSuite) TestSearchFailsWhenQueryIsRejected() {
+	idx := s.indexed()
+
+	s.respond = func(n int32, w http.ResponseWriter) bool {
+		http.Error(w, "invalid api key", http.StatusUnauthorized)
+		return true
+	}
+
+	results, err := idx.Search(s.ctx, "ParseConfig", index.SearchOptions{FileTypes: []string{"src"}})
+	s.ErrorContains(err, "invalid api key")
+	s.NotErrorIs(err, index.ErrDegraded)
+	s.Empty(results)
+}
+
+func (s *HTTPEmbedderTestSuite) TestInitializationDoesNotWaitForHungEmbedder() {
+	hang := make(chan struct{})
+	defer close(hang)
+	s.respond = func(n int32, w http.ResponseWriter) bool {
+		<-hang
+		return true
+	}
+
+	start := time.Now()
+	_, err := openIndex(s.T(), s.ctx, s.T().TempDir(), index.Config{
+		Embedder: index.EmbedderConfig{
+			Provider: "openai-compat",
+			BaseURL:  s.server(false).URL + "/v1",
+			Model:    "bge-small",
+			APIKey:   "key",
+		},
+	})
+	s.Require().NoError(err)
+	s.Less(time.Since(start), 10*time.Second)
+	s.Equal(int32(1), s.requests.Load())
+}
+
+func (s *HTTPEmbedderTestSuite) TestRejectedRequestsAreNotQueued() {
+	idx := s.indexed()
+
+	s.respond = func(n int32, w http.ResponseWriter) bool {
+		http.Error(w, "invalid api key", http.StatusUnauthorized)
+		return true
+	}
+
+	file := testFile()
+	file.Chunks[0].Source += "\n// validated"
+	err := idx.Index(s.ctx, file)
+	s.ErrorContains(err, "invalid api key")
+	s.NotErrorIs(err, index.ErrEmbeddingQueued)
+	s.Zero(idx.EmbeddingBacklog().Files)
+}
+
+func (s *HTTPEmbedderTestSuite) TestUnusableResponsesAreNotQueued() {
+	idx := s.indexed()
+
+	// Every embedding is missing, like a server for another API
+	s.respond = func(n int32, w http.ResponseWriter) bool {
+		w.Write([]byte(`{"data": []}`))
+		return true
+	}
+
+	file := testFile()
+	file.Chunks[0].Source += "\n// validated"
+	err := idx.Index(s.ctx, file)
+	s.ErrorContains(err, "no embedding returned")
+	s.NotErrorIs(err, index.ErrEmbeddingQueued)
+	s.Zero(idx.EmbeddingBacklog().Files)
+}
+
+func TestHTTPEmbedderTestSuite(t *testing.T) {
+	suite.Run(t, new(HTTPEmbedderTestSuite))
+}
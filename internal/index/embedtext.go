@@ -0,0 +1,154 @@
+package index
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/cespare/xxhash"
+)
+
+// DefaultEmbeddingTemplate renders the text a chunk is embedded as. Besides
+// the source, it names where the chunk lives and what it is, which the
+// source of e.g. a method body doesn't say by itself.
+const DefaultEmbeddingTemplate = `File: {{.File}}
+{{- with .Symbol}}
+Symbol: {{.}}{{end}}
+{{- with .Language}}
+Language: {{.}}{{end}}
+{{- with .Kind}}
+Kind: {{.}}{{end}}
+{{- with .Doc}}
+
+{{.}}{{end}}
+
+{{.Code}}`
+
+// embeddingFields are the fields available to embedding templates
+type embeddingFields struct {
+	File     string // file path within workspace
+	Symbol   string // chunk path within file, e.g. Index::Search, empty for unnamed chunks
+	Language string
+	Kind     string // e.g. "method declaration"
+	Doc      string // comments preceding the chunk
+	Code     string // source without the doc comments
+}
+
+// embeddingTemplates picks the template chunks are embedded with by their
+// language
+type embeddingTemplates struct {
+	fallback   *template.Template
+	byLanguage map[string]*template.Template
+	key        string // identifies the templates, "" when using the default
+}
+
+// newEmbeddingTemplates parses the configured templates, keyed by language
+// with "" as the fallback for languages without one
+func newEmbeddingTemplates(sources map[string]string) (*embeddingTemplates, error) {
+	t := &embeddingTemplates{byLanguage: map[string]*template.Template{}}
+
+	fallback, err := template.New("").Parse(DefaultEmbeddingTemplate)
+	if err != nil {
+		return nil, err
+	}
+	t.fallback = fallback
+
+	var key strings.Builder
+	for _, language := range slices.Sorted(maps.Keys(sources)) {
+		tmpl, err := template.New(language).Parse(sources[language])
+		if err == nil {
+			// Catch references to unknown fields now rather than at index time
+			err = tmpl.Execute(io.Discard, embeddingFields{})
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid embedding template for %q: %w", language, err)
+		}
+
+		if language == "" {
+			t.fallback = tmpl
+		} else {
+			t.byLanguage[language] = tmpl
+		}
+
+		fmt.Fprintf(&key, "%s\x00%s\x00", language, sources[language])
+	}
+
+	if key.Len() > 0 {
+		t.key = fmt.Sprintf("%016x", xxhash.Sum64String(key.String()))
+	}
+
+	return t, nil
+}
+
+// render returns the text a stored chunk is embedded as
+func (t *embeddingTemplates) render(doc *Document) (string, error) {
+	tmpl, exists := t.byLanguage[doc.Metadata["language"]]
+	if !exists {
+		tmpl = t.fallback
+	}
+
+	// Parts of a split chunk are embedded as the chunk they belong to
+	symbol := cmp.Or(doc.Metadata["parent"], doc.Metadata["path"])
+	if isContentHash(symbol) {
+		symbol = ""
+	}
+
+	docComment := doc.Metadata["doc"]
+	code := doc.Content
+	codeStart, _ := strconv.Atoi(doc.Metadata["codeStart"])
+	switch {
+	case docComment == "":
+	case codeStart > 0 && codeStart <= len(code):
+		code = strings.TrimLeft(code[codeStart:], " \t\r\n")
+	default:
+		// Chunks stored before the doc's span was recorded
+		code = strings.TrimLeft(strings.TrimPrefix(code, docComment), "\n")
+	}
+
+	var text bytes.Buffer
+	err := tmpl.Execute(&text, embeddingFields{
+		File:     doc.Metadata["file"],
+		Symbol:   symbol,
+		Language: doc.Metadata["language"],
+		Kind:     strings.ReplaceAll(doc.Metadata["kind"], "_", " "),
+		Doc:      docComment,
+		Code:     code,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to render embedding text for %s: %w", doc.ID, err)
+	}
+
+	return text.String(), nil
+}
+
+// EmbeddingTemplatesFromEnv reads embedding templates from the environment:
+// SOURCERER_EMBEDDING_TEMPLATE replaces the default template, and
+// SOURCERER_EMBEDDING_TEMPLATE_<LANGUAGE> (e.g. _GO) the one for a language
+func EmbeddingTemplatesFromEnv() map[string]string {
+	const prefix = "SOURCERER_EMBEDDING_TEMPLATE"
+
+	templates := map[string]string{}
+	for _, entry := range os.Environ() {
+		name, value, _ := strings.Cut(entry, "=")
+		if !strings.HasPrefix(name, prefix) || value == "" {
+			continue
+		}
+
+		suffix := strings.TrimPrefix(name, prefix)
+		switch {
+		case suffix == "":
+			templates[""] = value
+		case strings.HasPrefix(suffix, "_"):
+			templates[strings.ToLower(suffix[1:])] = value
+		}
+	}
+
+	return templates
+}
@@ -0,0 +1,218 @@
+package index_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/st3v3nmw/sourcerer-mcp/internal/index"
+	"github.com/st3v3nmw/sourcerer-mcp/internal/parser"
+	"github.com/stretchr/testify/suite"
+)
+
+// recordingEmbedder records the texts sent to the embedder
+type recordingEmbedder struct {
+	index.Embedder
+}
+
+var (
+	embeddedTexts   []string
+	embeddedTextsMu sync.Mutex
+)
+
+func (e *recordingEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	embeddedTextsMu.Lock()
+	embeddedTexts = append(embeddedTexts, text)
+	embeddedTextsMu.Unlock()
+
+	return e.Embedder.Embed(ctx, text)
+}
+
+func init() {
+	index.RegisterEmbedder("recording", func(cfg index.EmbedderConfig) (index.Embedder, error) {
+		inner, err := index.NewEmbedder(index.EmbedderConfig{Provider: "test"})
+		if err != nil {
+			return nil, err
+		}
+
+		return &recordingEmbedder{Embedder: inner}, nil
+	})
+}
+
+type EmbeddingTextTestSuite struct {
+	suite.Suite
+	ctx           context.Context
+	workspaceRoot string
+}
+
+func (s *EmbeddingTextTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.workspaceRoot = s.T().TempDir()
+}
+
+func (s *EmbeddingTextTestSuite) newIndex(templates map[string]string) *index.Index {
+	embeddedTextsMu.Lock()
+	embeddedTexts = nil
+	embeddedTextsMu.Unlock()
+
+	idx, err := openIndex(s.T(), s.ctx, s.workspaceRoot, index.Config{
+		Embedder:           index.EmbedderConfig{Provider: "recording"},
+		EmbeddingTemplates: templates,
+	})
+	s.Require().NoError(err)
+
+	return idx
+}
+
+// embedded returns the texts embedded since the index was opened, without the
+// embedder probe made when opening it
+func (s *EmbeddingTextTestSuite) embedded() []string {
+	embeddedTextsMu.Lock()
+	defer embeddedTextsMu.Unlock()
+
+	var texts []string
+	for _, text := range embeddedTexts {
+		if text != "sourcerer" {
+			texts = append(texts, text)
+		}
+	}
+
+	return texts
+}
+
+func methodFile() *parser.File {
+	source := "// Search finds chunks matching query\nfunc (idx *Index) Search(query string) []string {\n\treturn nil\n}"
+
+	return &parser.File{
+		Path:     "internal/index/index.go",
+		Language: "go",
+		Chunks: []*parser.Chunk{{
+			File:      "internal/index/index.go",
+			Path:      "Index::Search",
+			Kind:      "method_declaration",
+			Type:      "src",
+			Summary:   "func (idx *Index) Search(query string) []string {",
+			Doc:       "// Search finds chunks matching query",
+			Source:    source,
+			StartLine: 1,
+			EndLine:   4,
+		}},
+	}
+}
+
+func (s *EmbeddingTextTestSuite) TestDefaultTemplateAddsContext() {
+	idx := s.newIndex(nil)
+	s.Require().NoError(idx.Index(s.ctx, methodFile()))
+
+	s.Equal([]string{`File: internal/index/index.go
+Symbol: Index::Search
+Language: go
+Kind: method declaration
+
+// Search finds chunks matching query
+
+func (idx *Index) Search(query string) []string {
+	return nil
+}`}, s.embedded())
+
+	// The stored chunk is still the raw source
+	chunk, err := idx.GetChunk(s.ctx, "internal/index/index.go::Index::Search")
+	s.Require().NoError(err)
+	s.Equal(methodFile().Chunks[0].Source, chunk.Source)
+}
+
+func (s *EmbeddingTextTestSuite) TestDocIsEmbeddedOnce() {
+	idx := s.newIndex(map[string]string{"": "{{.Symbol}}\n{{.Doc}}\n---\n{{.Code}}"})
+
+	// The doc comments are indented and separated by a blank line, so the
+	// joined doc isn't a prefix of the source
+	source := "// Search finds chunks\n\n\t// matching query\n\tfunc (idx *Index) Search() {}"
+	file := &parser.File{
+		Path:     "internal/index/index.go",
+		Language: "go",
+		Chunks: []*parser.Chunk{{
+			File:      "internal/index/index.go",
+			Path:      "Index::Search",
+			Type:      "src",
+			Doc:       "// Search finds chunks\n// matching query",
+			Source:    source,
+			CodeStart: uint(len("// Search finds chunks\n\n\t// matching query")),
+			StartLine: 1,
+			EndLine:   4,
+		}},
+	}
+	s.Require().NoError(idx.Index(s.ctx, file))
+
+	s.Equal([]string{"Index::Search\n// Search finds chunks\n// matching query\n---\nfunc (idx *Index) Search() {}"}, s.embedded())
+}
+
+func (s *EmbeddingTextTestSuite) TestPartsAreEmbeddedAsTheirChunk() {
+	idx := s.newIndex(map[string]string{"": "{{.Symbol}}: {{.Code}}"})
+
+	file := &parser.File{Path: "internal/index/index.go", Language: "go"}
+	for i, source := range []string{"func Search() {\n\tfind", "\tfind\n}"} {
+		file.Chunks = append(file.Chunks, &parser.Chunk{
+			File:      file.Path,
+			Path:      fmt.Sprintf("Index::Search#part%d", i+1),
+			Parent:    "Index::Search",
+			Type:      "src",
+			Source:    source,
+			StartLine: uint(1 + i),
+			EndLine:   uint(2 + i),
+		})
+	}
+	s.Require().NoError(idx.Index(s.ctx, file))
+
+	s.ElementsMatch([]string{"Index::Search: func Search() {\n\tfind", "Index::Search: \tfind\n}"}, s.embedded())
+}
+
+func (s *EmbeddingTextTestSuite) TestTemplatePerLanguage() {
+	idx := s.newIndex(map[string]string{"markdown": "{{.File}}: {{.Code}}"})
+
+	docs := &parser.File{
+		Path:     "README.md",
+		Language: "markdown",
+		Chunks: []*parser.Chunk{{
+			File:      "README.md",
+			Path:      "Usage",
+			Type:      "docs",
+			Source:    "## Usage",
+			StartLine: 1,
+			EndLine:   1,
+		}},
+	}
+	s.Require().NoError(idx.Index(s.ctx, docs))
+	s.Require().NoError(idx.Index(s.ctx, methodFile()))
+
+	texts := s.embedded()
+	s.Require().Len(texts, 2)
+	s.Equal("README.md: ## Usage", texts[0])
+	s.Contains(texts[1], "Symbol: Index::Search")
+}
+
+func (s *EmbeddingTextTestSuite) TestTemplateChangeRebuildsIndex() {
+	idx := s.newIndex(nil)
+	s.Require().NoError(idx.Index(s.ctx, methodFile()))
+	s.False(idx.Rebuilding())
+	s.Require().NoError(idx.Close())
+
+	idx = s.newIndex(map[string]string{"go": "{{.Symbol}}\n{{.Code}}"})
+	s.Require().Eventually(func() bool {
+		return !idx.Rebuilding()
+	}, 5*time.Second, 10*time.Millisecond)
+	s.Equal([]string{"Index::Search\nfunc (idx *Index) Search(query string) []string {\n\treturn nil\n}"}, s.embedded())
+}
+
+func (s *EmbeddingTextTestSuite) TestInvalidTemplate() {
+	_, err := openIndex(s.T(), s.ctx, s.workspaceRoot, index.Config{
+		Embedder:           index.EmbedderConfig{Provider: "test"},
+		EmbeddingTemplates: map[string]string{"go": "{{.Receiver}}"},
+	})
+	s.Error(err)
+}
+
+func TestEmbeddingTextTestSuite(t *testing.T) {
+	suite.Run(t, new(EmbeddingTextTestSuite))
+}
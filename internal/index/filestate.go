@@ -0,0 +1,143 @@
+package index
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/cespare/xxhash"
+)
+
+// fileState identifies the content a file's chunks were indexed from
+type fileState struct {
+	Hash string `json:"hash"`
+	Size int64  `json:"size"`
+}
+
+func newFileState(source []byte) fileState {
+	return fileState{
+		Hash: fmt.Sprintf("%x", xxhash.Sum64(source)),
+		Size: int64(len(source)),
+	}
+}
+
+// fileStateEntry is a line of the file state log
+type fileStateEntry struct {
+	Path    string `json:"path"`
+	Removed bool   `json:"removed,omitempty"`
+	fileState
+}
+
+// fileStateLog persists the state of indexed files in .sourcerer/files.log,
+// apart from their chunks so that editing a file only rewrites the chunks
+// that changed. Updates are appended as JSON lines, and the log is compacted
+// when it's loaded.
+type fileStateLog struct {
+	path string
+}
+
+func newFileStateLog(workspaceRoot string) *fileStateLog {
+	return &fileStateLog{path: filepath.Join(workspaceRoot, ".sourcerer", "files.log")}
+}
+
+// load replays the log into the latest state of each file and compacts it
+func (l *fileStateLog) load() (map[string]fileState, error) {
+	states := map[string]fileState{}
+
+	f, err := os.Open(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return states, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file states: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		var entry fileStateEntry
+		err = json.Unmarshal(scanner.Bytes(), &entry)
+		if err != nil || entry.Path == "" {
+			// An append was interrupted, the file gets re-indexed since it's stale
+			continue
+		}
+
+		if entry.Removed {
+			delete(states, entry.Path)
+		} else {
+			states[entry.Path] = entry.fileState
+		}
+	}
+
+	err = scanner.Err()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file states: %w", err)
+	}
+
+	return states, l.compact(states)
+}
+
+// compact replaces the log with one line per file
+func (l *fileStateLog) compact(states map[string]fileState) error {
+	var data []byte
+	for filePath, state := range states {
+		line, err := json.Marshal(fileStateEntry{Path: filePath, fileState: state})
+		if err != nil {
+			return fmt.Errorf("failed to encode file states: %w", err)
+		}
+
+		data = append(append(data, line...), '\n')
+	}
+
+	tmp := l.path + ".tmp"
+	err := os.WriteFile(tmp, data, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to write file states: %w", err)
+	}
+
+	err = os.Rename(tmp, l.path)
+	if err != nil {
+		return fmt.Errorf("failed to replace file states: %w", err)
+	}
+
+	return nil
+}
+
+// set records the content a file's chunks were indexed from
+func (l *fileStateLog) set(filePath string, state fileState) error {
+	return l.append(fileStateEntry{Path: filePath, fileState: state})
+}
+
+// remove forgets a file whose chunks were removed
+func (l *fileStateLog) remove(filePath string) error {
+	return l.append(fileStateEntry{Path: filePath, Removed: true})
+}
+
+func (l *fileStateLog) append(entry fileStateEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode file state: %w", err)
+	}
+
+	err = os.MkdirAll(filepath.Dir(l.path), 0o755)
+	if err != nil {
+		return fmt.Errorf("failed to write file state: %w", err)
+	}
+
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to write file state: %w", err)
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(line, '\n'))
+	if err != nil {
+		return fmt.Errorf("failed to write file state: %w", err)
+	}
+
+	return nil
+}
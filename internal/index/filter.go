@@ -0,0 +1,113 @@
+package index
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// chunkFilter decides from their metadata which chunks a search may return
+type chunkFilter struct {
+	types     map[string]bool // nil allows every type
+	languages map[string]bool // nil allows every language
+	directory string
+	include   []string
+	exclude   []string
+}
+
+// newChunkFilter builds the filter for opts, restricting chunks to fileTypes
+// unless it's empty
+func newChunkFilter(opts SearchOptions, fileTypes []string) (*chunkFilter, error) {
+	f := &chunkFilter{
+		types:     set(fileTypes),
+		languages: set(opts.Languages),
+		include:   opts.Include,
+		exclude:   opts.Exclude,
+	}
+
+	for _, pattern := range append(opts.Include, opts.Exclude...) {
+		if !doublestar.ValidatePattern(pattern) {
+			return nil, fmt.Errorf("invalid path pattern %q", pattern)
+		}
+	}
+
+	directory := strings.TrimSuffix(path.Clean(opts.Directory), "/")
+	if directory != "." {
+		f.directory = strings.TrimPrefix(directory, "./")
+	}
+
+	return f, nil
+}
+
+// narrow reports whether the filter looks at more than the chunk type, which
+// vector db queries can't filter on by themselves
+func (f *chunkFilter) narrow() bool {
+	return f.languages != nil || f.directory != "" || len(f.include) > 0 || len(f.exclude) > 0
+}
+
+func (f *chunkFilter) matches(metadata map[string]string) bool {
+	if f.types != nil && !f.types[metadata["type"]] {
+		return false
+	}
+
+	file := metadata["file"]
+	if f.languages != nil && !f.matchesLanguage(metadata["language"], file) {
+		return false
+	}
+
+	if f.directory != "" && file != f.directory && !strings.HasPrefix(file, f.directory+"/") {
+		return false
+	}
+
+	if len(f.include) > 0 && !matchesAny(f.include, file) {
+		return false
+	}
+
+	return !matchesAny(f.exclude, file)
+}
+
+// headerLanguages are the languages headers can be written in, whichever
+// they're parsed as. C and C++ share .h, which is parsed as C++.
+var headerLanguages = map[string][]string{
+	".h": {"c", "cpp"},
+}
+
+func (f *chunkFilter) matchesLanguage(language, file string) bool {
+	if f.languages[language] {
+		return true
+	}
+
+	for _, language := range headerLanguages[path.Ext(file)] {
+		if f.languages[language] {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchesAny(patterns []string, file string) bool {
+	for _, pattern := range patterns {
+		matched, _ := doublestar.Match(pattern, file)
+		if matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+func set(values []string) map[string]bool {
+	if len(values) == 0 {
+		return nil
+	}
+
+	result := make(map[string]bool, len(values))
+	for _, value := range values {
+		result[value] = true
+	}
+
+	return result
+}
@@ -0,0 +1,397 @@
+package index
+
+import (
+	"container/heap"
+	"math"
+	"math/rand/v2"
+	"sort"
+)
+
+const (
+	hnswM              = 16 // neighbours per node above layer 0, twice that on it
+	hnswEfConstruction = 100
+)
+
+// hnsw is a hierarchical navigable small world graph over normalized
+// embeddings. Searches descend greedily from the sparse top layer and
+// explore the dense bottom layer breadth-first, so they only compare the
+// query with a small part of the graph.
+//
+// Nodes keep their embeddings in the vector store's format, and queries are
+// compared with them the way the store rescores its candidates, so the graph
+// returns the same similarities as the store would. The codes are always
+// well formed, the graph encodes them or checks them when it's loaded.
+//
+// Removed nodes stay in the graph as tombstones that searches route through
+// but never return, so the graph doesn't lose connectivity.
+type hnsw struct {
+	quantizer quantizer
+	nodes     []hnswNode
+	ids       map[string]int32 // live nodes by document ID
+	entry     int32            // -1 when the graph is empty
+	maxLayer  int
+	deleted   int
+	rng       *rand.Rand
+}
+
+type hnswNode struct {
+	id       string
+	code     []byte
+	metadata map[string]string
+	friends  [][]int32 // neighbours on each layer the node is on
+	deleted  bool
+}
+
+// hnswSnapshot is an hnsw graph in a form that can be encoded
+type hnswSnapshot struct {
+	Nodes    []hnswNodeSnapshot
+	Entry    int32
+	MaxLayer int
+}
+
+type hnswNodeSnapshot struct {
+	ID       string
+	Code     []byte
+	Metadata map[string]string
+	Friends  [][]int32
+	Deleted  bool
+}
+
+type candidate struct {
+	node       int32
+	similarity float32
+}
+
+func newHNSW(q quantizer) *hnsw {
+	return &hnsw{
+		quantizer: q,
+		ids:       map[string]int32{},
+		entry:     -1,
+		rng:       rand.New(rand.NewPCG(1, 2)),
+	}
+}
+
+// snapshot returns the graph's nodes and entry point, sharing their slices
+func (h *hnsw) snapshot() hnswSnapshot {
+	nodes := make([]hnswNodeSnapshot, len(h.nodes))
+	for i, node := range h.nodes {
+		nodes[i] = hnswNodeSnapshot{
+			ID:       node.id,
+			Code:     node.code,
+			Metadata: node.metadata,
+			Friends:  node.friends,
+			Deleted:  node.deleted,
+		}
+	}
+
+	return hnswSnapshot{Nodes: nodes, Entry: h.entry, MaxLayer: h.maxLayer}
+}
+
+// hnswFromSnapshot restores a graph from its snapshot
+func hnswFromSnapshot(q quantizer, snapshot hnswSnapshot) *hnsw {
+	h := newHNSW(q)
+	h.nodes = make([]hnswNode, len(snapshot.Nodes))
+	for i, node := range snapshot.Nodes {
+		h.nodes[i] = hnswNode{
+			id:       node.ID,
+			code:     node.Code,
+			metadata: node.Metadata,
+			friends:  node.Friends,
+			deleted:  node.Deleted,
+		}
+
+		if node.Deleted {
+			h.deleted++
+		} else {
+			h.ids[node.ID] = int32(i)
+		}
+	}
+
+	h.entry = snapshot.Entry
+	h.maxLayer = snapshot.MaxLayer
+
+	return h
+}
+
+// len returns the number of live nodes
+func (h *hnsw) len() int {
+	return len(h.ids)
+}
+
+func (h *hnsw) insert(id string, vector []float32, metadata map[string]string) {
+	h.remove(id)
+
+	query := normalized(vector)
+	node := int32(len(h.nodes))
+	layer := int(-math.Log(1-h.rng.Float64()) / math.Log(hnswM))
+	h.nodes = append(h.nodes, hnswNode{
+		id:       id,
+		code:     h.quantizer.encode(query),
+		metadata: metadata,
+		friends:  make([][]int32, layer+1),
+	})
+	h.ids[id] = node
+
+	if h.entry == -1 {
+		h.entry = node
+		h.maxLayer = layer
+		return
+	}
+
+	entry := h.entry
+	for l := h.maxLayer; l > layer; l-- {
+		entry = h.greedy(query, entry, l)
+	}
+
+	for l := min(layer, h.maxLayer); l >= 0; l-- {
+		found := h.searchLayer(query, entry, hnswEfConstruction, l)
+		friends := h.selectNeighbours(found, maxFriends(l))
+
+		h.nodes[node].friends[l] = make([]int32, len(friends))
+		for i, friend := range friends {
+			h.nodes[node].friends[l][i] = friend.node
+			h.link(friend.node, node, l)
+		}
+
+		entry = found[0].node
+	}
+
+	if layer > h.maxLayer {
+		h.entry = node
+		h.maxLayer = layer
+	}
+}
+
+// remove turns a node into a tombstone, it reports whether the ID was live
+func (h *hnsw) remove(id string) bool {
+	node, exists := h.ids[id]
+	if !exists {
+		return false
+	}
+
+	h.nodes[node].deleted = true
+	h.nodes[node].metadata = nil
+	delete(h.ids, id)
+	h.deleted++
+
+	return true
+}
+
+// search returns the n live nodes most similar to a normalized query whose
+// metadata passes match, exploring ef candidates on the bottom layer
+func (h *hnsw) search(query []float32, n, ef int, match func(map[string]string) bool) []candidate {
+	if h.entry == -1 {
+		return nil
+	}
+
+	entry := h.entry
+	for l := h.maxLayer; l > 0; l-- {
+		entry = h.greedy(query, entry, l)
+	}
+
+	var results []candidate
+	for _, found := range h.searchLayer(query, entry, max(ef, n), 0) {
+		node := &h.nodes[found.node]
+		if node.deleted || !match(node.metadata) {
+			continue
+		}
+
+		results = append(results, found)
+		if len(results) == n {
+			break
+		}
+	}
+
+	return results
+}
+
+// scan compares the query with every live node whose metadata passes match
+func (h *hnsw) scan(query []float32, n int, match func(map[string]string) bool) []candidate {
+	var results []candidate
+	for _, node := range h.ids {
+		if match(h.nodes[node].metadata) {
+			results = append(results, candidate{node, h.similarity(query, node)})
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].similarity > results[j].similarity
+	})
+
+	return results[:min(n, len(results))]
+}
+
+// greedy walks a layer towards the query until no neighbour is closer
+func (h *hnsw) greedy(query []float32, entry int32, layer int) int32 {
+	best := entry
+	bestSimilarity := h.similarity(query, entry)
+
+	for improved := true; improved; {
+		improved = false
+		for _, friend := range h.nodes[best].friends[layer] {
+			similarity := h.similarity(query, friend)
+			if similarity > bestSimilarity {
+				best, bestSimilarity = friend, similarity
+				improved = true
+			}
+		}
+	}
+
+	return best
+}
+
+// searchLayer returns the ef nodes closest to the query that it finds on a
+// layer, closest first
+func (h *hnsw) searchLayer(query []float32, entry int32, ef int, layer int) []candidate {
+	start := candidate{entry, h.similarity(query, entry)}
+	visited := map[int32]bool{entry: true}
+	frontier := &candidateHeap{items: []candidate{start}, closestFirst: true}
+	found := &candidateHeap{items: []candidate{start}}
+
+	for frontier.Len() > 0 {
+		current := heap.Pop(frontier).(candidate)
+		if found.Len() >= ef && current.similarity < found.items[0].similarity {
+			break
+		}
+
+		for _, friend := range h.nodes[current.node].friends[layer] {
+			if visited[friend] {
+				continue
+			}
+			visited[friend] = true
+
+			next := candidate{friend, h.similarity(query, friend)}
+			if found.Len() < ef || next.similarity > found.items[0].similarity {
+				heap.Push(frontier, next)
+				heap.Push(found, next)
+				if found.Len() > ef {
+					heap.Pop(found)
+				}
+			}
+		}
+	}
+
+	sort.Slice(found.items, func(i, j int) bool {
+		return found.items[i].similarity > found.items[j].similarity
+	})
+
+	return found.items
+}
+
+// selectNeighbours picks up to m of the candidates (closest first) to link
+// to, preferring candidates that aren't closer to an already picked one so
+// links point in different directions
+func (h *hnsw) selectNeighbours(candidates []candidate, m int) []candidate {
+	if len(candidates) <= m {
+		return candidates
+	}
+
+	selected := make([]candidate, 0, m)
+	var skipped []candidate
+	for _, c := range candidates {
+		if len(selected) == m {
+			break
+		}
+
+		diverse := true
+		vector, _ := h.quantizer.decode(h.nodes[c.node].code)
+		for _, s := range selected {
+			if h.similarity(vector, s.node) > c.similarity {
+				diverse = false
+				break
+			}
+		}
+
+		if diverse {
+			selected = append(selected, c)
+		} else {
+			skipped = append(skipped, c)
+		}
+	}
+
+	for _, c := range skipped {
+		if len(selected) == m {
+			break
+		}
+
+		selected = append(selected, c)
+	}
+
+	return selected
+}
+
+// link adds a neighbour to a node's layer, pruning its neighbours if it has
+// too many
+func (h *hnsw) link(node, friend int32, layer int) {
+	friends := append(h.nodes[node].friends[layer], friend)
+	if len(friends) <= maxFriends(layer) {
+		h.nodes[node].friends[layer] = friends
+		return
+	}
+
+	vector, _ := h.quantizer.decode(h.nodes[node].code)
+	candidates := make([]candidate, len(friends))
+	for i, f := range friends {
+		candidates[i] = candidate{f, h.similarity(vector, f)}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].similarity > candidates[j].similarity
+	})
+
+	kept := h.selectNeighbours(candidates, maxFriends(layer))
+	friends = friends[:0]
+	for _, c := range kept {
+		friends = append(friends, c.node)
+	}
+
+	h.nodes[node].friends[layer] = friends
+}
+
+// similarity estimates the cosine similarity of a normalized query and a node
+func (h *hnsw) similarity(query []float32, node int32) float32 {
+	return h.quantizer.rescore(query, h.nodes[node].code)
+}
+
+func maxFriends(layer int) int {
+	if layer == 0 {
+		return 2 * hnswM
+	}
+
+	return hnswM
+}
+
+func matchesWhere(metadata, where map[string]string) bool {
+	for key, value := range where {
+		if metadata[key] != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+// candidateHeap orders candidates furthest first, or closest first
+type candidateHeap struct {
+	items        []candidate
+	closestFirst bool
+}
+
+func (h *candidateHeap) Len() int { return len(h.items) }
+
+func (h *candidateHeap) Less(i, j int) bool {
+	if h.closestFirst {
+		return h.items[i].similarity > h.items[j].similarity
+	}
+
+	return h.items[i].similarity < h.items[j].similarity
+}
+
+func (h *candidateHeap) Swap(i, j int) { h.items[i], h.items[j] = h.items[j], h.items[i] }
+
+func (h *candidateHeap) Push(x any) { h.items = append(h.items, x.(candidate)) }
+
+func (h *candidateHeap) Pop() any {
+	last := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+	return last
+}
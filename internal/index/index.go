@@ -1,192 +1,361 @@
 package index
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
-	"runtime"
+	"slices"
 	"sort"
 	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
-	"github.com/philippgille/chromem-go"
 	"github.com/st3v3nmw/sourcerer-mcp/internal/parser"
 )
 
 const (
 	//minSimilarity = 0.3
 	minSimilarity = 0.2
-	maxResults    = 5  // Conservative limit to avoid exceeding any filtered type's count
+	// defaultQueryTimeout is how long a search waits for its query's
+	// embedding before falling back to keyword search
+	defaultQueryTimeout = 5 * time.Second
 )
 
+// Config holds the settings an Index is built from
+type Config struct {
+	Embedder EmbedderConfig
+	Reranker RerankerConfig
+	Store    string // vector store backend, chromem if empty
+	ANN      ANNConfig
+	// Quantization is the format embeddings are stored in, full precision
+	// if empty. It needs the sqlite store, which is picked if Store is empty.
+	Quantization string
+	// EmbeddingTemplates override the text chunks are embedded as, keyed by
+	// language with "" for every other language
+	EmbeddingTemplates map[string]string
+	// QueryTimeout is how long searches wait for the embedder before falling
+	// back to keyword search, 5s if zero
+	QueryTimeout time.Duration
+}
+
+// ConfigFromEnv reads the index configuration from the environment
+func ConfigFromEnv() Config {
+	return Config{
+		Embedder: EmbedderConfigFromEnv(),
+		Reranker: RerankerConfigFromEnv(),
+		Store:    VectorStoreFromEnv(),
+		ANN:      ANNConfigFromEnv(),
+
+		Quantization:       QuantizationFromEnv(),
+		EmbeddingTemplates: EmbeddingTemplatesFromEnv(),
+		QueryTimeout:       QueryTimeoutFromEnv(),
+	}
+}
+
+// QueryTimeoutFromEnv reads how long searches wait for the embedder from
+// SOURCERER_QUERY_TIMEOUT, a duration such as "2s"
+func QueryTimeoutFromEnv() time.Duration {
+	timeout, err := time.ParseDuration(os.Getenv("SOURCERER_QUERY_TIMEOUT"))
+	if err != nil || timeout < 0 {
+		return 0
+	}
+
+	return timeout
+}
+
 type Index struct {
 	workspaceRoot string
-	collection    *chromem.Collection
-
-	cache   map[string]int64 // filePath -> max parsedAt timestamp
+	embedder      Embedder
+	reranker      Reranker // nil when reranking is disabled
+	templates     *embeddingTemplates
+	spec          embeddingSpec
+	storeName     string
+	ann           ANNConfig
+	quantization  string
+	queryTimeout  time.Duration
+	db            VectorDB
+	collection    VectorStore
+	keywords      *keywordIndex
+	embeddings    *embeddingCache
+	changes       *changeLog
+	queue         *embedQueue
+
+	// previous is the collection of an earlier embedding model that's being
+	// re-embedded into collection, nil when no rebuild is in progress
+	previous atomic.Pointer[VectorStore]
+	writeMu  sync.Mutex // serializes changes to the collections
+	// previousDB holds previous, which can be another backend's db
+	previousDB VectorDB
+	// reuseEmbeddings is set when previous only differs in how its
+	// embeddings are stored, so they're copied rather than re-embedded
+	reuseEmbeddings bool
+
+	cache   map[string]fileState // filePath -> content its chunks were indexed from
 	cacheMu sync.RWMutex
+	files   *fileStateLog
 
 	initOnce sync.Once
 	initErr  error
+
+	// stop cancels the background rebuild and queue retries, which
+	// background tracks so Close can wait for them
+	stop       context.CancelFunc
+	background sync.WaitGroup
+	closeOnce  sync.Once
 }
 
 func New(ctx context.Context, workspaceRoot string) (*Index, error) {
-	// Create DB path relative to workspace root, not current directory
-	dbPath := filepath.Join(workspaceRoot, ".sourcerer/db")
-	db, err := chromem.NewPersistentDB(dbPath, false)
+	return NewWithConfig(ctx, workspaceRoot, ConfigFromEnv())
+}
+
+func NewWithConfig(ctx context.Context, workspaceRoot string, cfg Config) (*Index, error) {
+	embedder, err := NewEmbedder(cfg.Embedder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create embedder: %w", err)
+	}
+
+	reranker, err := NewReranker(cfg.Reranker)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create reranker: %w", err)
+	}
+
+	templates, err := newEmbeddingTemplates(cfg.EmbeddingTemplates)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = newQuantizer(cfg.Quantization)
 	if err != nil {
-	    return nil, fmt.Errorf("failed to create vector db: %w", err)
-	}
-
-	var collection *chromem.Collection
-	openaiAPIKey := os.Getenv("OPENAI_API_KEY")
-
-	if openaiAPIKey != "" {
-	    // Use OpenAI embeddings (existing functionality)
-	    collection, err = db.GetOrCreateCollection("code-chunks", nil, nil)
-	} else {
-	    
-	    // Check if we can use local Ollama embeddings
-	    ollamaEndpoint := os.Getenv("OLLAMA_ENDPOINT")
-	    if ollamaEndpoint == "" {
-	        ollamaEndpoint = "http://localhost:11434/api" // default
-	    }
-
-	    ollamaModel := os.Getenv("OLLAMA_MODEL")
-	    if ollamaModel == "" {
-	        ollamaModel = "nomic-embed-text" // default
-	    }
-
-	    // No OpenAI key found - inform user about Ollama setup
-	    if os.Getenv("OLLAMA_ENDPOINT") == "" || os.Getenv("OLLAMA_MODEL") == "" {
-            fmt.Printf("No OPENAI_API_KEY found. Using local Ollama embeddings with defaults:\n")
-            fmt.Printf("  Endpoint: %s\n", ollamaEndpoint)
-            fmt.Printf("  Model: %s\n", ollamaModel)
-            fmt.Printf("Ensure Ollama is running with the embedding model installed.\n")
-            fmt.Printf("To customize: set OLLAMA_ENDPOINT and/or OLLAMA_MODEL environment variables.\n")
-            fmt.Printf("Example:\n")
-            fmt.Printf("  export OLLAMA_ENDPOINT=http://127.0.0.1:11434\n")
-            fmt.Printf("  export OLLAMA_MODEL=nomic-embed-text\n\n")
-	    }
-
-		 collection, err = db.GetOrCreateCollection( "code-chunks", nil, 
-	    chromem.NewEmbeddingFuncOllama(ollamaModel, ollamaEndpoint) )
-
-	    // Provide specific error context for Ollama connection issues
-	    if err != nil {
-            return nil, fmt.Errorf("failed to create vector db collection with Ollama embeddings (endpoint: %s, model: %s): %w\n"+
-                "Ensure Ollama is running and the model is installed:\n"+
-                "  ollama pull %s\n"+
-                "Or set OPENAI_API_KEY to use OpenAI embeddings instead",
-                ollamaEndpoint, ollamaModel, err, ollamaModel)
-	    }
-	} //end else
-	
-  if err != nil {
-     return nil, fmt.Errorf("failed to create vector db collection: %w", err)
-	
-  }
+		return nil, err
+	}
+
+	if cfg.Quantization != QuantizationNone {
+		if cfg.Store == "" {
+			cfg.Store = "sqlite"
+		}
+
+		if cfg.Store != "sqlite" {
+			return nil, fmt.Errorf("quantization isn't supported by the %s vector store, use sqlite", cfg.Store)
+		}
+	}
 
 	idx := &Index{
 		workspaceRoot: workspaceRoot,
-		collection:    collection,
-		//cache:         map[string][]*ChunkMetadata{},
-		cache:         map[string]int64{},
+		storeName:     cfg.Store,
+		ann:           cfg.ANN,
+		quantization:  cfg.Quantization,
+		queryTimeout:  cmp.Or(cfg.QueryTimeout, defaultQueryTimeout),
+		embedder:      embedder,
+		reranker:      reranker,
+		templates:     templates,
+		keywords:      newKeywordIndex(),
+		embeddings:    newEmbeddingCache(cfg.Quantization),
+		changes:       &changeLog{},
+		cache:         map[string]fileState{},
+		files:         newFileStateLog(workspaceRoot),
 	}
 
-	idx.loadCache(ctx)
+	err = idx.ensureInitialized(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	return idx, nil
 }
 
-
-
 func (idx *Index) ensureInitialized(ctx context.Context) error {
 	idx.initOnce.Do(func() {
 		// Create DB path relative to workspace root, not current directory
-		dbPath := filepath.Join(idx.workspaceRoot, ".sourcerer/db")
-		db, err := chromem.NewPersistentDB(dbPath, false)
+		db, err := NewVectorDB(idx.storeName, filepath.Join(idx.workspaceRoot, ".sourcerer"))
 		if err != nil {
 			idx.initErr = fmt.Errorf("failed to create vector db: %w", err)
 			return
 		}
 
-		// ADD THE SAME OLLAMA LOGIC HERE
-		var collection *chromem.Collection
-		openaiAPIKey := os.Getenv("OPENAI_API_KEY")
-
-		if openaiAPIKey != "" {
-			collection, err = db.GetOrCreateCollection("code-chunks", nil, nil)
+		// Graphs are saved next to the db. Without the ANN index, the stores
+		// change without them, so they'd be out of date next time.
+		annDir := filepath.Join(idx.workspaceRoot, ".sourcerer", "ann")
+		if idx.ann.Enabled {
+			ann := idx.ann
+			ann.Dir = annDir
+			db = WithANN(db, ann)
 		} else {
-			ollamaEndpoint := os.Getenv("OLLAMA_ENDPOINT")
-			if ollamaEndpoint == "" {
-				ollamaEndpoint = "http://localhost:11434/api"
-			}
-			ollamaModel := os.Getenv("OLLAMA_MODEL")
-			if ollamaModel == "" {
-				ollamaModel = "nomic-embed-text"
+			os.RemoveAll(annDir)
+		}
+
+		idx.db = db
+		defer func() {
+			// The index is unusable, Close has nothing left to close
+			if idx.initErr != nil {
+				idx.closeDBs()
+				idx.db, idx.previousDB = nil, nil
 			}
-			collection, err = db.GetOrCreateCollection("code-chunks", nil, 
-				chromem.NewEmbeddingFuncOllama(ollamaModel, ollamaEndpoint))
+		}()
+
+		idx.queue, err = newEmbedQueue(idx.workspaceRoot)
+		if err != nil {
+			idx.initErr = err
+			return
 		}
 
-		//collection, err := db.GetOrCreateCollection("code-chunks", nil, nil)
+		err = idx.openCollections(ctx)
 		if err != nil {
-			idx.initErr = fmt.Errorf("failed to create vector db collection: %w", err)
+			idx.initErr = err
 			return
 		}
 
-		idx.collection = collection
-		idx.loadCache(ctx)
+		err = idx.loadCache(ctx)
+		if err != nil {
+			idx.initErr = err
+			return
+		}
+
+		// Background work outlives the caller's context, until Close
+		background, stop := context.WithCancel(context.WithoutCancel(ctx))
+		idx.stop = stop
+
+		if idx.Rebuilding() {
+			idx.goBackground(func() { idx.rebuild(background) })
+		}
+
+		idx.goBackground(func() { idx.retryQueued(background) })
 	})
 
 	return idx.initErr
 }
 
-func (idx *Index) loadCache(ctx context.Context) {
+func (idx *Index) goBackground(f func()) {
+	idx.background.Add(1)
+	go func() {
+		defer idx.background.Done()
+		f()
+	}()
+}
+
+// Close stops the index's background work and closes its vector db. Queued
+// files stay queued for the next start.
+func (idx *Index) Close() error {
+	var err error
+	idx.closeOnce.Do(func() {
+		if idx.stop != nil {
+			idx.stop()
+			idx.background.Wait()
+		}
+
+		idx.writeMu.Lock()
+		defer idx.writeMu.Unlock()
+
+		err = idx.closeDBs()
+	})
+
+	return err
+}
+
+// closeDBs closes the vector db and the one a rebuild reads from, if they're
+// open. The caller must hold writeMu or be initializing the index.
+func (idx *Index) closeDBs() error {
+	var err error
+	if idx.previousDB != nil && idx.previousDB != idx.db {
+		err = idx.previousDB.Close()
+	}
+
+	if idx.db != nil {
+		err = errors.Join(err, idx.db.Close())
+	}
+
+	return err
+}
+
+func (idx *Index) loadCache(ctx context.Context) error {
 	idx.cacheMu.Lock()
 	defer idx.cacheMu.Unlock()
 
-	docs, err := idx.collection.ListDocumentsShallow(ctx)
+	states, err := idx.files.load()
 	if err != nil {
-		return
+		return err
+	}
+
+	docs, err := idx.collection.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list documents in vector db: %w", err)
+	}
+
+	idx.embeddings.reset(docs)
+
+	// Chunks that weren't re-embedded yet are still searchable by keyword
+	previous := idx.previousStore()
+	if previous != nil {
+		pending, err := previous.List(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list documents in vector db: %w", err)
+		}
+
+		docs = append(docs, pending...)
 	}
 
-	fileMaxParsed := make(map[string]int64)
+	seen := make(map[string]bool, len(docs))
+	files := make(map[string]fileState)
 	for _, doc := range docs {
-		filePath := doc.Metadata["file"]
-		_, exists := fileMaxParsed[filePath]
-		if exists {
+		// An interrupted migration can leave a chunk in both collections
+		if seen[doc.ID] {
 			continue
 		}
+		seen[doc.ID] = true
 
-		parsedAt, err := strconv.ParseInt(doc.Metadata["parsedAt"], 10, 64)
-		if err != nil {
-			continue
+		idx.observe(doc, 1)
+		idx.keywords.add(doc.ID, doc.Content, doc.Metadata)
+
+		// Files without a recorded state, e.g. indexed before states were
+		// recorded, are stale
+		filePath := doc.Metadata["file"]
+		state, ok := states[filePath]
+		if ok {
+			files[filePath] = state
 		}
+	}
 
-		fileMaxParsed[filePath] = parsedAt
+	// Queued files are up to date, they're indexed once the embedder is back
+	for _, filePath := range idx.queue.paths() {
+		file := idx.queue.get(filePath)
+		if file != nil && file.State.Hash != "" {
+			files[filePath] = file.State
+		}
 	}
 
-	idx.cache = fileMaxParsed
+	idx.cache = files
+
+	return nil
 }
 
+// IsStale reports whether a workspace file's content differs from what its
+// chunks were indexed from. Modification times aren't trusted since they
+// change on checkouts and copies without the content changing.
 func (idx *Index) IsStale(ctx context.Context, filePath string) bool {
-	fileInfo, err := os.Stat(filePath)
-	if err != nil {
+	idx.cacheMu.RLock()
+	state, exists := idx.cache[filePath]
+	idx.cacheMu.RUnlock()
+
+	if !exists {
 		return true
 	}
 
-	idx.cacheMu.RLock()
-	defer idx.cacheMu.RUnlock()
+	fullPath := filepath.Join(idx.workspaceRoot, filePath)
+	fileInfo, err := os.Stat(fullPath)
+	if err != nil || fileInfo.Size() != state.Size {
+		return true
+	}
 
-	maxParsedAt, exists := idx.cache[filePath]
-	if !exists {
+	source, err := os.ReadFile(fullPath)
+	if err != nil {
 		return true
 	}
 
-	return fileInfo.ModTime().Unix() > maxParsedAt
+	return newFileState(source) != state
 }
 
 func (idx *Index) Index(ctx context.Context, file *parser.File) error {
@@ -195,29 +364,33 @@ func (idx *Index) Index(ctx context.Context, file *parser.File) error {
 		return err
 	}
 
-	err = idx.Remove(ctx, file.Path)
-	if err != nil {
-		return err
-	}
+	idx.writeMu.Lock()
+	defer idx.writeMu.Unlock()
 
 	if len(file.Chunks) == 0 {
-		return nil
+		return idx.remove(ctx, file.Path)
 	}
 
-	docs := []chromem.Document{}
+	docs := []Document{}
 	for _, chunk := range file.Chunks {
-		doc := chromem.Document{
+		doc := Document{
 			ID: chunk.ID(),
 			Metadata: map[string]string{
 				"file":        file.Path,
 				"type":        chunk.Type,
 				"path":        chunk.Path,
+				"parent":      chunk.Parent,
+				"kind":        chunk.Kind,
 				"summary":     chunk.Summary,
+				"doc":         chunk.Doc,
+				"codeStart":   strconv.Itoa(int(chunk.CodeStart)),
+				"ranges":      encodeRanges(chunk.Ranges),
 				"startLine":   strconv.Itoa(int(chunk.StartLine)),
 				"startColumn": strconv.Itoa(int(chunk.StartColumn)),
 				"endLine":     strconv.Itoa(int(chunk.EndLine)),
 				"endColumn":   strconv.Itoa(int(chunk.EndColumn)),
 				"parsedAt":    strconv.FormatInt(chunk.ParsedAt, 10),
+				"language":    file.Language,
 			},
 			Content: chunk.Source,
 		}
@@ -225,78 +398,327 @@ func (idx *Index) Index(ctx context.Context, file *parser.File) error {
 		docs = append(docs, doc)
 	}
 
-	// Use lower concurrency for Ollama to avoid overwhelming the service
-	// OpenAI can handle more concurrent requests, but local Ollama needs rate limiting
-	concurrency := 1  // Sequential processing for stability with Ollama
-	if os.Getenv("OPENAI_API_KEY") != "" {
-		concurrency = runtime.NumCPU() // OpenAI can handle more
+	return idx.index(ctx, file.Path, newFileState(file.Source), docs)
+}
+
+// index brings a file's stored chunks in line with docs, parsed from content
+// with the given state. If the embedder is down, docs are queued and retried
+// in the background, leaving the previous version of the file searchable.
+// The caller must hold writeMu.
+func (idx *Index) index(ctx context.Context, filePath string, state fileState, docs []Document) error {
+	// The file's chunks of a previous model are migrated first, so they're
+	// diffed against the new ones
+	err := idx.migrate(ctx, filePath)
+	if err != nil {
+		return idx.enqueue(filePath, state, docs, err)
+	}
+
+	stored, err := idx.collection.GetWhere(ctx, map[string]string{"file": filePath})
+	if err != nil {
+		return fmt.Errorf("failed to look up documents in vector db: %w", err)
 	}
 
-	err = idx.collection.AddDocuments(ctx, docs, concurrency)
+	diff := diffChunks(stored, docs)
+	err = idx.embedDocuments(ctx, diff.upserts)
 	if err != nil {
-		return fmt.Errorf("failed to add documents to vector db: %w", err)
+		return idx.enqueue(filePath, state, docs, fmt.Errorf("failed to embed documents: %w", err))
+	}
+
+	if !diff.empty() {
+		err = idx.apply(ctx, diff)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = idx.queue.remove(filePath)
+	if err != nil {
+		return err
+	}
+
+	if len(diff.upserts) > 0 {
+		// The embedder works, so queued files may be indexable again
+		idx.queue.notify()
+	}
+
+	err = idx.files.set(filePath, state)
+	if err != nil {
+		return err
+	}
+
+	idx.cacheMu.Lock()
+	defer idx.cacheMu.Unlock()
+
+	idx.cache[filePath] = state
+
+	return nil
+}
+
+// enqueue queues a file's docs if err means the embedder is down, and
+// returns err otherwise
+func (idx *Index) enqueue(filePath string, state fileState, docs []Document, err error) error {
+	if !queueable(err) {
+		return err
+	}
+
+	queueErr := idx.queue.add(filePath, state, docs, err)
+	if queueErr != nil {
+		return err
 	}
 
 	idx.cacheMu.Lock()
 	defer idx.cacheMu.Unlock()
 
-	if len(file.Chunks) > 0 {
-		idx.cache[file.Path] = file.Chunks[0].ParsedAt
+	idx.cache[filePath] = state
+
+	return fmt.Errorf("%w: %w", ErrEmbeddingQueued, err)
+}
+
+// apply writes a file's embedded chunk diff to the vector db and keeps the
+// keyword index, corpus statistics and change log in sync with it
+func (idx *Index) apply(ctx context.Context, diff *chunkDiff) error {
+	if len(diff.removed) > 0 {
+		ids := make([]string, 0, len(diff.removed))
+		for _, doc := range diff.removed {
+			ids = append(ids, doc.ID)
+		}
+
+		err := idx.collection.Delete(ctx, nil, ids...)
+		if err != nil {
+			return fmt.Errorf("failed to remove documents from vector db: %w", err)
+		}
+	}
+
+	if len(diff.upserts) > 0 {
+		err := idx.collection.Add(ctx, diff.upserts)
+		if err != nil {
+			return fmt.Errorf("failed to add documents to vector db: %w", err)
+		}
+	}
+
+	idx.pruneEmbeddings(ctx)
+
+	for _, doc := range slices.Concat(diff.replaced, diff.removed) {
+		idx.observe(doc, -1)
 	}
 
+	for _, doc := range diff.removed {
+		idx.keywords.remove(doc.ID)
+	}
+
+	for i := range diff.upserts {
+		doc := &diff.upserts[i]
+		idx.observe(doc, 1)
+		idx.keywords.add(doc.ID, doc.Content, doc.Metadata)
+	}
+
+	idx.changes.record(diff.changes)
+
 	return nil
 }
 
+// observe adds (delta > 0) or removes (delta < 0) a document from the corpus
+// statistics of embedders that keep them, as the text it's embedded as
+func (idx *Index) observe(doc *Document, delta int) {
+	observer, isObserver := idx.embedder.(corpusObserver)
+	if !isObserver {
+		return
+	}
+
+	// Documents that can't be rendered can't be embedded either
+	text, err := idx.templates.render(doc)
+	if err != nil {
+		return
+	}
+
+	observer.observe(text, delta)
+}
+
 func (idx *Index) Remove(ctx context.Context, filePath string) error {
 	err := idx.ensureInitialized(ctx)
 	if err != nil {
 		return err
 	}
 
+	idx.writeMu.Lock()
+	defer idx.writeMu.Unlock()
+
+	return idx.remove(ctx, filePath)
+}
+
+// remove drops a file's chunks from the index. The caller must hold writeMu.
+func (idx *Index) remove(ctx context.Context, filePath string) error {
 	where := map[string]string{"file": filePath}
-	err = idx.collection.Delete(ctx, where, nil)
+
+	var removed []*Document
+	for _, collection := range []VectorStore{idx.collection, idx.previousStore()} {
+		if collection == nil {
+			continue
+		}
+
+		docs, err := collection.GetWhere(ctx, where)
+		if err != nil {
+			return fmt.Errorf("failed to look up documents in vector db: %w", err)
+		}
+
+		err = collection.Delete(ctx, where)
+		if err != nil {
+			return fmt.Errorf("failed to remove documents from vector db: %w", err)
+		}
+
+		removed = append(removed, docs...)
+	}
+
+	err := idx.queue.remove(filePath)
 	if err != nil {
-		return fmt.Errorf("failed to remove documents from vector db: %w", err)
+		return err
 	}
 
+	idx.keywords.removeFile(filePath)
+
+	changes := make([]ChunkChange, 0, len(removed))
+	for _, doc := range removed {
+		idx.observe(doc, -1)
+		changes = append(changes, newChunkChange(doc.ID, doc.Metadata, ChunkRemoved))
+	}
+
+	idx.changes.record(changes)
+
 	idx.cacheMu.Lock()
 	defer idx.cacheMu.Unlock()
 
+	_, recorded := idx.cache[filePath]
+	if !recorded {
+		return nil
+	}
+
 	delete(idx.cache, filePath)
 
-	return nil
+	return idx.files.remove(filePath)
 }
 
-func (idx *Index) Search(ctx context.Context, query string, fileTypes []string) ([]string, error) {
+// LatestChange returns the sequence number of the most recent chunk change
+func (idx *Index) LatestChange() uint64 {
+	return idx.changes.latest()
+}
+
+// ChangesSince returns the net chunk changes applied after the given sequence
+// number, oldest first. Only the most recent changes are kept, so very old
+// sequence numbers may miss some.
+func (idx *Index) ChangesSince(seq uint64) []ChunkChange {
+	return idx.changes.since(seq)
+}
+
+// Search returns the chunks most relevant to the query. If the query can't be
+// embedded, it returns keyword matches along with ErrDegraded.
+func (idx *Index) Search(ctx context.Context, query string, opts SearchOptions) ([]string, error) {
 	err := idx.ensureInitialized(ctx)
 	if err != nil {
 		return nil, err
 	}
 
+	fileTypes := opts.FileTypes
 	if len(fileTypes) == 0 {
 		fileTypes = []string{"src", "docs"}
 	}
 
+	mode := opts.Mode
+	if mode == "" {
+		mode = SearchHybrid
+	}
+
+	filter, err := newChunkFilter(opts, fileTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	offset, limit := opts.window(DefaultSearchLimit)
+	candidates := candidatesPerResult * (offset + limit)
+	if idx.reranker != nil {
+		// Give the reranker enough to pull low-ranked chunks into the page
+		candidates = max(candidates, maxRerankCandidates)
+	}
+
+	var semantic, lexical []Result
+	var degraded error
+	if mode != SearchLexical {
+		semantic, err = idx.semanticResults(ctx, query, fileTypes, filter, candidates, opts.MinScore)
+		// An embedder rejecting the query, e.g. for a bad API key, is a
+		// misconfiguration rather than an outage
+		if err != nil && (query == "" || ctx.Err() != nil || !queueable(err)) {
+			return nil, err
+		}
+
+		if err != nil {
+			// Keyword search doesn't need the embedder, so agents can keep
+			// navigating while it's down
+			degraded = fmt.Errorf("%w: %w", ErrDegraded, err)
+			mode = SearchLexical
+		}
+	}
+
+	if mode != SearchSemantic {
+		lexical = idx.lexicalResults(query, filter, candidates)
+	}
+
+	var results []Result
+	switch mode {
+	case SearchSemantic:
+		results = semantic
+	case SearchLexical:
+		results = lexical
+	default:
+		results = fuseResults(semantic, lexical)
+	}
+
+	// The reranker is usually served by the same provider as the embedder
+	rerankQuery := query
+	if degraded != nil {
+		rerankQuery = ""
+	}
+
+	// Relevance cut-offs were applied per retriever, fused scores aren't similarities
+	paths, err := idx.formatSearchResults(ctx, results, 0, offset, limit, opts.Lambda, rerankQuery, "", filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return paths, degraded
+}
+
+// semanticResults returns up to n chunks of each of the given file types that
+// pass filter, ranked by vector similarity to the query, dropping those below
+// minScore or, if it's zero, the embedder's relevance cut-off
+func (idx *Index) semanticResults(
+	ctx context.Context,
+	query string,
+	fileTypes []string,
+	filter *chunkFilter,
+	n int,
+	minScore float32,
+) ([]Result, error) {
+	queryEmbedding, err := idx.embedQuery(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
 	// Query each file type separately and merge results
 	// This ensures we get results for each type even if one type has many more chunks
-	var allResults []chromem.Result
+	var allResults []Result
 	seenIDs := make(map[string]bool)
 
 	for _, fileType := range fileTypes {
 		where := map[string]string{"type": fileType}
 
-		// Determine how many results to request
-		// Use a conservative number to avoid exceeding any single type's count
-		nResults := min(maxResults, idx.collection.Count())
-		if nResults == 0 {
+		if idx.collection.Count() == 0 {
 			continue // Empty collection, skip this type
 		}
 
-		results, err := idx.collection.Query(ctx, query, nResults, where, nil)
+		results, err := idx.query(ctx, queryEmbedding, n, where, filter)
 		if err != nil {
 			// If we requested too many results for this filtered type, try with just 1
 			// This handles cases where a type has very few documents
-			results, err = idx.collection.Query(ctx, query, 1, where, nil)
+			results, err = idx.collection.Query(ctx, queryEmbedding, 1, where)
 			if err != nil {
 				// Even 1 result failed, skip this type (probably empty)
 				continue
@@ -304,82 +726,231 @@ func (idx *Index) Search(ctx context.Context, query string, fileTypes []string)
 		}
 
 		// Merge results, avoiding duplicates
+		kept := 0
 		for _, result := range results {
-			if !seenIDs[result.ID] {
+			if kept == n {
+				break
+			}
+
+			if !seenIDs[result.ID] && filter.matches(result.Metadata) {
 				allResults = append(allResults, result)
 				seenIDs[result.ID] = true
+				kept++
 			}
 		}
 	}
 
-	// No need for type filtering since we already filtered in the query
-	return idx.formatSearchResults(ctx, allResults, minSimilarity, maxResults, "", nil), nil
+	minSimilarity := cmp.Or(minScore, embedMinSimilarity(idx.embedder))
+	relevant := allResults[:0]
+	for _, result := range allResults {
+		if result.Similarity >= minSimilarity {
+			relevant = append(relevant, result)
+		}
+	}
+
+	sort.Slice(relevant, func(i, j int) bool {
+		return relevant[i].Similarity > relevant[j].Similarity
+	})
+
+	return relevant, nil
 }
 
-func (idx *Index) FindSimilarChunks(ctx context.Context, chunkID string) ([]string, error) {
+// query returns the n chunks matching where and filter that are most similar
+// to an embedding. Stores that can't apply filter while they search rank
+// every chunk matching where first.
+func (idx *Index) query(
+	ctx context.Context,
+	embedding []float32,
+	n int,
+	where map[string]string,
+	filter *chunkFilter,
+) ([]Result, error) {
+	// Never request more results than the collection holds
+	n = min(n, idx.collection.Count())
+
+	store, ok := idx.collection.(filteringStore)
+	if ok {
+		return store.QueryMatching(ctx, embedding, n, where, filter.matches)
+	}
+
+	if filter.narrow() || filter.types != nil && where["type"] == "" {
+		n = idx.collection.Count()
+	}
+
+	return idx.collection.Query(ctx, embedding, n, where)
+}
+
+// lexicalResults returns up to n chunks that pass filter ranked by BM25
+// keyword relevance to the query
+func (idx *Index) lexicalResults(query string, filter *chunkFilter, n int) []Result {
+	var results []Result
+	for _, hit := range idx.keywords.search(query, n, filter.matches) {
+		results = append(results, Result{ID: hit.id, Similarity: hit.score})
+	}
+
+	return results
+}
+
+// embedQuery embeds a search query, preferring the embedder's query-specific
+// embedding when it has one
+func (idx *Index) embedQuery(ctx context.Context, query string) ([]float32, error) {
+	if query == "" {
+		return nil, errors.New("query is empty")
+	}
+
+	embed := idx.embedder.Embed
+	qe, ok := idx.embedder.(queryEmbedder)
+	if ok {
+		embed = qe.EmbedQuery
+	}
+
+	// Searches fall back to keywords if the embedder is down, so they don't
+	// wait for it like indexing does
+	ctx, cancel := context.WithTimeout(withSingleAttempt(ctx), idx.queryTimeout)
+	defer cancel()
+
+	embedding, err := embed(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't create embedding of query: %w", &embedderError{err})
+	}
+
+	return embedding, nil
+}
+
+// FindSimilarChunks returns the chunks most similar to the given chunk. The
+// search mode doesn't apply and no file types means all of them.
+func (idx *Index) FindSimilarChunks(ctx context.Context, chunkID string, opts SearchOptions) ([]string, error) {
 	err := idx.ensureInitialized(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	doc, err := idx.collection.GetByID(ctx, chunkID)
+	filter, err := newChunkFilter(opts, opts.FileTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := idx.document(ctx, chunkID)
+	if err != nil {
+		// Split chunks are compared by their first part
+		doc, err = idx.document(ctx, chunkID+parser.PartSeparator+"1")
+	}
 	if err != nil {
 		return nil, fmt.Errorf("chunk not found: %s", chunkID)
 	}
 
-	results, err := idx.collection.QueryEmbedding(ctx, doc.Embedding, 10, nil, nil)
+	if len(doc.Embedding) == 0 {
+		docs := []Document{doc}
+		err = idx.embedDocuments(ctx, docs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to embed chunk: %w", err)
+		}
+		doc = docs[0]
+	}
+
+	offset, limit := opts.window(DefaultSimilarLimit)
+
+	// The chunk itself is usually the best match and gets skipped
+	nResults := offset + limit + 1
+	if diversify(opts.Lambda) {
+		nResults = candidatesPerResult*(offset+limit) + 1
+	}
+	if idx.collection.Count() == 0 {
+		return nil, nil
+	}
+
+	results, err := idx.query(ctx, doc.Embedding, nResults, nil, filter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to perform similarity search: %w", err)
 	}
 
-	return idx.formatSearchResults(ctx, results, 2*minSimilarity, 10, chunkID, nil), nil
+	minSimilarity := cmp.Or(opts.MinScore, 2*embedMinSimilarity(idx.embedder))
+	return idx.formatSearchResults(ctx, results, minSimilarity, offset, limit, opts.Lambda, "", parentID(doc), filter)
 }
 
 func (idx *Index) formatSearchResults(
 	ctx context.Context,
-	results []chromem.Result,
+	results []Result,
 	minSimilarity float32,
-	maxCount int,
+	offset int,
+	limit int,
+	lambda float32,
+	query string,
 	skipID string,
-	typeFilter map[string]bool,
-) []string {
+	filter *chunkFilter,
+) ([]string, error) {
 	sort.Slice(results, func(i, j int) bool {
 		return results[i].Similarity > results[j].Similarity
 	})
 
-	paths := []string{}
+	// Reranking and diversifying need every candidate, not just the page
+	rerank := query != "" && idx.reranker != nil
+	var candidates []rankedDoc
+	seen := map[string]bool{skipID: true}
 	for _, result := range results {
 		if result.ID == skipID {
 			continue
 		}
 
-		if result.Similarity < minSimilarity || len(paths) >= maxCount {
+		if result.Similarity < minSimilarity {
+			break
+		}
+
+		if !rerank && !diversify(lambda) && len(candidates) >= offset+limit {
 			break
 		}
 
-		chunk, err := idx.GetChunk(ctx, result.ID)
+		doc, err := idx.document(ctx, result.ID)
 		if err != nil {
 			continue
 		}
 
-		if typeFilter != nil && !typeFilter[chunk.Type] {
+		if !filter.matches(doc.Metadata) {
 			continue
 		}
 
-		var lines string
-		if chunk.StartLine == chunk.EndLine {
-			lines = fmt.Sprintf("line %d", chunk.StartLine)
+		// Parts of a split chunk collapse into it, ranked by the best one
+		if seen[parentID(doc)] {
+			continue
+		}
+		seen[parentID(doc)] = true
+
+		candidates = append(candidates, rankedDoc{doc: doc, score: result.Similarity})
+	}
+
+	if rerank {
+		reranked, err := idx.rerank(ctx, query, candidates)
+		if err == nil {
+			candidates = reranked
 		} else {
-			lines = fmt.Sprintf("lines %d-%d", chunk.StartLine, chunk.EndLine)
+			// Reranking only improves the order, the retrieval order still works
+			fmt.Fprintf(os.Stderr, "Sourcerer: %v\n", err)
+		}
+	}
+
+	if diversify(lambda) {
+		candidates = maximalMarginalRelevance(candidates, lambda, offset+limit)
+	}
+
+	paths := []string{}
+	candidates = candidates[:min(offset+limit, len(candidates))]
+	for _, candidate := range candidates[min(offset, len(candidates)):] {
+		chunk := chunkFromDocument(candidate.doc)
+		if chunk.Parent != "" {
+			var err error
+			chunk, err = idx.chunk(ctx, chunk.ParentID())
+			if err != nil {
+				continue
+			}
 		}
 
 		paths = append(
 			paths,
-			fmt.Sprintf("%s | %s [%s]", result.ID, chunk.Summary, lines),
+			fmt.Sprintf("%s | %s [%s]", chunk.ID(), chunk.Summary, chunk.Lines()),
 		)
 	}
 
-	return paths
+	return paths, nil
 }
 
 func (idx *Index) GetChunk(ctx context.Context, id string) (*parser.Chunk, error) {
@@ -388,29 +959,141 @@ func (idx *Index) GetChunk(ctx context.Context, id string) (*parser.Chunk, error
 		return nil, err
 	}
 
-	doc, err := idx.collection.GetByID(ctx, id)
+	// Stored chunks of queued files are from an older version of them
+	chunk, queued := idx.queue.chunk(id)
+	if queued {
+		if chunk == nil {
+			return nil, fmt.Errorf("chunk not found: %s", id)
+		}
+
+		return chunk, nil
+	}
+
+	return idx.chunk(ctx, id)
+}
+
+// ChunksWithPath returns the chunks that have the given path within their
+// file across the workspace, e.g. a function's declaration and definition
+func (idx *Index) ChunksWithPath(ctx context.Context, path string) ([]*parser.Chunk, error) {
+	err := idx.ensureInitialized(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("chunk not found: %s", id)
+		return nil, err
+	}
+
+	files := map[string][]*Document{}
+	for _, collection := range []VectorStore{idx.collection, idx.previousStore()} {
+		if collection == nil {
+			continue
+		}
+
+		// Split chunks are stored as parts pointing at the chunk's path
+		var found []*Document
+		for _, where := range []map[string]string{{"path": path}, {"parent": path}} {
+			docs, err := collection.GetWhere(ctx, where)
+			if err != nil {
+				return nil, fmt.Errorf("failed to look up documents in vector db: %w", err)
+			}
+
+			found = append(found, docs...)
+		}
+
+		// Files still in the previous collection haven't been migrated yet
+		migrated := map[string]bool{}
+		for filePath := range files {
+			migrated[filePath] = true
+		}
+
+		for _, doc := range found {
+			filePath := doc.Metadata["file"]
+			if !migrated[filePath] {
+				files[filePath] = append(files[filePath], doc)
+			}
+		}
+	}
+
+	var chunks []*parser.Chunk
+	for filePath, docs := range files {
+		// Stored chunks of queued files are from an older version of them
+		if idx.queue.get(filePath) != nil {
+			continue
+		}
+
+		if len(docs) == 1 && docs[0].Metadata["path"] == path {
+			chunks = append(chunks, chunkFromDocument(*docs[0]))
+		} else {
+			chunks = append(chunks, joinParts(docs))
+		}
 	}
 
+	for _, filePath := range idx.queue.paths() {
+		chunk, _ := idx.queue.chunk(filePath + "::" + path)
+		if chunk != nil {
+			chunks = append(chunks, chunk)
+		}
+	}
+
+	sort.Slice(chunks, func(i, j int) bool {
+		return chunks[i].ID() < chunks[j].ID()
+	})
+
+	return chunks, nil
+}
+
+func chunkFromDocument(doc Document) *parser.Chunk {
 	startLine, _ := strconv.Atoi(doc.Metadata["startLine"])
 	startColumn, _ := strconv.Atoi(doc.Metadata["startColumn"])
 	endLine, _ := strconv.Atoi(doc.Metadata["endLine"])
 	endColumn, _ := strconv.Atoi(doc.Metadata["endColumn"])
 	parsedAt, _ := strconv.ParseInt(doc.Metadata["parsedAt"], 10, 64)
+	codeStart, _ := strconv.Atoi(doc.Metadata["codeStart"])
 
 	return &parser.Chunk{
 		File:        doc.Metadata["file"],
 		Type:        doc.Metadata["type"],
 		Path:        doc.Metadata["path"],
+		Parent:      doc.Metadata["parent"],
+		Kind:        doc.Metadata["kind"],
 		Summary:     doc.Metadata["summary"],
+		Doc:         doc.Metadata["doc"],
 		Source:      doc.Content,
+		CodeStart:   uint(codeStart),
 		StartLine:   uint(startLine),
 		StartColumn: uint(startColumn),
 		EndLine:     uint(endLine),
 		EndColumn:   uint(endColumn),
 		ParsedAt:    parsedAt,
-	}, nil
+		Ranges:      decodeRanges(doc.Metadata["ranges"]),
+	}
+}
+
+// encodeRanges stores the line ranges of a composite chunk as metadata,
+// e.g. "3-5,7-7"
+func encodeRanges(ranges []parser.LineRange) string {
+	encoded := make([]string, len(ranges))
+	for i, r := range ranges {
+		encoded[i] = fmt.Sprintf("%d-%d", r.Start, r.End)
+	}
+
+	return strings.Join(encoded, ",")
+}
+
+func decodeRanges(encoded string) []parser.LineRange {
+	if encoded == "" {
+		return nil
+	}
+
+	var ranges []parser.LineRange
+	for _, part := range strings.Split(encoded, ",") {
+		var r parser.LineRange
+		_, err := fmt.Sscanf(part, "%d-%d", &r.Start, &r.End)
+		if err != nil {
+			return nil
+		}
+
+		ranges = append(ranges, r)
+	}
+
+	return ranges
 }
 
 func (idx *Index) CleanupDeletedFiles(ctx context.Context) {
@@ -424,9 +1107,9 @@ func (idx *Index) CleanupDeletedFiles(ctx context.Context) {
 
 	for filePath := range idx.cache {
 		go func(path string) {
-			_, err := os.Stat(filePath)
+			_, err := os.Stat(filepath.Join(idx.workspaceRoot, path))
 			if os.IsNotExist(err) {
-				idx.Remove(ctx, filePath)
+				idx.Remove(ctx, path)
 			}
 		}(filePath)
 	}
@@ -0,0 +1,656 @@
+package index_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/st3v3nmw/sourcerer-mcp/internal/index"
+	"github.com/st3v3nmw/sourcerer-mcp/internal/parser"
+	"github.com/stretchr/testify/suite"
+)
+
+type IndexTestSuite struct {
+	suite.Suite
+	ctx           context.Context
+	workspaceRoot string
+	store         string
+	idx           *index.Index
+}
+
+func (s *IndexTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.workspaceRoot = s.T().TempDir()
+
+	var err error
+	s.idx, err = openIndex(s.T(), s.ctx, s.workspaceRoot, index.Config{
+		Embedder: index.EmbedderConfig{Provider: "test"},
+		Store:    s.store,
+	})
+	s.Require().NoError(err)
+
+	s.Require().NoError(s.idx.Index(s.ctx, testFile()))
+}
+
+// openIndex creates an index that's closed when the test ends
+func openIndex(t *testing.T, ctx context.Context, workspaceRoot string, cfg index.Config) (*index.Index, error) {
+	idx, err := index.NewWithConfig(ctx, workspaceRoot, cfg)
+	if err == nil {
+		t.Cleanup(func() { idx.Close() })
+	}
+
+	return idx, err
+}
+
+func testFile() *parser.File {
+	chunks := []*parser.Chunk{
+		{
+			Path:      "ParseConfig",
+			Type:      "src",
+			Summary:   "func ParseConfig(path string) (*Config, error) {",
+			Source:    "func ParseConfig(path string) (*Config, error) {\n\treturn readConfig(path)\n}",
+			StartLine: 1,
+			EndLine:   3,
+		},
+		{
+			Path:      "FlushPending",
+			Type:      "src",
+			Summary:   "func FlushPending() {",
+			Source:    "func FlushPending() {\n\twatcher flush pending changes now\n}",
+			StartLine: 5,
+			EndLine:   7,
+		},
+		{
+			Path:      "Usage",
+			Type:      "docs",
+			Summary:   "## Usage",
+			Source:    "## Usage\n\nRun the server and point your agent at it.",
+			StartLine: 9,
+			EndLine:   11,
+		},
+	}
+
+	for _, chunk := range chunks {
+		chunk.File = "pkg/config.go"
+	}
+
+	return &parser.File{Path: "pkg/config.go", Chunks: chunks}
+}
+
+func (s *IndexTestSuite) TestSearchFindsMatchingChunk() {
+	results, err := s.idx.Search(s.ctx, "watcher flush pending changes", index.SearchOptions{FileTypes: []string{"src"}})
+	s.Require().NoError(err)
+	s.Require().NotEmpty(results)
+	s.Contains(results[0], "pkg/config.go::FlushPending")
+}
+
+func (s *IndexTestSuite) TestSearchRespectsFileTypes() {
+	results, err := s.idx.Search(s.ctx, "run the server usage", index.SearchOptions{FileTypes: []string{"docs"}})
+	s.Require().NoError(err)
+
+	for _, result := range results {
+		s.Contains(result, "pkg/config.go::Usage")
+	}
+}
+
+func (s *IndexTestSuite) TestLexicalModeMatchesIdentifiers() {
+	results, err := s.idx.Search(s.ctx, "ParseConfig", index.SearchOptions{
+		FileTypes: []string{"src"},
+		Mode:      index.SearchLexical,
+	})
+	s.Require().NoError(err)
+	s.Require().NotEmpty(results)
+	s.Contains(results[0], "pkg/config.go::ParseConfig")
+}
+
+func (s *IndexTestSuite) TestHybridModeCombinesRetrievers() {
+	results, err := s.idx.Search(s.ctx, "readConfig", index.SearchOptions{FileTypes: []string{"src"}})
+	s.Require().NoError(err)
+	s.Require().NotEmpty(results)
+	s.Contains(results[0], "pkg/config.go::ParseConfig")
+}
+
+func (s *IndexTestSuite) TestSearchFallsBackToKeywordsWhenEmbedderIsDown() {
+	idx, err := openIndex(s.T(), s.ctx, s.T().TempDir(), index.Config{
+		Embedder: index.EmbedderConfig{Provider: "flaky"},
+		Store:    s.store,
+	})
+	s.Require().NoError(err)
+	s.Require().NoError(idx.Index(s.ctx, testFile()))
+
+	embedderDown.Store(true)
+	defer embedderDown.Store(false)
+
+	for _, mode := range []index.SearchMode{index.SearchHybrid, index.SearchSemantic} {
+		results, err := idx.Search(s.ctx, "ParseConfig", index.SearchOptions{FileTypes: []string{"src"}, Mode: mode})
+		s.ErrorIs(err, index.ErrDegraded)
+		s.Require().NotEmpty(results)
+		s.Contains(results[0], "pkg/config.go::ParseConfig")
+	}
+
+	_, err = idx.Search(s.ctx, "", index.SearchOptions{})
+	s.Error(err)
+	s.NotErrorIs(err, index.ErrDegraded)
+}
+
+func (s *IndexTestSuite) TestRemoveDropsKeywordMatches() {
+	s.Require().NoError(s.idx.Remove(s.ctx, "pkg/config.go"))
+
+	results, err := s.idx.Search(s.ctx, "ParseConfig", index.SearchOptions{
+		FileTypes: []string{"src"},
+		Mode:      index.SearchLexical,
+	})
+	s.Require().NoError(err)
+	s.Empty(results)
+}
+
+func (s *IndexTestSuite) TestParseSearchMode() {
+	mode, err := index.ParseSearchMode("")
+	s.Require().NoError(err)
+	s.Equal(index.SearchHybrid, mode)
+
+	_, err = index.ParseSearchMode("fuzzy")
+	s.Error(err)
+}
+
+func (s *IndexTestSuite) TestGetChunk() {
+	chunk, err := s.idx.GetChunk(s.ctx, "pkg/config.go::ParseConfig")
+	s.Require().NoError(err)
+	s.Equal("pkg/config.go", chunk.File)
+	s.Equal(uint(1), chunk.StartLine)
+	s.Equal(uint(3), chunk.EndLine)
+	s.Contains(chunk.Source, "readConfig")
+}
+
+func (s *IndexTestSuite) TestRemove() {
+	s.Require().NoError(s.idx.Remove(s.ctx, "pkg/config.go"))
+
+	_, err := s.idx.GetChunk(s.ctx, "pkg/config.go::ParseConfig")
+	s.Error(err)
+}
+
+func (s *IndexTestSuite) TestChunksWithPath() {
+	header := &parser.File{Path: "geo/shapes.hpp", Chunks: []*parser.Chunk{{
+		File:      "geo/shapes.hpp",
+		Path:      "geo::Circle::area",
+		Type:      "src",
+		Summary:   "double area() const override;",
+		Source:    "double area() const override;",
+		StartLine: 25,
+		EndLine:   25,
+	}}}
+	s.Require().NoError(s.idx.Index(s.ctx, header))
+
+	// The definition is split into parts
+	impl := &parser.File{Path: "geo/shapes.cpp"}
+	for i, source := range []string{
+		"double Circle::area() const {\n\tcompute circle area",
+		"\tcompute circle area\n\treturn area\n}",
+	} {
+		impl.Chunks = append(impl.Chunks, &parser.Chunk{
+			File:      impl.Path,
+			Path:      fmt.Sprintf("geo::Circle::area#part%d", i+1),
+			Parent:    "geo::Circle::area",
+			Type:      "src",
+			Summary:   "double Circle::area() const {",
+			Source:    source,
+			StartLine: uint(7 + 2*i),
+			EndLine:   uint(9 + 2*i),
+		})
+	}
+	s.Require().NoError(s.idx.Index(s.ctx, impl))
+
+	chunks, err := s.idx.ChunksWithPath(s.ctx, "geo::Circle::area")
+	s.Require().NoError(err)
+	s.Require().Len(chunks, 2)
+	s.Equal("geo/shapes.cpp::geo::Circle::area", chunks[0].ID())
+	s.Equal("lines 7-11", chunks[0].Lines())
+	s.Equal("geo/shapes.hpp::geo::Circle::area", chunks[1].ID())
+
+	chunks, err = s.idx.ChunksWithPath(s.ctx, "geo::Circle::radius")
+	s.Require().NoError(err)
+	s.Empty(chunks)
+}
+
+func (s *IndexTestSuite) TestReindexUnchangedFileRecordsNoChanges() {
+	seq := s.idx.LatestChange()
+
+	s.Require().NoError(s.idx.Index(s.ctx, testFile()))
+	s.Empty(s.idx.ChangesSince(seq))
+}
+
+func (s *IndexTestSuite) TestReindexKeepsUnchangedChunks() {
+	// Editing one chunk changes the file's hash, but not the other chunks
+	file := s.writeFile("pkg/config.go", "package pkg // edited")
+	for _, chunk := range file.Chunks {
+		chunk.ParsedAt = 2
+	}
+	file.Chunks[0].Source += "\n// validated"
+	s.Require().NoError(s.idx.Index(s.ctx, file))
+
+	chunk, err := s.idx.GetChunk(s.ctx, "pkg/config.go::ParseConfig")
+	s.Require().NoError(err)
+	s.Equal(int64(2), chunk.ParsedAt)
+
+	chunk, err = s.idx.GetChunk(s.ctx, "pkg/config.go::FlushPending")
+	s.Require().NoError(err)
+	s.Zero(chunk.ParsedAt)
+	s.False(s.idx.IsStale(s.ctx, "pkg/config.go"))
+}
+
+func (s *IndexTestSuite) TestReindexRecordsChunkChanges() {
+	seq := s.idx.LatestChange()
+
+	file := testFile()
+	file.Chunks[0].Source += "\n// validated"
+	file.Chunks[1].StartLine, file.Chunks[1].EndLine = 6, 8
+	file.Chunks = file.Chunks[:2]
+	file.Chunks = append(file.Chunks, &parser.Chunk{
+		File:      file.Path,
+		Path:      "Validate",
+		Type:      "src",
+		Source:    "func Validate() error {\n\treturn nil\n}",
+		StartLine: 10,
+		EndLine:   12,
+	})
+	s.Require().NoError(s.idx.Index(s.ctx, file))
+
+	kinds := map[string]index.ChangeKind{}
+	for _, change := range s.idx.ChangesSince(seq) {
+		kinds[change.ID] = change.Kind
+	}
+
+	s.Equal(map[string]index.ChangeKind{
+		"pkg/config.go::ParseConfig":  index.ChunkModified,
+		"pkg/config.go::FlushPending": index.ChunkMoved,
+		"pkg/config.go::Usage":        index.ChunkRemoved,
+		"pkg/config.go::Validate":     index.ChunkAdded,
+	}, kinds)
+
+	chunk, err := s.idx.GetChunk(s.ctx, "pkg/config.go::FlushPending")
+	s.Require().NoError(err)
+	s.Equal(uint(6), chunk.StartLine)
+
+	_, err = s.idx.GetChunk(s.ctx, "pkg/config.go::Usage")
+	s.Error(err)
+
+	results, err := s.idx.Search(s.ctx, "Usage", index.SearchOptions{
+		FileTypes: []string{"docs"},
+		Mode:      index.SearchLexical,
+	})
+	s.Require().NoError(err)
+	s.Empty(results)
+}
+
+func (s *IndexTestSuite) TestChangesSinceReportsNetChange() {
+	seq := s.idx.LatestChange()
+
+	file := testFile()
+	file.Chunks = append(file.Chunks, &parser.Chunk{
+		File:   file.Path,
+		Path:   "Validate",
+		Type:   "src",
+		Source: "func Validate() error {\n\treturn nil\n}",
+	})
+	s.Require().NoError(s.idx.Index(s.ctx, file))
+	s.Require().NoError(s.idx.Index(s.ctx, testFile()))
+	s.Require().NoError(s.idx.Remove(s.ctx, "pkg/config.go"))
+	s.Require().NoError(s.idx.Index(s.ctx, testFile()))
+
+	changes := s.idx.ChangesSince(seq)
+	s.Require().Len(changes, 3)
+	for _, change := range changes {
+		s.Equal(index.ChunkModified, change.Kind, change.ID)
+	}
+}
+
+func (s *IndexTestSuite) TestSearchPagesWithLimitAndOffset() {
+	file := &parser.File{Path: "pkg/handlers.go"}
+	for i := range 8 {
+		file.Chunks = append(file.Chunks, &parser.Chunk{
+			File:      file.Path,
+			Path:      fmt.Sprintf("Handler%d", i),
+			Type:      "src",
+			Source:    fmt.Sprintf("func Handler%d() { handle request %d }", i, i),
+			StartLine: uint(i + 1),
+			EndLine:   uint(i + 1),
+		})
+	}
+	s.Require().NoError(s.idx.Index(s.ctx, file))
+
+	opts := index.SearchOptions{FileTypes: []string{"src"}, Mode: index.SearchLexical, Limit: 3}
+	first, err := s.idx.Search(s.ctx, "handle request", opts)
+	s.Require().NoError(err)
+	s.Len(first, 3)
+
+	opts.Offset = 3
+	second, err := s.idx.Search(s.ctx, "handle request", opts)
+	s.Require().NoError(err)
+	s.Len(second, 3)
+
+	for _, result := range second {
+		s.NotContains(first, result)
+	}
+
+	opts.Offset, opts.Limit = 0, 0
+	defaults, err := s.idx.Search(s.ctx, "handle request", opts)
+	s.Require().NoError(err)
+	s.Len(defaults, index.DefaultSearchLimit)
+}
+
+func (s *IndexTestSuite) TestSearchMinScore() {
+	results, err := s.idx.Search(s.ctx, "watcher flush pending changes", index.SearchOptions{
+		FileTypes: []string{"src"},
+		Mode:      index.SearchSemantic,
+		MinScore:  0.99,
+	})
+	s.Require().NoError(err)
+	s.Empty(results)
+}
+
+func (s *IndexTestSuite) TestFindSimilarChunksLimit() {
+	results, err := s.idx.FindSimilarChunks(s.ctx, "pkg/config.go::ParseConfig", index.SearchOptions{
+		Limit:    1,
+		MinScore: 0.01,
+	})
+	s.Require().NoError(err)
+	s.Len(results, 1)
+	s.NotContains(results[0], "pkg/config.go::ParseConfig |")
+}
+
+func (s *IndexTestSuite) indexFilterFixtures() {
+	files := []struct{ path, language string }{
+		{"internal/index/search.go", "go"},
+		{"internal/index/testdata/search.py", "python"},
+		{"internal/mcp/server.go", "go"},
+		{"native/search.c", "c"},
+		{"native/search.cpp", "cpp"},
+		{"native/search.h", "cpp"},
+	}
+
+	for _, f := range files {
+		s.Require().NoError(s.idx.Index(s.ctx, &parser.File{
+			Path:     f.path,
+			Language: f.language,
+			Chunks: []*parser.Chunk{{
+				File:   f.path,
+				Path:   "Search",
+				Type:   "src",
+				Source: "func Search(query string) { rank results }",
+			}},
+		}))
+	}
+}
+
+func (s *IndexTestSuite) searchIDs(opts index.SearchOptions) []string {
+	opts.FileTypes = []string{"src"}
+
+	var ids []string
+	for _, mode := range []index.SearchMode{index.SearchSemantic, index.SearchLexical} {
+		opts.Mode = mode
+		results, err := s.idx.Search(s.ctx, "search rank results", opts)
+		s.Require().NoError(err)
+
+		for _, result := range results {
+			ids = append(ids, strings.Split(result, "::")[0])
+		}
+	}
+
+	slices.Sort(ids)
+	return slices.Compact(ids)
+}
+
+func (s *IndexTestSuite) TestSearchFilters() {
+	s.indexFilterFixtures()
+
+	s.Equal(
+		[]string{"internal/index/search.go", "internal/index/testdata/search.py"},
+		s.searchIDs(index.SearchOptions{Directory: "internal/index/"}),
+	)
+	s.Equal(
+		[]string{"internal/index/search.go"},
+		s.searchIDs(index.SearchOptions{Directory: "internal/index", Exclude: []string{"**/testdata/**"}}),
+	)
+	s.Equal(
+		[]string{"internal/index/search.go", "internal/mcp/server.go"},
+		s.searchIDs(index.SearchOptions{Include: []string{"internal/**/*.go"}}),
+	)
+	s.Equal(
+		[]string{"internal/index/testdata/search.py"},
+		s.searchIDs(index.SearchOptions{Languages: []string{"python"}}),
+	)
+	// Headers are parsed as C++, but could be C
+	s.Equal(
+		[]string{"native/search.c", "native/search.h"},
+		s.searchIDs(index.SearchOptions{Languages: []string{"c"}}),
+	)
+	s.Equal(
+		[]string{"native/search.cpp", "native/search.h"},
+		s.searchIDs(index.SearchOptions{Languages: []string{"cpp"}}),
+	)
+	s.Empty(s.searchIDs(index.SearchOptions{Directory: "internal/ind"}))
+}
+
+func (s *IndexTestSuite) TestSearchRejectsInvalidGlob() {
+	_, err := s.idx.Search(s.ctx, "search", index.SearchOptions{Include: []string{"internal/[*.go"}})
+	s.Error(err)
+}
+
+func (s *IndexTestSuite) TestSearchDiversifiesNearDuplicates() {
+	file := &parser.File{Path: "pkg/loader.go"}
+	for i, path := range []string{"Load", "Load-2", "Load-3", "Reload"} {
+		source := "load config settings from disk"
+		if path == "Reload" {
+			source = "reload config watcher"
+		}
+
+		file.Chunks = append(file.Chunks, &parser.Chunk{
+			File:      file.Path,
+			Path:      path,
+			Type:      "src",
+			Source:    source,
+			StartLine: uint(i + 1),
+			EndLine:   uint(i + 1),
+		})
+	}
+	s.Require().NoError(s.idx.Index(s.ctx, file))
+
+	opts := index.SearchOptions{
+		FileTypes: []string{"src"},
+		Mode:      index.SearchSemantic,
+		Limit:     2,
+	}
+	results, err := s.idx.Search(s.ctx, "load config settings reload", opts)
+	s.Require().NoError(err)
+	s.Require().Len(results, 2)
+	s.NotContains(strings.Join(results, "\n"), "pkg/loader.go::Reload")
+
+	opts.Lambda = 0.5
+	results, err = s.idx.Search(s.ctx, "load config settings reload", opts)
+	s.Require().NoError(err)
+	s.Require().Len(results, 2)
+	s.Contains(results[1], "pkg/loader.go::Reload")
+}
+
+func (s *IndexTestSuite) TestSplitChunkCollapsesToParent() {
+	file := &parser.File{Path: "pkg/report.go"}
+	for i, source := range []string{
+		"func BuildReport() {\n\tcollect report rows\n\tformat report rows",
+		"\tformat report rows\n\twrite report rows\n}",
+	} {
+		file.Chunks = append(file.Chunks, &parser.Chunk{
+			File:      file.Path,
+			Path:      fmt.Sprintf("BuildReport#part%d", i+1),
+			Parent:    "BuildReport",
+			Type:      "src",
+			Summary:   "func BuildReport() {",
+			Source:    source,
+			StartLine: uint(10 + 2*i),
+			EndLine:   uint(12 + 2*i),
+		})
+	}
+	s.Require().NoError(s.idx.Index(s.ctx, file))
+
+	results, err := s.idx.Search(s.ctx, "report rows", index.SearchOptions{FileTypes: []string{"src"}})
+	s.Require().NoError(err)
+	s.Require().NotEmpty(results)
+	s.Equal("pkg/report.go::BuildReport | func BuildReport() { [lines 10-14]", results[0])
+	for _, result := range results[1:] {
+		s.NotContains(result, "BuildReport")
+	}
+
+	chunk, err := s.idx.GetChunk(s.ctx, "pkg/report.go::BuildReport")
+	s.Require().NoError(err)
+	s.Equal("func BuildReport() {\n\tcollect report rows\n\tformat report rows\n\twrite report rows\n}", chunk.Source)
+	s.Equal(uint(10), chunk.StartLine)
+	s.Equal(uint(14), chunk.EndLine)
+
+	part, err := s.idx.GetChunk(s.ctx, "pkg/report.go::BuildReport#part2")
+	s.Require().NoError(err)
+	s.Equal("BuildReport", part.Parent)
+
+	similar, err := s.idx.FindSimilarChunks(s.ctx, "pkg/report.go::BuildReport", index.SearchOptions{})
+	s.Require().NoError(err)
+	for _, result := range similar {
+		s.NotContains(result, "BuildReport")
+	}
+}
+
+func (s *IndexTestSuite) TestSplitLongLineCollapsesToParent() {
+	file := &parser.File{Path: "web/table.js"}
+	for i, part := range []struct {
+		source      string
+		startLine   uint
+		startColumn uint
+		endColumn   uint
+	}{
+		{"// lookup table\nconst table = [1,2,3,", 1, 1, 22},
+		{"3,4,5,6,", 2, 20, 28},
+		{"6,7];", 2, 26, 31},
+	} {
+		file.Chunks = append(file.Chunks, &parser.Chunk{
+			File:        file.Path,
+			Path:        fmt.Sprintf("table#part%d", i+1),
+			Parent:      "table",
+			Type:        "src",
+			Summary:     "const table = [",
+			Source:      part.source,
+			StartLine:   part.startLine,
+			StartColumn: part.startColumn,
+			EndLine:     2,
+			EndColumn:   part.endColumn,
+		})
+	}
+	s.Require().NoError(s.idx.Index(s.ctx, file))
+
+	chunk, err := s.idx.GetChunk(s.ctx, "web/table.js::table")
+	s.Require().NoError(err)
+	s.Equal("// lookup table\nconst table = [1,2,3,4,5,6,7];", chunk.Source)
+}
+
+func (s *IndexTestSuite) TestCompositeChunkKeepsLineRanges() {
+	file := &parser.File{Path: "pkg/flags.go", Chunks: []*parser.Chunk{{
+		File:      "pkg/flags.go",
+		Path:      "0123456789abcdef",
+		Kind:      "composite",
+		Type:      "src",
+		Summary:   "var verbose = flag.Bool(\"verbose\")",
+		Source:    "var verbose = flag.Bool(\"verbose\")\n\nvar quiet = flag.Bool(\"quiet\")",
+		StartLine: 3,
+		EndLine:   5,
+		Ranges:    []parser.LineRange{{Start: 3, End: 3}, {Start: 5, End: 5}},
+	}}}
+	s.Require().NoError(s.idx.Index(s.ctx, file))
+
+	chunk, err := s.idx.GetChunk(s.ctx, "pkg/flags.go::0123456789abcdef")
+	s.Require().NoError(err)
+	s.Equal(file.Chunks[0].Ranges, chunk.Ranges)
+
+	results, err := s.idx.Search(s.ctx, "verbose quiet flag", index.SearchOptions{Mode: index.SearchLexical})
+	s.Require().NoError(err)
+	s.Require().NotEmpty(results)
+	s.Equal(`pkg/flags.go::0123456789abcdef | var verbose = flag.Bool("verbose") [lines 3, 5]`, results[0])
+}
+
+func (s *IndexTestSuite) TestFailedInitializationClosesVectorDB() {
+	if _, err := os.Stat("/proc/self/fd"); err != nil {
+		s.T().Skip("open files can't be listed")
+	}
+
+	workspaceRoot := s.T().TempDir()
+	manifest := filepath.Join(workspaceRoot, ".sourcerer", "manifest.json")
+	s.Require().NoError(os.MkdirAll(filepath.Dir(manifest), 0o755))
+	s.Require().NoError(os.WriteFile(manifest, []byte("{"), 0o644))
+
+	_, err := openIndex(s.T(), s.ctx, workspaceRoot, index.Config{
+		Embedder: index.EmbedderConfig{Provider: "test"},
+		Store:    "sqlite",
+	})
+	s.Require().ErrorContains(err, "manifest")
+
+	fds, err := os.ReadDir("/proc/self/fd")
+	s.Require().NoError(err)
+	for _, fd := range fds {
+		target, _ := os.Readlink(filepath.Join("/proc/self/fd", fd.Name()))
+		s.NotContains(target, workspaceRoot)
+	}
+}
+
+func (s *IndexTestSuite) writeFile(path, source string) *parser.File {
+	fullPath := filepath.Join(s.workspaceRoot, path)
+	s.Require().NoError(os.MkdirAll(filepath.Dir(fullPath), 0o755))
+	s.Require().NoError(os.WriteFile(fullPath, []byte(source), 0o644))
+
+	file := testFile()
+	file.Source = []byte(source)
+
+	return file
+}
+
+func (s *IndexTestSuite) TestIsStaleComparesContent() {
+	file := s.writeFile("pkg/config.go", "package pkg")
+	s.Require().NoError(s.idx.Index(s.ctx, file))
+	s.False(s.idx.IsStale(s.ctx, "pkg/config.go"))
+
+	// Checkouts and copies touch files without changing them
+	future := time.Now().Add(time.Hour)
+	s.Require().NoError(os.Chtimes(filepath.Join(s.workspaceRoot, "pkg/config.go"), future, future))
+	s.False(s.idx.IsStale(s.ctx, "pkg/config.go"))
+
+	s.writeFile("pkg/config.go", "package cfg")
+	s.True(s.idx.IsStale(s.ctx, "pkg/config.go"))
+
+	s.writeFile("pkg/config.go", "package config")
+	s.True(s.idx.IsStale(s.ctx, "pkg/config.go"))
+}
+
+func (s *IndexTestSuite) TestIsStaleSurvivesRestart() {
+	file := s.writeFile("pkg/config.go", "package pkg")
+	s.Require().NoError(s.idx.Index(s.ctx, file))
+	s.Require().NoError(s.idx.Close())
+
+	reopened, err := openIndex(s.T(), s.ctx, s.workspaceRoot, index.Config{
+		Embedder: index.EmbedderConfig{Provider: "test"},
+		Store:    s.store,
+	})
+	s.Require().NoError(err)
+	s.False(reopened.IsStale(s.ctx, "pkg/config.go"))
+}
+
+func (s *IndexTestSuite) TestIsStaleForUnindexedFile() {
+	s.writeFile("pkg/other.go", "package pkg")
+	s.True(s.idx.IsStale(s.ctx, "pkg/other.go"))
+}
+
+func TestIndexTestSuite(t *testing.T) {
+	suite.Run(t, new(IndexTestSuite))
+}
+
+func TestIndexTestSuiteSQLite(t *testing.T) {
+	suite.Run(t, &IndexTestSuite{store: "sqlite"})
+}
@@ -0,0 +1,182 @@
+package index
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// keywordDoc is the per-document state kept by the keyword index
+type keywordDoc struct {
+	length   int
+	terms    map[string]int // term -> frequency in the document
+	metadata map[string]string
+}
+
+// keywordHit is a document matched by a keyword search
+type keywordHit struct {
+	id    string
+	score float32
+}
+
+// keywordIndex is an inverted index over chunk text and metadata that ranks
+// documents with BM25. It complements vector search for queries containing
+// identifiers or exact strings that embeddings tend to blur.
+type keywordIndex struct {
+	mu       sync.RWMutex
+	postings map[string]map[string]bool // term -> docIDs containing it
+	docs     map[string]*keywordDoc     // docID -> document
+	byFile   map[string][]string        // file -> docIDs
+	totalLen int
+}
+
+func newKeywordIndex() *keywordIndex {
+	return &keywordIndex{
+		postings: map[string]map[string]bool{},
+		docs:     map[string]*keywordDoc{},
+		byFile:   map[string][]string{},
+	}
+}
+
+// keywordText is the text indexed for a chunk: its content plus the chunk and
+// file paths, so searching for a file or symbol name finds it
+func keywordText(content string, metadata map[string]string) string {
+	return content + "\n" + metadata["path"] + "\n" + metadata["file"]
+}
+
+func (k *keywordIndex) add(id, content string, metadata map[string]string) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.removeLocked(id)
+
+	doc := &keywordDoc{
+		terms:    map[string]int{},
+		metadata: metadata,
+	}
+	for _, term := range terms(keywordText(content, metadata)) {
+		doc.terms[term]++
+		doc.length++
+	}
+
+	for term := range doc.terms {
+		if k.postings[term] == nil {
+			k.postings[term] = map[string]bool{}
+		}
+		k.postings[term][id] = true
+	}
+
+	k.docs[id] = doc
+	k.totalLen += doc.length
+
+	file := metadata["file"]
+	k.byFile[file] = append(k.byFile[file], id)
+}
+
+func (k *keywordIndex) remove(id string) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.removeLocked(id)
+}
+
+func (k *keywordIndex) removeFile(file string) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	ids := k.byFile[file]
+	delete(k.byFile, file)
+
+	for _, id := range ids {
+		k.removeLocked(id)
+	}
+}
+
+func (k *keywordIndex) removeLocked(id string) {
+	doc, exists := k.docs[id]
+	if !exists {
+		return
+	}
+
+	for term := range doc.terms {
+		delete(k.postings[term], id)
+		if len(k.postings[term]) == 0 {
+			delete(k.postings, term)
+		}
+	}
+
+	file := doc.metadata["file"]
+	ids := k.byFile[file]
+	for i, docID := range ids {
+		if docID == id {
+			k.byFile[file] = append(ids[:i], ids[i+1:]...)
+			break
+		}
+	}
+
+	delete(k.docs, id)
+	k.totalLen -= doc.length
+}
+
+// search returns up to n documents whose metadata passes match, ranked by BM25
+func (k *keywordIndex) search(query string, n int, match func(metadata map[string]string) bool) []keywordHit {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	if len(k.docs) == 0 || n <= 0 {
+		return nil
+	}
+
+	nDocs := float64(len(k.docs))
+	avgLen := float64(k.totalLen) / nDocs
+
+	queryTerms := map[string]bool{}
+	for _, term := range terms(query) {
+		queryTerms[term] = true
+	}
+
+	scores := map[string]float64{}
+	for term := range queryTerms {
+		postings := k.postings[term]
+		if len(postings) == 0 {
+			continue
+		}
+
+		df := float64(len(postings))
+		idf := math.Log(1 + (nDocs-df+0.5)/(df+0.5))
+
+		for id := range postings {
+			doc := k.docs[id]
+			if !match(doc.metadata) {
+				continue
+			}
+
+			tf := float64(doc.terms[term])
+			norm := tf + bm25K1*(1-bm25B+bm25B*float64(doc.length)/avgLen)
+			scores[id] += idf * tf * (bm25K1 + 1) / norm
+		}
+	}
+
+	hits := make([]keywordHit, 0, len(scores))
+	for id, score := range scores {
+		hits = append(hits, keywordHit{id: id, score: float32(score)})
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].score != hits[j].score {
+			return hits[i].score > hits[j].score
+		}
+		return hits[i].id < hits[j].id
+	})
+
+	if len(hits) > n {
+		hits = hits[:n]
+	}
+
+	return hits
+}
@@ -0,0 +1,158 @@
+package index
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+
+	"github.com/cespare/xxhash"
+)
+
+const (
+	lexicalDims          = 1024
+	lexicalBigramWeight  = 0.5
+	lexicalTrigramWeight = 0.25
+	// Sparse hashed vectors have much lower cosine similarities than dense
+	// model embeddings, so the usual relevance cut-off would drop everything
+	lexicalMinSimilarity = 0.05
+)
+
+// LexicalEmbedder is a fully offline embedder built from hashed identifier,
+// subword and character n-gram features. Documents are embedded with
+// sublinear term frequencies and queries are weighted by inverse document
+// frequencies learned from the indexed corpus, so their dot product behaves
+// like TF-IDF and stays correct as the corpus changes.
+type LexicalEmbedder struct {
+	dims int
+
+	mu    sync.RWMutex
+	df    map[uint64]int // feature -> number of documents containing it
+	nDocs int
+}
+
+func NewLexicalEmbedder(dims int) *LexicalEmbedder {
+	return &LexicalEmbedder{
+		dims: dims,
+		df:   map[uint64]int{},
+	}
+}
+
+func (e *LexicalEmbedder) Provider() string {
+	return "lexical"
+}
+
+func (e *LexicalEmbedder) Model() string {
+	return fmt.Sprintf("hashed-ngrams-%d", e.dims)
+}
+
+func (e *LexicalEmbedder) MinSimilarity() float32 {
+	return lexicalMinSimilarity
+}
+
+// Embed returns the document vector for text
+func (e *LexicalEmbedder) Embed(_ context.Context, text string) ([]float32, error) {
+	return e.vectorize(lexicalFeatures(text), nil), nil
+}
+
+// EmbedQuery returns the query vector for text, weighting each feature by
+// how rare it is in the indexed corpus
+func (e *LexicalEmbedder) EmbedQuery(_ context.Context, text string) ([]float32, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return e.vectorize(lexicalFeatures(text), e.idf), nil
+}
+
+// observe adds (delta > 0) or removes (delta < 0) a document from the corpus statistics
+func (e *LexicalEmbedder) observe(text string, delta int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for feature := range lexicalFeatures(text) {
+		e.df[feature] += delta
+		if e.df[feature] <= 0 {
+			delete(e.df, feature)
+		}
+	}
+
+	e.nDocs = max(e.nDocs+delta, 0)
+}
+
+// idf is the BM25 inverse document frequency of a feature, which stays
+// positive even for features found in most documents
+func (e *LexicalEmbedder) idf(feature uint64) float32 {
+	n := float64(e.nDocs)
+	df := float64(e.df[feature])
+
+	return float32(math.Log(1 + (n-df+0.5)/(df+0.5)))
+}
+
+// vectorize folds hashed features into a fixed-size vector, using the top
+// hash bit as the sign so colliding features tend to cancel out
+func (e *LexicalEmbedder) vectorize(features map[uint64]float32, weight func(uint64) float32) []float32 {
+	vector := make([]float32, e.dims)
+	for feature, count := range features {
+		value := float32(math.Log1p(float64(count)))
+		if weight != nil {
+			value *= weight(feature)
+		}
+
+		if feature>>63 == 1 {
+			value = -value
+		}
+
+		vector[feature%uint64(e.dims)] += value
+	}
+
+	// Normalizing an all-zero vector would produce NaNs
+	if isZero(vector) {
+		vector[0] = 1
+	}
+
+	return vector
+}
+
+// lexicalFeatures counts the hashed features of text: subwords and whole
+// identifiers, adjacent subword pairs and character trigrams of subwords
+func lexicalFeatures(text string) map[uint64]float32 {
+	features := map[uint64]float32{}
+	add := func(kind, feature string, weight float32) {
+		features[xxhash.Sum64String(kind+feature)] += weight
+	}
+
+	var prev string
+	for _, identifier := range identifiers(text) {
+		words := splitIdentifier(identifier)
+		if len(words) > 1 {
+			add("w:", strings.Join(words, ""), 1)
+		}
+
+		for _, word := range words {
+			add("w:", word, 1)
+
+			if prev != "" {
+				add("b:", prev+" "+word, lexicalBigramWeight)
+			}
+			prev = word
+
+			runes := []rune(word)
+			for i := 0; len(runes) > 3 && i+3 <= len(runes); i++ {
+				add("c:", string(runes[i:i+3]), lexicalTrigramWeight)
+			}
+		}
+	}
+
+	return features
+}
+
+func isZero(vector []float32) bool {
+	for _, value := range vector {
+		if value != 0 {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,116 @@
+package index_test
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/st3v3nmw/sourcerer-mcp/internal/index"
+	"github.com/st3v3nmw/sourcerer-mcp/internal/parser"
+	"github.com/stretchr/testify/suite"
+)
+
+type LexicalEmbedderTestSuite struct {
+	suite.Suite
+	ctx context.Context
+}
+
+func (s *LexicalEmbedderTestSuite) SetupTest() {
+	s.ctx = context.Background()
+}
+
+func (s *LexicalEmbedderTestSuite) similarity(a, b []float32) float64 {
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i] * b[i])
+		normA += float64(a[i] * a[i])
+		normB += float64(b[i] * b[i])
+	}
+
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+func (s *LexicalEmbedderTestSuite) TestSubwordsMatchIdentifiers() {
+	embedder := index.NewLexicalEmbedder(1024)
+
+	query, err := embedder.EmbedQuery(s.ctx, "flush pending changes")
+	s.Require().NoError(err)
+
+	match, err := embedder.Embed(s.ctx, "func (w *Watcher) FlushPendingChanges() { w.timer.Reset(0) }")
+	s.Require().NoError(err)
+
+	other, err := embedder.Embed(s.ctx, "func ParseConfig(path string) (*Config, error) { return nil, nil }")
+	s.Require().NoError(err)
+
+	s.Greater(s.similarity(query, match), s.similarity(query, other))
+}
+
+func (s *LexicalEmbedderTestSuite) TestEmptyTextIsEmbeddable() {
+	embedder := index.NewLexicalEmbedder(64)
+
+	vector, err := embedder.Embed(s.ctx, "{}();")
+	s.Require().NoError(err)
+	s.Len(vector, 64)
+	s.NotZero(s.similarity(vector, vector))
+}
+
+func (s *LexicalEmbedderTestSuite) TestSearchWithoutExternalServices() {
+	idx, err := openIndex(s.T(), s.ctx, s.T().TempDir(), index.Config{
+		Embedder: index.EmbedderConfig{Provider: "lexical"},
+	})
+	s.Require().NoError(err)
+
+	file := &parser.File{Path: "watcher.go"}
+	for _, chunk := range []*parser.Chunk{
+		{Path: "Watcher::FlushPending", Source: "func (w *Watcher) FlushPending() {\n\tw.debounceTimer.Reset(0)\n}"},
+		{Path: "Watcher::Close", Source: "func (w *Watcher) Close() error {\n\treturn w.fsWatcher.Close()\n}"},
+		{Path: "NewWatcher", Source: "func NewWatcher(root string) (*Watcher, error) {\n\treturn &Watcher{root: root}, nil\n}"},
+	} {
+		chunk.File = file.Path
+		chunk.Type = "src"
+		file.Chunks = append(file.Chunks, chunk)
+	}
+	s.Require().NoError(idx.Index(s.ctx, file))
+
+	results, err := idx.Search(s.ctx, "flush pending debounce", index.SearchOptions{
+		FileTypes: []string{"src"},
+		Mode:      index.SearchSemantic,
+	})
+	s.Require().NoError(err)
+	s.Require().NotEmpty(results)
+	s.Contains(results[0], "watcher.go::Watcher::FlushPending")
+}
+
+func (s *LexicalEmbedderTestSuite) TestCorpusStatisticsCountEmbeddedText() {
+	idx, err := openIndex(s.T(), s.ctx, s.T().TempDir(), index.Config{
+		Embedder: index.EmbedderConfig{Provider: "lexical"},
+	})
+	s.Require().NoError(err)
+
+	// Every chunk but one is embedded with "ledger" in its file path, which
+	// makes it a common term even though no chunk's code mentions it
+	for i, name := range []string{"Open", "Close", "Append", "Balance", "Export", "Import", "Audit", "Total", "Notes"} {
+		path := fmt.Sprintf("ledger/part%d.go", i)
+		source := fmt.Sprintf("func %s() {\n\treturn\n}", name)
+		if name == "Notes" {
+			path = "notes/notes.go"
+			source = "func Notes() {\n\treconcile()\n}"
+		}
+
+		file := &parser.File{Path: path, Chunks: []*parser.Chunk{{File: path, Path: name, Type: "src", Source: source}}}
+		s.Require().NoError(idx.Index(s.ctx, file))
+	}
+
+	results, err := idx.Search(s.ctx, "ledger reconcile", index.SearchOptions{
+		FileTypes: []string{"src"},
+		Mode:      index.SearchSemantic,
+	})
+	s.Require().NoError(err)
+	s.Require().NotEmpty(results)
+	s.Contains(results[0], "notes/notes.go::Notes")
+}
+
+func TestLexicalEmbedderTestSuite(t *testing.T) {
+	suite.Run(t, new(LexicalEmbedderTestSuite))
+}
@@ -0,0 +1,116 @@
+package index
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/cespare/xxhash"
+)
+
+// legacyCollection is the collection indexes were stored in before their
+// embedding model was recorded
+const legacyCollection = "code-chunks"
+
+// embeddingSpec identifies the vector space a collection's embeddings live in
+type embeddingSpec struct {
+	Provider   string `json:"provider"`
+	Model      string `json:"model"`
+	Dimensions int    `json:"dimensions"`          // 0 if unknown
+	Templates  string `json:"templates,omitempty"` // key of custom embedding templates
+	// Quantization is the format embeddings are stored in
+	Quantization string `json:"quantization,omitempty"`
+}
+
+// matches reports whether both specs describe the same stored embeddings
+func (s embeddingSpec) matches(other embeddingSpec) bool {
+	return s.sameSpace(other) && s.Quantization == other.Quantization
+}
+
+// sameSpace reports whether vectors of both specs can be compared. Unknown
+// dimensions (the embedder couldn't be reached) don't count as a mismatch.
+func (s embeddingSpec) sameSpace(other embeddingSpec) bool {
+	if s.Provider != other.Provider || s.Model != other.Model || s.Templates != other.Templates {
+		return false
+	}
+
+	return s.Dimensions == 0 || other.Dimensions == 0 || s.Dimensions == other.Dimensions
+}
+
+// collection is the name of the collection holding embeddings of this spec
+func (s embeddingSpec) collection() string {
+	key := s.Provider + "/" + s.Model + "/" + strconv.Itoa(s.Dimensions) + "/" + s.Templates
+	if s.Quantization != "" {
+		key += "/" + s.Quantization
+	}
+
+	return fmt.Sprintf("%s-%016x", legacyCollection, xxhash.Sum64String(key))
+}
+
+func (s embeddingSpec) metadata() map[string]string {
+	return map[string]string{
+		"provider":     s.Provider,
+		"model":        s.Model,
+		"dimensions":   strconv.Itoa(s.Dimensions),
+		"templates":    s.Templates,
+		"quantization": s.Quantization,
+	}
+}
+
+// manifest records which collection is live and which embedding model built
+// it. chromem-go doesn't expose collection metadata once it's persisted, so
+// it's kept next to the db and replaced atomically when a rebuild completes.
+type manifest struct {
+	Collection string `json:"collection"`
+	embeddingSpec
+}
+
+func manifestPath(workspaceRoot string) string {
+	return filepath.Join(workspaceRoot, ".sourcerer", "manifest.json")
+}
+
+// readManifest returns the workspace's manifest, or nil if there's none yet
+func readManifest(workspaceRoot string) (*manifest, error) {
+	data, err := os.ReadFile(manifestPath(workspaceRoot))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index manifest: %w", err)
+	}
+
+	var m manifest
+	err = json.Unmarshal(data, &m)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse index manifest: %w", err)
+	}
+
+	return &m, nil
+}
+
+// writeManifest replaces the workspace's manifest, writing to a temporary
+// file first so readers never see a partial one
+func writeManifest(workspaceRoot string, m *manifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode index manifest: %w", err)
+	}
+
+	path := manifestPath(workspaceRoot)
+	tmp := path + ".tmp"
+	err = os.WriteFile(tmp, data, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to write index manifest: %w", err)
+	}
+
+	err = os.Rename(tmp, path)
+	if err != nil {
+		return fmt.Errorf("failed to replace index manifest: %w", err)
+	}
+
+	return nil
+}
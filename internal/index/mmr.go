@@ -0,0 +1,61 @@
+package index
+
+// rankedDoc is a search candidate with its retrieval score
+type rankedDoc struct {
+	doc   Document
+	score float32
+}
+
+// maximalMarginalRelevance greedily re-orders the first n of the ranked
+// candidates so that each pick maximizes
+//
+//	lambda * relevance - (1 - lambda) * max similarity to earlier picks
+//
+// trading relevance for diversity among near-duplicate chunks. Relevance is
+// the score scaled to [0, 1] since fused and reranked scores aren't
+// similarities.
+func maximalMarginalRelevance(ranked []rankedDoc, lambda float32, n int) []rankedDoc {
+	if len(ranked) == 0 {
+		return ranked
+	}
+
+	// Rerankers may return negative scores, shift those to start at 0
+	var minScore, maxScore float32
+	for _, candidate := range ranked {
+		minScore = min(minScore, candidate.score)
+		maxScore = max(maxScore, candidate.score)
+	}
+
+	remaining := append([]rankedDoc(nil), ranked...)
+	picked := make([]rankedDoc, 0, min(n, len(ranked)))
+	// redundancy[i] is the highest similarity of remaining[i] to any pick
+	redundancy := make([]float32, len(remaining))
+
+	for len(picked) < n && len(remaining) > 0 {
+		best, bestScore := 0, float32(0)
+		for i, candidate := range remaining {
+			relevance := float32(0)
+			if maxScore > minScore {
+				relevance = (candidate.score - minScore) / (maxScore - minScore)
+			}
+
+			score := lambda*relevance - (1-lambda)*redundancy[i]
+			if i == 0 || score > bestScore {
+				best, bestScore = i, score
+			}
+		}
+
+		pick := remaining[best]
+		picked = append(picked, pick)
+		remaining = append(remaining[:best], remaining[best+1:]...)
+		redundancy = append(redundancy[:best], redundancy[best+1:]...)
+
+		// Embeddings are normalized, and missing ones (e.g. chunks still
+		// waiting to be re-embedded) aren't similar to anything
+		for i, candidate := range remaining {
+			redundancy[i] = max(redundancy[i], dot(pick.doc.Embedding, candidate.doc.Embedding))
+		}
+	}
+
+	return picked
+}
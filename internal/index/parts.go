@@ -0,0 +1,117 @@
+package index
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/st3v3nmw/sourcerer-mcp/internal/parser"
+)
+
+// parentID returns the ID of the chunk a stored document belongs to, which
+// is the document's own ID unless it's a part of a split chunk
+func parentID(doc Document) string {
+	parent := doc.Metadata["parent"]
+	if parent == "" {
+		return doc.ID
+	}
+
+	return doc.Metadata["file"] + "::" + parent
+}
+
+// chunk returns a stored chunk, reassembling split chunks from their parts
+func (idx *Index) chunk(ctx context.Context, id string) (*parser.Chunk, error) {
+	doc, err := idx.document(ctx, id)
+	if err == nil {
+		return chunkFromDocument(doc), nil
+	}
+
+	file, path, _ := strings.Cut(id, "::")
+	where := map[string]string{"file": file, "parent": path}
+
+	var parts []*Document
+	for _, collection := range []VectorStore{idx.collection, idx.previousStore()} {
+		if collection == nil {
+			continue
+		}
+
+		found, err := collection.GetWhere(ctx, where)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up documents in vector db: %w", err)
+		}
+
+		parts = append(parts, found...)
+	}
+
+	if len(parts) == 0 {
+		return nil, fmt.Errorf("chunk not found: %s", id)
+	}
+
+	return joinParts(parts), nil
+}
+
+// joinParts reassembles a split chunk, dropping the source parts share.
+// Parts of overlong lines start and end mid-line, so they're joined by
+// column.
+func joinParts(parts []*Document) *parser.Chunk {
+	chunks := make([]*parser.Chunk, len(parts))
+	for i, part := range parts {
+		chunks[i] = chunkFromDocument(*part)
+	}
+
+	sort.Slice(chunks, func(i, j int) bool {
+		if chunks[i].StartLine != chunks[j].StartLine {
+			return chunks[i].StartLine < chunks[j].StartLine
+		}
+
+		return chunks[i].StartColumn < chunks[j].StartColumn
+	})
+
+	first, last := chunks[0], chunks[len(chunks)-1]
+	joined := &parser.Chunk{
+		File:        first.File,
+		Type:        first.Type,
+		Path:        first.Parent,
+		Kind:        first.Kind,
+		Summary:     first.Summary,
+		Doc:         first.Doc,
+		CodeStart:   first.CodeStart,
+		StartLine:   first.StartLine,
+		StartColumn: first.StartColumn,
+		EndLine:     last.EndLine,
+		EndColumn:   last.EndColumn,
+		ParsedAt:    first.ParsedAt,
+	}
+
+	// column returns the column the source of a chunk's line starts at
+	column := func(chunk *parser.Chunk, line uint) int {
+		if line == chunk.StartLine {
+			return int(chunk.StartColumn)
+		}
+
+		return 1
+	}
+
+	var lines []string
+	for _, chunk := range chunks {
+		for i, text := range strings.Split(chunk.Source, "\n") {
+			line := chunk.StartLine + uint(i)
+			j := int(line - first.StartLine)
+
+			switch {
+			case j >= len(lines):
+				lines = append(lines, text)
+			case j == len(lines)-1:
+				// Only the end of the line past what's joined already is new
+				covered := column(joined, line) + len(lines[j]) - column(chunk, line)
+				if covered >= 0 && covered < len(text) {
+					lines[j] += text[covered:]
+				}
+			}
+		}
+	}
+	joined.Source = strings.Join(lines, "\n")
+
+	return joined
+}
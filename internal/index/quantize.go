@@ -0,0 +1,273 @@
+package index
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/bits"
+	"os"
+)
+
+// Quantization formats stored embeddings can be kept in
+const (
+	QuantizationNone   = ""       // float32, 4 bytes per dimension
+	QuantizationInt8   = "int8"   // 1 byte per dimension plus a scale
+	QuantizationBinary = "binary" // 1 bit per dimension
+)
+
+// rescoreFactor is how many candidates per requested result are ranked by
+// their quantized codes before being rescored with the full-precision query
+const rescoreFactor = 4
+
+// QuantizationFromEnv reads the stored embedding format from the environment
+func QuantizationFromEnv() string {
+	return os.Getenv("SOURCERER_QUANTIZATION")
+}
+
+// quantizer encodes normalized embeddings into the form a vector store keeps
+// them in
+type quantizer interface {
+	encode(embedding []float32) []byte
+	// check fails if a code is malformed, e.g. a truncated blob
+	check(code []byte) error
+	// decode returns an approximation of the normalized embedding, failing
+	// if the code is malformed
+	decode(code []byte) ([]float32, error)
+	// coarse returns a cheap similarity between a query and well-formed
+	// codes, only meant to rank codes against each other
+	coarse(query []float32) func(code []byte) float32
+	// rescore estimates the cosine similarity of a full-precision query and
+	// a well-formed code
+	rescore(query []float32, code []byte) float32
+}
+
+// malformedCode is the error of a code that doesn't hold what its format
+// says it does
+func malformedCode(format string, code []byte) error {
+	return fmt.Errorf("malformed %s embedding code of %d bytes", format, len(code))
+}
+
+func newQuantizer(format string) (quantizer, error) {
+	switch format {
+	case QuantizationNone:
+		return float32Quantizer{}, nil
+	case QuantizationInt8:
+		return int8Quantizer{}, nil
+	case QuantizationBinary:
+		return binaryQuantizer{}, nil
+	default:
+		return nil, fmt.Errorf("unknown quantization %q (available: int8, binary)", format)
+	}
+}
+
+// quantizationRank orders formats from most to least precise
+func quantizationRank(format string) int {
+	switch format {
+	case QuantizationNone:
+		return 0
+	case QuantizationInt8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// float32Quantizer keeps embeddings as little-endian float32s
+type float32Quantizer struct{}
+
+func (float32Quantizer) encode(embedding []float32) []byte {
+	code := make([]byte, 4*len(embedding))
+	for i, value := range embedding {
+		binary.LittleEndian.PutUint32(code[4*i:], math.Float32bits(value))
+	}
+
+	return code
+}
+
+func (float32Quantizer) check(code []byte) error {
+	if len(code)%4 != 0 {
+		return malformedCode("float32", code)
+	}
+
+	return nil
+}
+
+func (q float32Quantizer) decode(code []byte) ([]float32, error) {
+	err := q.check(code)
+	if err != nil {
+		return nil, err
+	}
+
+	embedding := make([]float32, len(code)/4)
+	for i := range embedding {
+		embedding[i] = math.Float32frombits(binary.LittleEndian.Uint32(code[4*i:]))
+	}
+
+	return embedding, nil
+}
+
+func (q float32Quantizer) coarse(query []float32) func(code []byte) float32 {
+	return func(code []byte) float32 {
+		return q.rescore(query, code)
+	}
+}
+
+func (float32Quantizer) rescore(query []float32, code []byte) float32 {
+	var sum float32
+	for i := range min(len(query), len(code)/4) {
+		sum += query[i] * math.Float32frombits(binary.LittleEndian.Uint32(code[4*i:]))
+	}
+
+	return sum
+}
+
+// int8Quantizer scales each embedding so its largest component is ±127 and
+// rounds it to bytes, prefixed by the float32 scale that undoes it
+type int8Quantizer struct{}
+
+func (int8Quantizer) encode(embedding []float32) []byte {
+	var largest float32
+	for _, value := range embedding {
+		largest = max(largest, float32(math.Abs(float64(value))))
+	}
+
+	scale := largest / 127
+	code := make([]byte, 4+len(embedding))
+	binary.LittleEndian.PutUint32(code, math.Float32bits(scale))
+	if scale == 0 {
+		return code
+	}
+
+	for i, value := range embedding {
+		code[4+i] = byte(int8(math.Round(float64(value / scale))))
+	}
+
+	return code
+}
+
+// check fails if the code doesn't start with its scale
+func (int8Quantizer) check(code []byte) error {
+	if len(code) < 4 {
+		return malformedCode("int8", code)
+	}
+
+	return nil
+}
+
+func (q int8Quantizer) decode(code []byte) ([]float32, error) {
+	err := q.check(code)
+	if err != nil {
+		return nil, err
+	}
+
+	scale := math.Float32frombits(binary.LittleEndian.Uint32(code))
+	embedding := make([]float32, len(code)-4)
+	for i := range embedding {
+		embedding[i] = float32(int8(code[4+i])) * scale
+	}
+
+	return embedding, nil
+}
+
+func (q int8Quantizer) coarse(query []float32) func(code []byte) float32 {
+	quantized := q.encode(query)[4:]
+
+	return func(code []byte) float32 {
+		var sum int32
+		for i, value := range code[4:min(len(code), 4+len(quantized))] {
+			sum += int32(int8(value)) * int32(int8(quantized[i]))
+		}
+
+		return float32(sum) * math.Float32frombits(binary.LittleEndian.Uint32(code))
+	}
+}
+
+func (int8Quantizer) rescore(query []float32, code []byte) float32 {
+	var sum float32
+	for i := range min(len(query), len(code)-4) {
+		sum += query[i] * float32(int8(code[4+i]))
+	}
+
+	return sum * math.Float32frombits(binary.LittleEndian.Uint32(code))
+}
+
+// binaryQuantizer keeps the sign of each component, prefixed by the number of
+// dimensions. Signs are compared by Hamming distance, and rescored against
+// the full-precision query scaled by sqrt(π/2), the ratio between the cosine
+// similarity and the similarity to sign vectors of normally distributed
+// components.
+type binaryQuantizer struct{}
+
+var binaryRescale = float32(math.Sqrt(math.Pi / 2))
+
+func (binaryQuantizer) encode(embedding []float32) []byte {
+	code := make([]byte, 4+(len(embedding)+7)/8)
+	binary.LittleEndian.PutUint32(code, uint32(len(embedding)))
+	for i, value := range embedding {
+		if value > 0 {
+			code[4+i/8] |= 1 << (i % 8)
+		}
+	}
+
+	return code
+}
+
+// check fails if the code doesn't hold a bit for each of the dimensions it
+// starts with
+func (binaryQuantizer) check(code []byte) error {
+	if len(code) < 4 || uint64(len(code)-4) != (uint64(binary.LittleEndian.Uint32(code))+7)/8 {
+		return malformedCode("binary", code)
+	}
+
+	return nil
+}
+
+func (q binaryQuantizer) decode(code []byte) ([]float32, error) {
+	err := q.check(code)
+	if err != nil {
+		return nil, err
+	}
+
+	embedding := make([]float32, binary.LittleEndian.Uint32(code))
+	magnitude := float32(1 / math.Sqrt(float64(len(embedding))))
+	for i := range embedding {
+		embedding[i] = -magnitude
+		if code[4+i/8]&(1<<(i%8)) != 0 {
+			embedding[i] = magnitude
+		}
+	}
+
+	return embedding, nil
+}
+
+func (q binaryQuantizer) coarse(query []float32) func(code []byte) float32 {
+	quantized := q.encode(query)[4:]
+
+	return func(code []byte) float32 {
+		differing := 0
+		for i, value := range code[4:min(len(code), 4+len(quantized))] {
+			differing += bits.OnesCount8(value ^ quantized[i])
+		}
+
+		return -float32(differing)
+	}
+}
+
+func (binaryQuantizer) rescore(query []float32, code []byte) float32 {
+	dims := min(len(query), int(binary.LittleEndian.Uint32(code)))
+	if dims == 0 {
+		return 0
+	}
+
+	var sum float32
+	for i := range dims {
+		if code[4+i/8]&(1<<(i%8)) != 0 {
+			sum += query[i]
+		} else {
+			sum -= query[i]
+		}
+	}
+
+	similarity := sum / float32(math.Sqrt(float64(dims))) * binaryRescale
+	return max(-1, min(1, similarity))
+}
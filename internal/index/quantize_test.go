@@ -0,0 +1,194 @@
+package index_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"maps"
+	"math/rand/v2"
+	"path/filepath"
+	"testing"
+
+	"github.com/st3v3nmw/sourcerer-mcp/internal/index"
+	"github.com/stretchr/testify/suite"
+)
+
+type QuantizationTestSuite struct {
+	suite.Suite
+	ctx   context.Context
+	rng   *rand.Rand
+	dir   string
+	db    index.VectorDB
+	exact index.VectorStore
+	docs  []index.Document
+}
+
+func (s *QuantizationTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.rng = rand.New(rand.NewPCG(3, 5))
+
+	var err error
+	s.dir = s.T().TempDir()
+	s.db, err = index.NewVectorDB("sqlite", s.dir)
+	s.Require().NoError(err)
+
+	// Embeddings of code cluster by topic
+	var topics [][]float32
+	for range 20 {
+		topics = append(topics, s.vector())
+	}
+
+	s.docs = nil
+	for i := range 500 {
+		s.docs = append(s.docs, index.Document{
+			ID:        fmt.Sprintf("a.go::F%d", i),
+			Content:   "func",
+			Embedding: s.near(topics[i%len(topics)], 0.7),
+			Metadata:  map[string]string{"file": "a.go"},
+		})
+	}
+
+	s.exact = s.open("exact", index.QuantizationNone)
+}
+
+func (s *QuantizationTestSuite) vector() []float32 {
+	v := make([]float32, 128)
+	for i := range v {
+		v[i] = float32(s.rng.NormFloat64())
+	}
+
+	return v
+}
+
+func (s *QuantizationTestSuite) near(v []float32, noise float32) []float32 {
+	result := s.vector()
+	for i := range result {
+		result[i] = v[i] + noise*result[i]
+	}
+
+	return result
+}
+
+func (s *QuantizationTestSuite) open(name, quantization string) index.VectorStore {
+	store, err := s.db.Open(name, map[string]string{"quantization": quantization})
+	s.Require().NoError(err)
+	s.Require().NoError(store.Add(s.ctx, s.docs))
+
+	return store
+}
+
+// compare returns the fraction of the exact top 10 results a quantized store
+// finds, and the largest error of its similarities
+func (s *QuantizationTestSuite) compare(store index.VectorStore) (float64, float64) {
+	hits, total, worst := 0, 0, 0.0
+	for range 30 {
+		query := s.near(s.docs[s.rng.IntN(len(s.docs))].Embedding, 1)
+
+		expected, err := s.exact.Query(s.ctx, query, 10, nil)
+		s.Require().NoError(err)
+		similarities := map[string]float32{}
+		for _, result := range expected {
+			similarities[result.ID] = result.Similarity
+		}
+
+		found, err := store.Query(s.ctx, query, 10, nil)
+		s.Require().NoError(err)
+		s.Require().Len(found, 10)
+
+		for _, result := range found {
+			similarity, exists := similarities[result.ID]
+			if exists {
+				hits++
+				worst = max(worst, float64(abs(similarity-result.Similarity)))
+			}
+		}
+		total += len(expected)
+	}
+
+	return float64(hits) / float64(total), worst
+}
+
+func (s *QuantizationTestSuite) TestInt8() {
+	store := s.open("int8", index.QuantizationInt8)
+
+	recall, worst := s.compare(store)
+	s.GreaterOrEqual(recall, 0.95)
+	s.Less(worst, 0.01)
+
+	doc, err := store.Get(s.ctx, "a.go::F0")
+	s.Require().NoError(err)
+	s.Len(doc.Embedding, 128)
+}
+
+func (s *QuantizationTestSuite) TestBinary() {
+	store := s.open("binary", index.QuantizationBinary)
+
+	recall, worst := s.compare(store)
+	s.GreaterOrEqual(recall, 0.5)
+	s.Less(worst, 0.25)
+
+	// Documents are their own nearest neighbours
+	doc, err := store.Get(s.ctx, "a.go::F7")
+	s.Require().NoError(err)
+	s.Len(doc.Embedding, 128)
+
+	results, err := store.Query(s.ctx, s.docs[7].Embedding, 1, nil)
+	s.Require().NoError(err)
+	s.Equal("a.go::F7", results[0].ID)
+}
+
+func (s *QuantizationTestSuite) TestMalformedCodesAreNotDecoded() {
+	// Truncated blobs, e.g. left behind by a bad copy of the database
+	malformed := map[string][]byte{"a.go::F3": {1, 2}, "a.go::F4": {0}}
+
+	for _, quantization := range []string{index.QuantizationInt8, index.QuantizationBinary} {
+		s.Run(quantization, func() {
+			store := s.open(quantization, quantization)
+
+			codes := maps.Clone(malformed)
+			if quantization == index.QuantizationBinary {
+				// Fewer bits than the dimensions the code starts with
+				codes["a.go::F5"] = []byte{128, 0, 0, 0, 1, 2}
+			}
+
+			db, err := sql.Open("sqlite", filepath.Join(s.dir, "vectors.sqlite"))
+			s.Require().NoError(err)
+			defer db.Close()
+			for id, code := range codes {
+				_, err = db.Exec("UPDATE documents SET embedding = ? WHERE collection = ? AND id = ?", code, quantization, id)
+				s.Require().NoError(err)
+			}
+
+			for id := range codes {
+				doc, err := store.Get(s.ctx, id)
+				s.Require().NoError(err)
+				s.Empty(doc.Embedding)
+			}
+
+			docs, err := store.List(s.ctx)
+			s.Require().NoError(err)
+			s.Len(docs, len(s.docs))
+
+			// Malformed codes can't be scored, so they aren't returned
+			results, err := store.Query(s.ctx, s.docs[3].Embedding, len(s.docs), nil)
+			s.Require().NoError(err)
+			s.Len(results, len(s.docs)-len(codes))
+			for _, result := range results {
+				s.NotContains(codes, result.ID)
+			}
+		})
+	}
+}
+
+func (s *QuantizationTestSuite) TestUnknownFormat() {
+	_, err := s.db.Open("int4", map[string]string{"quantization": "int4"})
+	s.Error(err)
+}
+
+func abs(x float32) float32 {
+	return max(x, -x)
+}
+
+func TestQuantizationTestSuite(t *testing.T) {
+	suite.Run(t, new(QuantizationTestSuite))
+}
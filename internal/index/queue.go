@@ -0,0 +1,340 @@
+package index
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cespare/xxhash"
+	"github.com/st3v3nmw/sourcerer-mcp/internal/parser"
+)
+
+const (
+	queueRetryInterval    = 15 * time.Second
+	maxQueueRetryInterval = 5 * time.Minute
+)
+
+// ErrEmbeddingQueued is returned by Index when a file was parsed but couldn't
+// be embedded. Its chunks are queued and indexed once the embedder is back.
+var ErrEmbeddingQueued = errors.New("embedder unavailable, file queued for indexing")
+
+// embedderError is an error of the embedder, as opposed to the vector db
+type embedderError struct {
+	err error
+}
+
+func (e *embedderError) Error() string {
+	return e.err.Error()
+}
+
+func (e *embedderError) Unwrap() error {
+	return e.err
+}
+
+// queueable reports whether err means the embedder is unreachable, overloaded
+// or too slow, so a file that failed to index with it should be queued. It
+// isn't when the embedder rejects requests, e.g. for a bad model or API key,
+// or answers them with responses that can't be used, since retrying them
+// won't help.
+func queueable(err error) bool {
+	var embedErr *embedderError
+	if !errors.As(err, &embedErr) {
+		return false
+	}
+
+	var status *httpStatusError
+	if errors.As(err, &status) {
+		return status.retryable()
+	}
+
+	return transient(err)
+}
+
+// Backlog describes the files waiting for the embedder
+type Backlog struct {
+	Files     int
+	Chunks    int
+	LastError string // why the last attempt to embed them failed
+}
+
+// queuedFile is a parsed file whose chunks weren't embedded yet
+type queuedFile struct {
+	Path  string     `json:"path"`
+	State fileState  `json:"state"`
+	Docs  []Document `json:"docs"`
+}
+
+// embedQueue persists parsed files that are waiting for the embedder under
+// .sourcerer/queue, one JSON file each, so they survive restarts
+type embedQueue struct {
+	dir  string
+	wake chan struct{}
+
+	mu        sync.Mutex
+	files     map[string]*queuedFile
+	lastError string
+}
+
+func newEmbedQueue(workspaceRoot string) (*embedQueue, error) {
+	q := &embedQueue{
+		dir:   filepath.Join(workspaceRoot, ".sourcerer", "queue"),
+		wake:  make(chan struct{}, 1),
+		files: map[string]*queuedFile{},
+	}
+
+	entries, err := os.ReadDir(q.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return q, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embedding queue: %w", err)
+	}
+
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(q.dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read embedding queue: %w", err)
+		}
+
+		var file queuedFile
+		err = json.Unmarshal(data, &file)
+		if err != nil || file.Path == "" {
+			// A write was interrupted, the file gets re-parsed since it's stale
+			os.Remove(filepath.Join(q.dir, entry.Name()))
+			continue
+		}
+
+		q.files[file.Path] = &file
+	}
+
+	return q, nil
+}
+
+func (q *embedQueue) entryPath(filePath string) string {
+	return filepath.Join(q.dir, fmt.Sprintf("%016x.json", xxhash.Sum64String(filePath)))
+}
+
+// add queues a file's chunks, replacing any earlier version of the file
+func (q *embedQueue) add(filePath string, state fileState, docs []Document, cause error) error {
+	file := &queuedFile{Path: filePath, State: state, Docs: make([]Document, len(docs))}
+	for i, doc := range docs {
+		doc.Embedding = nil
+		file.Docs[i] = doc
+	}
+
+	data, err := json.Marshal(file)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(q.dir, 0o755)
+	if err != nil {
+		return err
+	}
+
+	path := q.entryPath(filePath)
+	err = os.WriteFile(path+".tmp", data, 0o600)
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(path+".tmp", path)
+	if err != nil {
+		return err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.files[filePath] = file
+	q.lastError = cause.Error()
+
+	return nil
+}
+
+// remove drops a file from the queue, if it's queued
+func (q *embedQueue) remove(filePath string) error {
+	q.mu.Lock()
+	_, queued := q.files[filePath]
+	delete(q.files, filePath)
+	if len(q.files) == 0 {
+		q.lastError = ""
+	}
+	q.mu.Unlock()
+
+	if !queued {
+		return nil
+	}
+
+	err := os.Remove(q.entryPath(filePath))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove %s from the embedding queue: %w", filePath, err)
+	}
+
+	return nil
+}
+
+// get returns a queued file, nil if it isn't queued
+func (q *embedQueue) get(filePath string) *queuedFile {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.files[filePath]
+}
+
+// paths returns the queued files in a stable order
+func (q *embedQueue) paths() []string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	paths := make([]string, 0, len(q.files))
+	for path := range q.files {
+		paths = append(paths, path)
+	}
+
+	sort.Strings(paths)
+	return paths
+}
+
+// chunk returns a chunk of a queued file, reassembling split chunks, and
+// whether the file is queued. The chunk is nil if the file doesn't have it.
+func (q *embedQueue) chunk(id string) (*parser.Chunk, bool) {
+	filePath, path, _ := strings.Cut(id, "::")
+
+	q.mu.Lock()
+	file, queued := q.files[filePath]
+	q.mu.Unlock()
+
+	if !queued {
+		return nil, false
+	}
+
+	var parts []*Document
+	for i, doc := range file.Docs {
+		if doc.ID == id {
+			return chunkFromDocument(doc), true
+		}
+
+		if doc.Metadata["parent"] == path {
+			parts = append(parts, &file.Docs[i])
+		}
+	}
+
+	if len(parts) == 0 {
+		return nil, true
+	}
+
+	return joinParts(parts), true
+}
+
+func (q *embedQueue) backlog() Backlog {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	backlog := Backlog{Files: len(q.files), LastError: q.lastError}
+	for _, file := range q.files {
+		backlog.Chunks += len(file.Docs)
+	}
+
+	return backlog
+}
+
+// notify wakes the retry loop up early, e.g. when the embedder just worked
+func (q *embedQueue) notify() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// EmbeddingBacklog returns the parsed files that are waiting for the embedder
+func (idx *Index) EmbeddingBacklog() Backlog {
+	return idx.queue.backlog()
+}
+
+// retryQueued indexes queued files in the background until ctx is done,
+// backing off while the embedder stays unavailable
+func (idx *Index) retryQueued(ctx context.Context) {
+	interval := queueRetryInterval
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-idx.queue.wake:
+		case <-time.After(interval):
+		}
+
+		err := idx.drainQueue(ctx)
+		if err == nil {
+			interval = queueRetryInterval
+
+			// Files a rebuild couldn't migrate may have been the last ones
+			idx.finishRebuild()
+		} else {
+			interval = min(2*interval, maxQueueRetryInterval)
+		}
+	}
+}
+
+// drainQueue indexes the queued files, stopping at the first one that still
+// can't be embedded. Files that fail for other reasons are dropped from the
+// queue, so they don't hold up the files behind them.
+func (idx *Index) drainQueue(ctx context.Context) error {
+	for _, filePath := range idx.queue.paths() {
+		err := idx.indexQueued(ctx, filePath)
+		if errors.Is(err, ErrEmbeddingQueued) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (idx *Index) indexQueued(ctx context.Context, filePath string) error {
+	idx.writeMu.Lock()
+	defer idx.writeMu.Unlock()
+
+	// The file could have been indexed or removed meanwhile
+	file := idx.queue.get(filePath)
+	if file == nil {
+		return nil
+	}
+
+	docs := make([]Document, len(file.Docs))
+	for i, doc := range file.Docs {
+		doc.Metadata = maps.Clone(doc.Metadata)
+		docs[i] = doc
+	}
+
+	err := idx.index(ctx, filePath, file.State, docs)
+	if err == nil || errors.Is(err, ErrEmbeddingQueued) || ctx.Err() != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Sourcerer: failed to index queued file %s: %v\n", filePath, err)
+
+	// Retrying won't help, the file is re-parsed on its next change or at
+	// startup instead
+	removeErr := idx.queue.remove(filePath)
+	if removeErr != nil {
+		fmt.Fprintf(os.Stderr, "Sourcerer: %v\n", removeErr)
+	}
+
+	idx.cacheMu.Lock()
+	delete(idx.cache, filePath)
+	idx.cacheMu.Unlock()
+
+	return err
+}
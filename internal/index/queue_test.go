@@ -0,0 +1,276 @@
+package index_test
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/st3v3nmw/sourcerer-mcp/internal/index"
+	"github.com/st3v3nmw/sourcerer-mcp/internal/parser"
+	"github.com/stretchr/testify/suite"
+)
+
+// flakyEmbedder fails while embedderDown is set, like a crashed Ollama
+type flakyEmbedder struct {
+	index.Embedder
+}
+
+func (e *flakyEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	if embedderDown.Load() {
+		return nil, &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+	}
+
+	// An empty embedding can't be stored, like a response of the wrong shape
+	if strings.Contains(text, "unembeddable") {
+		return nil, nil
+	}
+
+	return e.Embedder.Embed(ctx, text)
+}
+
+var embedderDown atomic.Bool
+
+func init() {
+	// flaky-lexical is a different model, switching to it rebuilds the index
+	for name, provider := range map[string]string{"flaky": "test", "flaky-lexical": "lexical"} {
+		index.RegisterEmbedder(name, func(cfg index.EmbedderConfig) (index.Embedder, error) {
+			inner, err := index.NewEmbedder(index.EmbedderConfig{Provider: provider})
+			if err != nil {
+				return nil, err
+			}
+
+			return &flakyEmbedder{Embedder: inner}, nil
+		})
+	}
+}
+
+type EmbedQueueTestSuite struct {
+	suite.Suite
+	ctx           context.Context
+	workspaceRoot string
+}
+
+func (s *EmbedQueueTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.workspaceRoot = s.T().TempDir()
+	embedderDown.Store(false)
+}
+
+func (s *EmbedQueueTestSuite) TearDownTest() {
+	embedderDown.Store(false)
+}
+
+func (s *EmbedQueueTestSuite) open() *index.Index {
+	idx, err := openIndex(s.T(), s.ctx, s.workspaceRoot, index.Config{
+		Embedder: index.EmbedderConfig{Provider: "flaky"},
+	})
+	s.Require().NoError(err)
+
+	return idx
+}
+
+// editedFile is testFile with a new body for ParseConfig
+func editedFile() *parser.File {
+	file := testFile()
+	file.Chunks[0].Source = "func ParseConfig(path string) (*Config, error) {\n\treturn loadConfig(path)\n}"
+	return file
+}
+
+func (s *EmbedQueueTestSuite) TestQueuesFilesWhileEmbedderIsDown() {
+	idx := s.open()
+	s.Require().NoError(idx.Index(s.ctx, testFile()))
+
+	embedderDown.Store(true)
+	err := idx.Index(s.ctx, editedFile())
+	s.ErrorIs(err, index.ErrEmbeddingQueued)
+
+	backlog := idx.EmbeddingBacklog()
+	s.Equal(1, backlog.Files)
+	s.Equal(len(editedFile().Chunks), backlog.Chunks)
+	s.Contains(backlog.LastError, "connection refused")
+
+	// Chunks are read from the queued version of the file
+	chunk, err := idx.GetChunk(s.ctx, "pkg/config.go::ParseConfig")
+	s.Require().NoError(err)
+	s.Contains(chunk.Source, "loadConfig")
+
+	// The previous version stays searchable
+	results, err := idx.Search(s.ctx, "readConfig", index.SearchOptions{
+		FileTypes: []string{"src"},
+		Mode:      index.SearchLexical,
+	})
+	s.Require().NoError(err)
+	s.NotEmpty(results)
+}
+
+func (s *EmbedQueueTestSuite) TestQueueSurvivesRestart() {
+	embedderDown.Store(true)
+	idx := s.open()
+	s.ErrorIs(idx.Index(s.ctx, testFile()), index.ErrEmbeddingQueued)
+	s.Require().NoError(idx.Close())
+
+	reopened := s.open()
+	s.Equal(1, reopened.EmbeddingBacklog().Files)
+}
+
+func (s *EmbedQueueTestSuite) TestRetriesOnceEmbedderRecovers() {
+	embedderDown.Store(true)
+	idx := s.open()
+	s.ErrorIs(idx.Index(s.ctx, testFile()), index.ErrEmbeddingQueued)
+
+	// Another file getting embedded shows the embedder is back
+	embedderDown.Store(false)
+	other := testFile()
+	other.Path = "pkg/other.go"
+	for _, chunk := range other.Chunks {
+		chunk.File = other.Path
+	}
+	s.Require().NoError(idx.Index(s.ctx, other))
+
+	s.Require().Eventually(func() bool {
+		return idx.EmbeddingBacklog().Files == 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	results, err := idx.Search(s.ctx, "watcher flush pending changes", index.SearchOptions{
+		FileTypes: []string{"src"},
+		Mode:      index.SearchSemantic,
+	})
+	s.Require().NoError(err)
+
+	var found bool
+	for _, result := range results {
+		found = found || strings.HasPrefix(result, "pkg/config.go::FlushPending")
+	}
+	s.True(found)
+}
+
+func (s *EmbedQueueTestSuite) TestRemoveDropsQueuedFile() {
+	embedderDown.Store(true)
+	idx := s.open()
+	s.ErrorIs(idx.Index(s.ctx, testFile()), index.ErrEmbeddingQueued)
+
+	s.Require().NoError(idx.Remove(s.ctx, "pkg/config.go"))
+	s.Zero(idx.EmbeddingBacklog().Files)
+	s.Require().NoError(idx.Close())
+
+	reopened := s.open()
+	s.Zero(reopened.EmbeddingBacklog().Files)
+}
+
+func (s *EmbedQueueTestSuite) TestQueuesFilesWhileRebuildCantEmbed() {
+	idx := s.open()
+	s.Require().NoError(idx.Index(s.ctx, testFile()))
+	s.Require().NoError(idx.Close())
+
+	embedderDown.Store(true)
+	switched, err := openIndex(s.T(), s.ctx, s.workspaceRoot, index.Config{
+		Embedder: index.EmbedderConfig{Provider: "flaky-lexical"},
+	})
+	s.Require().NoError(err)
+	s.True(switched.Rebuilding())
+
+	// Migrating the file's chunks to the new model fails too
+	s.ErrorIs(switched.Index(s.ctx, editedFile()), index.ErrEmbeddingQueued)
+	s.Equal(1, switched.EmbeddingBacklog().Files)
+
+	embedderDown.Store(false)
+	other := testFile()
+	other.Path = "pkg/other.go"
+	for _, chunk := range other.Chunks {
+		chunk.File = other.Path
+	}
+	s.Require().NoError(switched.Index(s.ctx, other))
+
+	s.Require().Eventually(func() bool {
+		return switched.EmbeddingBacklog().Files == 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	chunk, err := switched.GetChunk(s.ctx, "pkg/config.go::ParseConfig")
+	s.Require().NoError(err)
+	s.Contains(chunk.Source, "loadConfig")
+}
+
+func (s *EmbedQueueTestSuite) TestDropsQueuedFilesThatCantBeStored() {
+	embedderDown.Store(true)
+	idx, err := openIndex(s.T(), s.ctx, s.workspaceRoot, index.Config{
+		Embedder: index.EmbedderConfig{Provider: "flaky"},
+		Store:    "sqlite",
+	})
+	s.Require().NoError(err)
+
+	// broken.go sorts before config.go, so it's retried first
+	broken := s.workspaceFile("pkg/broken.go", "Broken", "func Broken() {} // unembeddable")
+	config := s.workspaceFile("pkg/config.go", "ParseConfig", "func ParseConfig() {}")
+	s.ErrorIs(idx.Index(s.ctx, broken), index.ErrEmbeddingQueued)
+	s.ErrorIs(idx.Index(s.ctx, config), index.ErrEmbeddingQueued)
+
+	embedderDown.Store(false)
+	s.Require().NoError(idx.Index(s.ctx, s.workspaceFile("pkg/other.go", "Other", "func Other() {}")))
+
+	s.Require().Eventually(func() bool {
+		return idx.EmbeddingBacklog().Files == 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	chunk, err := idx.GetChunk(s.ctx, "pkg/config.go::ParseConfig")
+	s.Require().NoError(err)
+	s.Contains(chunk.Source, "ParseConfig")
+	s.False(idx.IsStale(s.ctx, "pkg/config.go"))
+
+	// The file that couldn't be stored is re-parsed on its next change
+	s.True(idx.IsStale(s.ctx, "pkg/broken.go"))
+}
+
+func (s *EmbedQueueTestSuite) TestCloseKeepsQueuedFiles() {
+	embedderDown.Store(true)
+	idx, err := openIndex(s.T(), s.ctx, s.workspaceRoot, index.Config{
+		Embedder: index.EmbedderConfig{Provider: "flaky"},
+		Store:    "sqlite",
+	})
+	s.Require().NoError(err)
+	s.ErrorIs(idx.Index(s.ctx, testFile()), index.ErrEmbeddingQueued)
+
+	s.Require().NoError(idx.Close())
+	s.Require().NoError(idx.Close())
+
+	// The store is closed along with the index
+	s.Error(idx.Index(s.ctx, editedFile()))
+
+	reopened, err := openIndex(s.T(), s.ctx, s.workspaceRoot, index.Config{
+		Embedder: index.EmbedderConfig{Provider: "flaky"},
+		Store:    "sqlite",
+	})
+	s.Require().NoError(err)
+	s.Equal(1, reopened.EmbeddingBacklog().Files)
+}
+
+// workspaceFile writes a file to the workspace and returns it parsed into a
+// single chunk with the given name
+func (s *EmbedQueueTestSuite) workspaceFile(path, name, source string) *parser.File {
+	fullPath := filepath.Join(s.workspaceRoot, path)
+	s.Require().NoError(os.MkdirAll(filepath.Dir(fullPath), 0o755))
+	s.Require().NoError(os.WriteFile(fullPath, []byte(source), 0o644))
+
+	return &parser.File{
+		Path:   path,
+		Source: []byte(source),
+		Chunks: []*parser.Chunk{{
+			File:      path,
+			Path:      name,
+			Type:      "src",
+			Summary:   source,
+			Source:    source,
+			StartLine: 1,
+			EndLine:   1,
+		}},
+	}
+}
+
+func TestEmbedQueueTestSuite(t *testing.T) {
+	suite.Run(t, new(EmbedQueueTestSuite))
+}
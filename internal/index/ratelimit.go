@@ -0,0 +1,160 @@
+package index
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// rateLimits pace the requests sent to one embedding provider
+type rateLimits struct {
+	RequestsPerSecond float64 // 0 for unlimited
+	Burst             int     // requests that can be sent at once after being idle
+	Concurrency       int     // requests in flight at once
+}
+
+// rateLimiter is a token bucket shared by every request to one provider
+// endpoint, which also caps how many requests are in flight. A 429 response
+// pauses it for every caller, not just the one that got it.
+type rateLimiter struct {
+	inFlight chan struct{}
+
+	mu          sync.Mutex
+	rate        float64
+	burst       float64
+	tokens      float64
+	last        time.Time
+	pausedUntil time.Time
+}
+
+// limiterKey identifies the clients that share a limiter. Clients of the same
+// endpoint configured with different limits get limiters of their own.
+type limiterKey struct {
+	endpoint string
+	limits   rateLimits
+}
+
+var (
+	limitersMu sync.Mutex
+	limiters   = map[limiterKey]*rateLimiter{}
+)
+
+// limiterFor returns the limiter shared by requests to an endpoint with the
+// given limits, creating it the first time
+func limiterFor(endpoint string, limits rateLimits) *rateLimiter {
+	limitersMu.Lock()
+	defer limitersMu.Unlock()
+
+	key := limiterKey{endpoint: endpoint, limits: limits}
+	limiter, exists := limiters[key]
+	if !exists {
+		limiter = newRateLimiter(limits)
+		limiters[key] = limiter
+	}
+
+	return limiter
+}
+
+func newRateLimiter(limits rateLimits) *rateLimiter {
+	burst := float64(max(limits.Burst, 1))
+	return &rateLimiter{
+		inFlight: make(chan struct{}, max(limits.Concurrency, 1)),
+		rate:     limits.RequestsPerSecond,
+		burst:    burst,
+		tokens:   burst,
+		last:     time.Now(),
+	}
+}
+
+// acquire waits until a request can be sent, the returned func must be
+// called once it's done
+func (l *rateLimiter) acquire(ctx context.Context) (func(), error) {
+	select {
+	case l.inFlight <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	release := func() { <-l.inFlight }
+	for {
+		wait := l.reserve(time.Now())
+		if wait <= 0 {
+			return release, nil
+		}
+
+		err := sleep(ctx, wait)
+		if err != nil {
+			release()
+			return nil, err
+		}
+	}
+}
+
+// reserve takes a token, or returns how long to wait before trying again
+func (l *rateLimiter) reserve(now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Before(l.pausedUntil) {
+		return l.pausedUntil.Sub(now)
+	}
+
+	if l.rate <= 0 {
+		return 0
+	}
+
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+
+	return time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+}
+
+// pause holds every request back until the provider accepts them again
+func (l *rateLimiter) pause(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.pausedUntil = later(l.pausedUntil, time.Now().Add(d))
+}
+
+// retryPolicy retries failed requests with exponential backoff and full
+// jitter, so clients that failed together don't retry together
+type retryPolicy struct {
+	attempts int
+	base     time.Duration
+	max      time.Duration
+}
+
+var defaultRetryPolicy = retryPolicy{attempts: 5, base: 500 * time.Millisecond, max: 30 * time.Second}
+
+// backoff returns how long to wait before retrying after the given attempt,
+// counting from 0
+func (p retryPolicy) backoff(attempt int) time.Duration {
+	ceiling := min(p.max, p.base<<min(attempt, 16))
+	return time.Duration(rand.Int64N(int64(ceiling) + 1))
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func later(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+
+	return b
+}
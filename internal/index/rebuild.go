@@ -0,0 +1,324 @@
+package index
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// probeTimeout is how long initialization waits for the embedder to tell the
+// dimensions of its embeddings
+const probeTimeout = 5 * time.Second
+
+// openCollections opens the collection for the configured embedder. If the
+// live collection was embedded by a different model, stored in another
+// format or vector store, or predates the manifest, it's kept around as the
+// source of a rebuild instead of being queried with incompatible vectors.
+func (idx *Index) openCollections(ctx context.Context) error {
+	spec := embeddingSpec{
+		Provider:     idx.embedder.Provider(),
+		Model:        idx.embedder.Model(),
+		Templates:    idx.templates.key,
+		Quantization: idx.quantization,
+	}
+
+	// A hung embedder mustn't block initialization, the dimensions are
+	// optional
+	probeCtx, cancel := context.WithTimeout(withSingleAttempt(ctx), probeTimeout)
+	probe, err := idx.embedder.Embed(probeCtx, "sourcerer")
+	cancel()
+	if err == nil {
+		spec.Dimensions = len(probe)
+	}
+
+	m, err := readManifest(idx.workspaceRoot)
+	if err != nil {
+		return err
+	}
+
+	name := spec.collection()
+	previous := legacyCollection
+	if m != nil {
+		previous = m.Collection
+		if m.matches(spec) {
+			name = m.Collection
+			spec.Dimensions = cmp.Or(spec.Dimensions, m.Dimensions)
+		}
+	}
+
+	// Look the previous collection up before opening the live one, which can
+	// have the same name if only the vector store changed
+	old, oldDB := idx.findCollection(previous)
+
+	collection, err := idx.db.Open(name, spec.metadata())
+	if err != nil {
+		if oldDB != nil && oldDB != idx.db {
+			oldDB.Close()
+		}
+
+		return fmt.Errorf(
+			"failed to create vector db collection (embedder: %s, model: %s): %w",
+			spec.Provider, spec.Model, err,
+		)
+	}
+
+	idx.collection = collection
+	idx.spec = spec
+
+	if old != nil && old.Count() > 0 && (previous != name || oldDB != idx.db) {
+		fmt.Fprintf(
+			os.Stderr,
+			"Sourcerer: index was built with a different embedding model or storage, rebuilding it with %s/%s\n",
+			spec.Provider, spec.Model,
+		)
+
+		// Embeddings are re-embedded if storing them again would gain precision
+		idx.reuseEmbeddings = m != nil && m.sameSpace(spec) &&
+			quantizationRank(spec.Quantization) >= quantizationRank(m.Quantization)
+		idx.previousDB = oldDB
+		idx.previous.Store(&old)
+		return nil
+	}
+
+	// Drop collections left behind by earlier models
+	for _, other := range idx.db.Names() {
+		if other == name {
+			continue
+		}
+
+		err = idx.db.Drop(other)
+		if err != nil {
+			return fmt.Errorf("failed to delete stale vector db collection: %w", err)
+		}
+	}
+
+	return writeManifest(idx.workspaceRoot, &manifest{Collection: name, embeddingSpec: spec})
+}
+
+// findCollection returns a collection and the db holding it, looking in the
+// dbs other vector store backends left in .sourcerer if the configured one
+// doesn't have it
+func (idx *Index) findCollection(name string) (VectorStore, VectorDB) {
+	store := idx.db.Get(name)
+	if store != nil {
+		return store, idx.db
+	}
+
+	var found VectorDB
+	for _, db := range otherVectorDBs(idx.storeName, filepath.Join(idx.workspaceRoot, ".sourcerer")) {
+		if found == nil {
+			store = db.Get(name)
+			if store != nil {
+				found = db
+				continue
+			}
+		}
+
+		db.Close()
+	}
+
+	return store, found
+}
+
+// previousStore returns the collection being rebuilt from, nil if there's none
+func (idx *Index) previousStore() VectorStore {
+	previous := idx.previous.Load()
+	if previous == nil {
+		return nil
+	}
+
+	return *previous
+}
+
+// Rebuilding reports whether chunks embedded by a previous model are still
+// being re-embedded
+func (idx *Index) Rebuilding() bool {
+	return idx.previous.Load() != nil
+}
+
+// rebuild re-embeds the previously live collection into the current one file
+// by file, then makes the current collection live. Files indexed meanwhile
+// are migrated by Index itself, and an interrupted rebuild resumes on the
+// next start since migrated files are no longer in the previous collection.
+// Files that can't be embedded while the embedder is down are queued, and the
+// rebuild finishes once the queue has migrated them.
+func (idx *Index) rebuild(ctx context.Context) {
+	previous := idx.previousStore()
+	if previous == nil {
+		return
+	}
+
+	docs, err := previous.List(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Sourcerer: failed to rebuild index: %v\n", err)
+		return
+	}
+
+	files := map[string]bool{}
+	for _, doc := range docs {
+		files[doc.Metadata["file"]] = true
+	}
+
+	for filePath := range files {
+		idx.writeMu.Lock()
+		err = idx.migrate(ctx, filePath)
+		if err != nil && ctx.Err() == nil {
+			err = idx.queueMigration(ctx, filePath, err)
+		}
+		idx.writeMu.Unlock()
+
+		if ctx.Err() != nil {
+			return // closed, the rebuild resumes on the next start
+		}
+
+		if err != nil && !errors.Is(err, ErrEmbeddingQueued) {
+			fmt.Fprintf(os.Stderr, "Sourcerer: failed to rebuild index: %v\n", err)
+			return
+		}
+	}
+
+	idx.finishRebuild()
+}
+
+// queueMigration queues the stored chunks of a file that couldn't be
+// migrated because of err, unless a newer version of the file is queued
+// already. The caller must hold writeMu.
+func (idx *Index) queueMigration(ctx context.Context, filePath string, err error) error {
+	if idx.queue.get(filePath) != nil {
+		return fmt.Errorf("%w: %w", ErrEmbeddingQueued, err)
+	}
+
+	stored, getErr := idx.previousStore().GetWhere(ctx, map[string]string{"file": filePath})
+	if getErr != nil {
+		return err
+	}
+
+	docs := make([]Document, 0, len(stored))
+	for _, doc := range stored {
+		docs = append(docs, *doc)
+	}
+
+	// Files without a recorded state are stale and get re-parsed anyway
+	idx.cacheMu.RLock()
+	state := idx.cache[filePath]
+	idx.cacheMu.RUnlock()
+
+	return idx.enqueue(filePath, state, docs, err)
+}
+
+// finishRebuild makes the current collection live and drops the previous one
+// once every file was migrated out of it
+func (idx *Index) finishRebuild() {
+	idx.writeMu.Lock()
+	defer idx.writeMu.Unlock()
+
+	previous := idx.previousStore()
+	if previous == nil || previous.Count() > 0 {
+		return
+	}
+
+	err := writeManifest(idx.workspaceRoot, &manifest{Collection: idx.collection.Name(), embeddingSpec: idx.spec})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Sourcerer: failed to rebuild index: %v\n", err)
+		return
+	}
+
+	idx.previous.Store(nil)
+
+	err = idx.previousDB.Drop(previous.Name())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Sourcerer: failed to delete previous index: %v\n", err)
+	}
+}
+
+// migrate moves a file's chunks from the previous collection into the current
+// one, re-embedding them with the current embedder unless their embeddings
+// can be reused. The caller must hold writeMu.
+func (idx *Index) migrate(ctx context.Context, filePath string) error {
+	previous := idx.previousStore()
+	if previous == nil {
+		return nil
+	}
+
+	where := map[string]string{"file": filePath}
+	stored, err := previous.GetWhere(ctx, where)
+	if err != nil {
+		return fmt.Errorf("failed to look up documents in vector db: %w", err)
+	}
+
+	if len(stored) == 0 {
+		return nil
+	}
+
+	docs := make([]Document, 0, len(stored))
+	for _, doc := range stored {
+		docs = append(docs, *doc)
+	}
+
+	// Documents whose stored code was malformed are embedded again too
+	var unembedded []Document
+	var positions []int
+	for i := range docs {
+		if !idx.reuseEmbeddings {
+			docs[i].Embedding = nil
+		}
+
+		if len(docs[i].Embedding) == 0 {
+			unembedded = append(unembedded, docs[i])
+			positions = append(positions, i)
+		}
+	}
+
+	if len(unembedded) > 0 {
+		err = idx.embedDocuments(ctx, unembedded)
+		if err != nil {
+			return fmt.Errorf("failed to embed documents: %w", err)
+		}
+
+		for i, position := range positions {
+			docs[position] = unembedded[i]
+		}
+	}
+
+	err = idx.collection.Add(ctx, docs)
+	if err != nil {
+		return fmt.Errorf("failed to add documents to vector db: %w", err)
+	}
+
+	err = previous.Delete(ctx, where)
+	if err != nil {
+		return fmt.Errorf("failed to remove documents from vector db: %w", err)
+	}
+
+	return nil
+}
+
+// document returns a stored chunk, falling back to the previous collection
+// while a rebuild is in progress. Chunks that weren't migrated yet are
+// returned without an embedding if theirs came from another model.
+func (idx *Index) document(ctx context.Context, id string) (Document, error) {
+	doc, err := idx.collection.Get(ctx, id)
+	if err == nil {
+		return doc, nil
+	}
+
+	previous := idx.previousStore()
+	if previous == nil {
+		return Document{}, err
+	}
+
+	doc, err = previous.Get(ctx, id)
+	if err != nil {
+		return Document{}, err
+	}
+
+	if !idx.reuseEmbeddings {
+		doc.Embedding = nil
+	}
+
+	return doc, nil
+}
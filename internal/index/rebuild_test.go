@@ -0,0 +1,218 @@
+package index_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/philippgille/chromem-go"
+	"github.com/st3v3nmw/sourcerer-mcp/internal/index"
+	"github.com/stretchr/testify/suite"
+)
+
+type RebuildTestSuite struct {
+	suite.Suite
+	ctx           context.Context
+	workspaceRoot string
+}
+
+func (s *RebuildTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.workspaceRoot = s.T().TempDir()
+}
+
+func (s *RebuildTestSuite) open(provider string) *index.Index {
+	idx, err := openIndex(s.T(), s.ctx, s.workspaceRoot, index.Config{
+		Embedder: index.EmbedderConfig{Provider: provider},
+	})
+	s.Require().NoError(err)
+
+	return idx
+}
+
+func (s *RebuildTestSuite) waitForRebuild(idx *index.Index) {
+	s.Require().Eventually(func() bool {
+		return !idx.Rebuilding()
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func (s *RebuildTestSuite) manifest() map[string]any {
+	data, err := os.ReadFile(filepath.Join(s.workspaceRoot, ".sourcerer", "manifest.json"))
+	s.Require().NoError(err)
+
+	var m map[string]any
+	s.Require().NoError(json.Unmarshal(data, &m))
+
+	return m
+}
+
+func (s *RebuildTestSuite) collections() []string {
+	entries, err := os.ReadDir(filepath.Join(s.workspaceRoot, ".sourcerer", "db"))
+	s.Require().NoError(err)
+
+	var dirs []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			dirs = append(dirs, entry.Name())
+		}
+	}
+
+	return dirs
+}
+
+func (s *RebuildTestSuite) TestManifestRecordsEmbeddingModel() {
+	s.open("test")
+
+	m := s.manifest()
+	s.Equal("test", m["provider"])
+	s.Equal("bow-256", m["model"])
+	s.Equal(float64(256), m["dimensions"])
+}
+
+func (s *RebuildTestSuite) TestSameModelReusesCollection() {
+	idx := s.open("test")
+	s.Require().NoError(idx.Index(s.ctx, testFile()))
+	s.Require().NoError(idx.Close())
+
+	reopened := s.open("test")
+	s.False(reopened.Rebuilding())
+
+	_, err := reopened.GetChunk(s.ctx, "pkg/config.go::ParseConfig")
+	s.NoError(err)
+	s.Len(s.collections(), 1)
+}
+
+func (s *RebuildTestSuite) TestModelSwitchRebuildsIndex() {
+	idx := s.open("test")
+	s.Require().NoError(idx.Index(s.ctx, testFile()))
+	s.Require().NoError(idx.Close())
+
+	switched := s.open("lexical")
+	s.waitForRebuild(switched)
+
+	s.Equal("lexical", s.manifest()["provider"])
+	s.Len(s.collections(), 1)
+
+	results, err := switched.Search(s.ctx, "watcher flush pending", index.SearchOptions{
+		FileTypes: []string{"src"},
+		Mode:      index.SearchSemantic,
+	})
+	s.Require().NoError(err)
+	s.Require().NotEmpty(results)
+	s.Contains(results[0], "pkg/config.go::FlushPending")
+
+	similar, err := switched.FindSimilarChunks(s.ctx, "pkg/config.go::ParseConfig", index.SearchOptions{})
+	s.NoError(err)
+	s.NotNil(similar)
+}
+
+func (s *RebuildTestSuite) TestRebuildQueuesFilesWhileEmbedderIsDown() {
+	idx := s.open("flaky")
+	s.Require().NoError(idx.Index(s.ctx, testFile()))
+	s.Require().NoError(idx.Close())
+
+	embedderDown.Store(true)
+	defer embedderDown.Store(false)
+
+	switched := s.open("flaky-lexical")
+	s.Require().Eventually(func() bool {
+		return switched.EmbeddingBacklog().Files == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// The previous collection is kept until the queued file is migrated
+	s.True(switched.Rebuilding())
+	s.Equal("test", s.manifest()["provider"])
+
+	embedderDown.Store(false)
+	other := testFile()
+	other.Path = "pkg/other.go"
+	for _, chunk := range other.Chunks {
+		chunk.File = other.Path
+	}
+	s.Require().NoError(switched.Index(s.ctx, other))
+	s.waitForRebuild(switched)
+
+	s.Equal("lexical", s.manifest()["provider"])
+	s.Len(s.collections(), 1)
+
+	chunk, err := switched.GetChunk(s.ctx, "pkg/config.go::ParseConfig")
+	s.Require().NoError(err)
+	s.Contains(chunk.Source, "readConfig")
+}
+
+func (s *RebuildTestSuite) TestLegacyCollectionIsRebuilt() {
+	db, err := chromem.NewPersistentDB(filepath.Join(s.workspaceRoot, ".sourcerer", "db"), false)
+	s.Require().NoError(err)
+
+	legacy, err := db.CreateCollection("code-chunks", nil, nil)
+	s.Require().NoError(err)
+	s.Require().NoError(legacy.AddDocument(s.ctx, chromem.Document{
+		ID:        "main.go::main",
+		Metadata:  map[string]string{"file": "main.go", "type": "src", "path": "main"},
+		Content:   "func main() {}",
+		Embedding: []float32{1, 0, 0},
+	}))
+
+	idx := s.open("test")
+	s.waitForRebuild(idx)
+
+	chunk, err := idx.GetChunk(s.ctx, "main.go::main")
+	s.Require().NoError(err)
+	s.Equal("func main() {}", chunk.Source)
+	s.NotContains(s.collections(), "code-chunks")
+}
+
+func (s *RebuildTestSuite) TestQuantizationMigratesCollection() {
+	idx := s.open("test")
+	s.Require().NoError(idx.Index(s.ctx, testFile()))
+	s.Require().NoError(idx.Close())
+
+	for _, quantization := range []string{index.QuantizationInt8, index.QuantizationBinary} {
+		quantized, err := openIndex(s.T(), s.ctx, s.workspaceRoot, index.Config{
+			Embedder:     index.EmbedderConfig{Provider: "test"},
+			Quantization: quantization,
+		})
+		s.Require().NoError(err)
+		s.waitForRebuild(quantized)
+
+		s.Equal(quantization, s.manifest()["quantization"])
+		s.Empty(s.collections())
+
+		_, err = quantized.GetChunk(s.ctx, "pkg/config.go::ParseConfig")
+		s.NoError(err)
+
+		// Binary codes of bag-of-words vectors only rank, their similarities
+		// are meaningless
+		results, err := quantized.Search(s.ctx, "watcher flush pending", index.SearchOptions{
+			FileTypes: []string{"src"},
+			Mode:      index.SearchSemantic,
+			MinScore:  -1,
+		})
+		s.Require().NoError(err)
+		s.Require().NotEmpty(results)
+		s.Contains(results[0], "pkg/config.go::FlushPending")
+		s.Require().NoError(quantized.Close())
+	}
+}
+
+func (s *RebuildTestSuite) TestQuantizationNeedsSQLite() {
+	_, err := openIndex(s.T(), s.ctx, s.workspaceRoot, index.Config{
+		Embedder:     index.EmbedderConfig{Provider: "test"},
+		Store:        "chromem",
+		Quantization: index.QuantizationInt8,
+	})
+	s.Error(err)
+
+	_, err = openIndex(s.T(), s.ctx, s.workspaceRoot, index.Config{
+		Embedder:     index.EmbedderConfig{Provider: "test"},
+		Quantization: "int4",
+	})
+	s.Error(err)
+}
+
+func TestRebuildTestSuite(t *testing.T) {
+	suite.Run(t, new(RebuildTestSuite))
+}
@@ -0,0 +1,344 @@
+package index
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/st3v3nmw/sourcerer-mcp/internal/parser"
+)
+
+// maxRerankCandidates caps how many retrieved chunks are sent to a reranker
+const maxRerankCandidates = 100
+
+// RerankCandidate is a retrieved chunk handed to a Reranker
+type RerankCandidate struct {
+	ID      string
+	File    string
+	Path    string // path within the file
+	Type    string
+	Content string
+	Score   float32 // retrieval score, only comparable within one search
+}
+
+// Reranker re-scores the chunks retrieved for a query. It runs on an
+// over-fetched candidate list so it can pull relevant chunks the first
+// retrieval stage ranked too low into the returned page.
+type Reranker interface {
+	Name() string
+	// Rerank returns a score per candidate, higher is more relevant
+	Rerank(ctx context.Context, query string, candidates []RerankCandidate) ([]float32, error)
+}
+
+// RerankerConfig selects and configures a reranker
+type RerankerConfig struct {
+	Provider string // registered reranker name, empty disables reranking
+	Model    string // model name sent to HTTP rerank endpoints
+	BaseURL  string // HTTP rerank endpoint
+	APIKey   string
+}
+
+// RerankerFactory creates a Reranker from its configuration
+type RerankerFactory func(cfg RerankerConfig) (Reranker, error)
+
+var rerankers = map[string]RerankerFactory{
+	"heuristic": func(cfg RerankerConfig) (Reranker, error) {
+		return &HeuristicReranker{}, nil
+	},
+	"http": func(cfg RerankerConfig) (Reranker, error) {
+		if cfg.BaseURL == "" {
+			return nil, errors.New("http reranker requires SOURCERER_RERANK_URL")
+		}
+
+		return NewHTTPReranker(cfg.BaseURL, cfg.Model, cfg.APIKey), nil
+	},
+}
+
+// NewReranker creates the Reranker for the configured provider, or nil when
+// reranking is disabled
+func NewReranker(cfg RerankerConfig) (Reranker, error) {
+	if cfg.Provider == "" {
+		return nil, nil
+	}
+
+	factory, exists := rerankers[cfg.Provider]
+	if !exists {
+		return nil, fmt.Errorf("unknown reranker %q (available: heuristic, http)", cfg.Provider)
+	}
+
+	return factory(cfg)
+}
+
+// RerankerConfigFromEnv reads the reranker configuration from the environment
+func RerankerConfigFromEnv() RerankerConfig {
+	return RerankerConfig{
+		Provider: os.Getenv("SOURCERER_RERANKER"),
+		Model:    os.Getenv("SOURCERER_RERANK_MODEL"),
+		BaseURL:  os.Getenv("SOURCERER_RERANK_URL"),
+		APIKey:   os.Getenv("SOURCERER_RERANK_API_KEY"),
+	}
+}
+
+// rerank re-orders candidates by the reranker's scores. Candidates beyond
+// maxRerankCandidates keep their order after the reranked ones.
+func (idx *Index) rerank(ctx context.Context, query string, candidates []rankedDoc) ([]rankedDoc, error) {
+	if idx.reranker == nil || len(candidates) == 0 {
+		return candidates, nil
+	}
+
+	n := min(len(candidates), maxRerankCandidates)
+	input := make([]RerankCandidate, n)
+	for i, candidate := range candidates[:n] {
+		input[i] = RerankCandidate{
+			ID:      candidate.doc.ID,
+			File:    candidate.doc.Metadata["file"],
+			Path:    candidate.doc.Metadata["path"],
+			Type:    candidate.doc.Metadata["type"],
+			Content: candidate.doc.Content,
+			Score:   candidate.score,
+		}
+	}
+
+	// Searches keep the retrieval order if the reranker fails, so like the
+	// query's embedding it gets a single attempt within the query timeout
+	ctx, cancel := context.WithTimeout(withSingleAttempt(ctx), idx.queryTimeout)
+	defer cancel()
+
+	scores, err := idx.reranker.Rerank(ctx, query, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rerank results with %s: %w", idx.reranker.Name(), err)
+	}
+
+	if len(scores) != n {
+		return nil, fmt.Errorf("reranker %s returned %d scores for %d candidates", idx.reranker.Name(), len(scores), n)
+	}
+
+	reranked := append([]rankedDoc(nil), candidates[:n]...)
+	for i := range reranked {
+		reranked[i].score = scores[i]
+	}
+
+	sort.SliceStable(reranked, func(i, j int) bool {
+		return reranked[i].score > reranked[j].score
+	})
+
+	// Scores past the reranked candidates aren't comparable, keep them last
+	lowest := reranked[n-1].score
+	for _, candidate := range candidates[n:] {
+		candidate.score = lowest
+		reranked = append(reranked, candidate)
+	}
+
+	return reranked, nil
+}
+
+const (
+	heuristicRetrievalWeight  = 0.4
+	heuristicIdentifierWeight = 0.3
+	heuristicPathWeight       = 0.1
+	// Bonus for queries naming the chunk's symbol, the strongest signal there is
+	heuristicNameBonus = 0.2
+	// Penalty for chunks named by a content hash (imports, loose statements)
+	// rather than by the declaration they hold
+	heuristicAnonymousPenalty = 0.05
+)
+
+// heuristicTypeBoosts favors implementations and project memory over docs and
+// tests when they're otherwise equally relevant
+var heuristicTypeBoosts = map[string]float32{
+	"src":    0.1,
+	"memory": 0.1,
+	"docs":   0.05,
+}
+
+// HeuristicReranker is an offline reranker that combines the retrieval score
+// with identifier overlap between the query and the chunk, how well the query
+// matches the chunk's file path, and boosts by chunk type
+type HeuristicReranker struct{}
+
+func (r *HeuristicReranker) Name() string {
+	return "heuristic"
+}
+
+func (r *HeuristicReranker) Rerank(_ context.Context, query string, candidates []RerankCandidate) ([]float32, error) {
+	queryTerms := termSet(query)
+
+	var minScore, maxScore float32
+	for i, candidate := range candidates {
+		if i == 0 || candidate.Score < minScore {
+			minScore = candidate.Score
+		}
+		maxScore = max(maxScore, candidate.Score)
+	}
+
+	scores := make([]float32, len(candidates))
+	for i, candidate := range candidates {
+		retrieval := float32(1)
+		if maxScore > minScore {
+			retrieval = (candidate.Score - minScore) / (maxScore - minScore)
+		}
+
+		score := heuristicRetrievalWeight*retrieval +
+			heuristicIdentifierWeight*overlap(queryTerms, termSet(candidate.Path+"\n"+candidate.Content)) +
+			heuristicPathWeight*overlap(queryTerms, termSet(candidate.File)) +
+			heuristicTypeBoosts[candidate.Type]
+
+		name := chunkName(candidate.Path)
+		if isContentHash(name) {
+			score -= heuristicAnonymousPenalty
+		} else if overlap(termSet(name), queryTerms) == 1 {
+			score += heuristicNameBonus
+		}
+
+		scores[i] = score
+	}
+
+	return scores, nil
+}
+
+func termSet(text string) map[string]bool {
+	set := map[string]bool{}
+	for _, term := range terms(text) {
+		set[term] = true
+	}
+
+	return set
+}
+
+// overlap is the fraction of the terms in a that are also in b
+func overlap(a, b map[string]bool) float32 {
+	if len(a) == 0 {
+		return 0
+	}
+
+	shared := 0
+	for term := range a {
+		if b[term] {
+			shared++
+		}
+	}
+
+	return float32(shared) / float32(len(a))
+}
+
+// chunkName returns the last segment of a chunk path without the suffixes
+// of split parts and of duplicate names, e.g. Search for Index::Search#part2
+func chunkName(path string) string {
+	name := path
+	i := strings.LastIndex(path, "::")
+	if i >= 0 {
+		name = path[i+len("::"):]
+	}
+	name, _, _ = strings.Cut(name, parser.PartSeparator)
+
+	i = strings.LastIndex(name, "-")
+	if i > 0 && i < len(name)-1 && strings.Trim(name[i+1:], "0123456789") == "" {
+		return name[:i]
+	}
+
+	return name
+}
+
+// isContentHash reports whether a chunk path is the hex content hash the
+// parser names unnamed chunks with
+func isContentHash(path string) bool {
+	if len(path) != 16 {
+		return false
+	}
+
+	for _, r := range path {
+		if !strings.ContainsRune("0123456789abcdef", r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// HTTPReranker calls a rerank endpoint in the format shared by Cohere, Jina,
+// llama.cpp and similar servers:
+//
+//	POST {"model": ..., "query": ..., "documents": [...]}
+//	  -> {"results": [{"index": 0, "relevance_score": 0.9}, ...]}
+type HTTPReranker struct {
+	url    string
+	model  string
+	apiKey string
+	client *embedClient
+}
+
+// rerankLimits pace the requests sent to a rerank endpoint
+var rerankLimits = rateLimits{RequestsPerSecond: 10, Burst: 4, Concurrency: 4}
+
+func NewHTTPReranker(url, model, apiKey string) *HTTPReranker {
+	return &HTTPReranker{
+		url:    url,
+		model:  model,
+		apiKey: apiKey,
+		client: &embedClient{
+			// Searches wait for the reranker, so it gets less time than embedding
+			http:    &http.Client{Timeout: 30 * time.Second},
+			limiter: limiterFor("rerank "+url, rerankLimits),
+			retry:   defaultRetryPolicy,
+		},
+	}
+}
+
+func (r *HTTPReranker) Name() string {
+	return "http"
+}
+
+type rerankRequest struct {
+	Model     string   `json:"model,omitempty"`
+	Query     string   `json:"query"`
+	Documents []string `json:"documents"`
+}
+
+type rerankResponse struct {
+	Results []struct {
+		Index          int     `json:"index"`
+		RelevanceScore float64 `json:"relevance_score"`
+	} `json:"results"`
+}
+
+func (r *HTTPReranker) Rerank(ctx context.Context, query string, candidates []RerankCandidate) ([]float32, error) {
+	documents := make([]string, len(candidates))
+	for i, candidate := range candidates {
+		// The ID carries the file and symbol names the query may refer to
+		documents[i] = candidate.ID + "\n" + candidate.Content
+	}
+
+	var result rerankResponse
+	err := r.client.post(ctx, r.url, r.apiKey, rerankRequest{Model: r.model, Query: query, Documents: documents}, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	scores := make([]float32, len(candidates))
+	scored := make([]bool, len(candidates))
+	lowest := float32(math.MaxFloat32)
+	for _, ranked := range result.Results {
+		if ranked.Index < 0 || ranked.Index >= len(candidates) {
+			return nil, fmt.Errorf("rerank endpoint returned unknown index %d", ranked.Index)
+		}
+
+		scores[ranked.Index] = float32(ranked.RelevanceScore)
+		scored[ranked.Index] = true
+		lowest = min(lowest, scores[ranked.Index])
+	}
+
+	// Candidates the endpoint left out (e.g. because of top_n) rank last
+	for i := range scores {
+		if !scored[i] {
+			scores[i] = lowest - 1
+		}
+	}
+
+	return scores, nil
+}
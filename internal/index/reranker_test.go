@@ -0,0 +1,220 @@
+package index_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/st3v3nmw/sourcerer-mcp/internal/index"
+	"github.com/stretchr/testify/suite"
+)
+
+type RerankerTestSuite struct {
+	suite.Suite
+	ctx context.Context
+}
+
+func (s *RerankerTestSuite) SetupTest() {
+	s.ctx = context.Background()
+}
+
+func (s *RerankerTestSuite) newIndex(reranker index.RerankerConfig) *index.Index {
+	idx, err := openIndex(s.T(), s.ctx, s.T().TempDir(), index.Config{
+		Embedder: index.EmbedderConfig{Provider: "test"},
+		Reranker: reranker,
+	})
+	s.Require().NoError(err)
+	s.Require().NoError(idx.Index(s.ctx, testFile()))
+
+	return idx
+}
+
+// reversingServer is a stand-in rerank endpoint that scores documents in
+// reverse of the order they were sent in
+func (s *RerankerTestSuite) reversingServer(requests *[]map[string]any) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request map[string]any
+		s.NoError(json.NewDecoder(r.Body).Decode(&request))
+		s.Equal("Bearer secret", r.Header.Get("Authorization"))
+		*requests = append(*requests, request)
+
+		documents := request["documents"].([]any)
+		results := []map[string]any{}
+		for i := range documents {
+			results = append(results, map[string]any{"index": i, "relevance_score": float64(i)})
+		}
+
+		s.NoError(json.NewEncoder(w).Encode(map[string]any{"results": results}))
+	}))
+	s.T().Cleanup(server.Close)
+
+	return server
+}
+
+func (s *RerankerTestSuite) TestHTTPRerankerReordersResults() {
+	opts := index.SearchOptions{FileTypes: []string{"src"}, Mode: index.SearchLexical}
+
+	plain, err := s.newIndex(index.RerankerConfig{}).Search(s.ctx, "func", opts)
+	s.Require().NoError(err)
+	s.Require().Len(plain, 2)
+
+	var requests []map[string]any
+	server := s.reversingServer(&requests)
+	idx := s.newIndex(index.RerankerConfig{
+		Provider: "http",
+		Model:    "rerank-test",
+		BaseURL:  server.URL,
+		APIKey:   "secret",
+	})
+
+	reranked, err := idx.Search(s.ctx, "func", opts)
+	s.Require().NoError(err)
+	s.Equal([]string{plain[1], plain[0]}, reranked)
+
+	s.Require().Len(requests, 1)
+	s.Equal("rerank-test", requests[0]["model"])
+	s.Equal("func", requests[0]["query"])
+}
+
+func (s *RerankerTestSuite) TestFailingRerankerKeepsRetrievalOrder() {
+	opts := index.SearchOptions{FileTypes: []string{"src"}, Mode: index.SearchLexical}
+
+	plain, err := s.newIndex(index.RerankerConfig{}).Search(s.ctx, "func", opts)
+	s.Require().NoError(err)
+	s.Require().NotEmpty(plain)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "model not found", http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	idx := s.newIndex(index.RerankerConfig{Provider: "http", BaseURL: server.URL})
+	results, err := idx.Search(s.ctx, "func", opts)
+	s.Require().NoError(err)
+	s.Equal(plain, results)
+}
+
+func (s *RerankerTestSuite) TestSearchDoesNotWaitForRerankerRetries() {
+	opts := index.SearchOptions{FileTypes: []string{"src"}, Mode: index.SearchLexical}
+
+	plain, err := s.newIndex(index.RerankerConfig{}).Search(s.ctx, "func", opts)
+	s.Require().NoError(err)
+
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		http.Error(w, "model loading", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	idx := s.newIndex(index.RerankerConfig{Provider: "http", BaseURL: server.URL})
+	start := time.Now()
+	results, err := idx.Search(s.ctx, "func", opts)
+	s.Require().NoError(err)
+	s.Equal(plain, results)
+	s.Less(time.Since(start), time.Second)
+	s.Equal(int32(1), attempts.Load())
+}
+
+func (s *RerankerTestSuite) TestSearchDoesNotWaitForHungReranker() {
+	opts := index.SearchOptions{FileTypes: []string{"src"}, Mode: index.SearchLexical}
+
+	plain, err := s.newIndex(index.RerankerConfig{}).Search(s.ctx, "func", opts)
+	s.Require().NoError(err)
+
+	hung := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-hung:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(hung)
+
+	idx, err := openIndex(s.T(), s.ctx, s.T().TempDir(), index.Config{
+		Embedder:     index.EmbedderConfig{Provider: "test"},
+		Reranker:     index.RerankerConfig{Provider: "http", BaseURL: server.URL},
+		QueryTimeout: 200 * time.Millisecond,
+	})
+	s.Require().NoError(err)
+	s.Require().NoError(idx.Index(s.ctx, testFile()))
+
+	start := time.Now()
+	results, err := idx.Search(s.ctx, "func", opts)
+	s.Require().NoError(err)
+	s.Equal(plain, results)
+	s.Less(time.Since(start), 2*time.Second)
+}
+
+func (s *RerankerTestSuite) TestHTTPRerankerRetriesTransientErrors() {
+	var requests []map[string]any
+	reversing := s.reversingServer(&requests)
+
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			http.Error(w, "model loading", http.StatusServiceUnavailable)
+			return
+		}
+
+		reversing.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	reranker, err := index.NewReranker(index.RerankerConfig{Provider: "http", BaseURL: server.URL, APIKey: "secret"})
+	s.Require().NoError(err)
+
+	scores, err := reranker.Rerank(s.ctx, "func", []index.RerankCandidate{{ID: "a"}, {ID: "b"}})
+	s.Require().NoError(err)
+	s.Equal([]float32{0, 1}, scores)
+	s.Equal(int32(2), attempts.Load())
+}
+
+func (s *RerankerTestSuite) TestHeuristicRerankerBoostsNamedChunk() {
+	reranker, err := index.NewReranker(index.RerankerConfig{Provider: "heuristic"})
+	s.Require().NoError(err)
+
+	scores, err := reranker.Rerank(s.ctx, "ParseConfig", []index.RerankCandidate{
+		{ID: "pkg/config.go::Usage", File: "pkg/config.go", Path: "Usage", Type: "docs", Score: 0.9},
+		{ID: "pkg/config.go::ParseConfig", File: "pkg/config.go", Path: "ParseConfig", Type: "src", Score: 0.5},
+	})
+	s.Require().NoError(err)
+	s.Require().Len(scores, 2)
+	s.Greater(scores[1], scores[0])
+}
+
+func (s *RerankerTestSuite) TestHeuristicRerankerNamesNestedChunks() {
+	reranker, err := index.NewReranker(index.RerankerConfig{Provider: "heuristic"})
+	s.Require().NoError(err)
+
+	scores, err := reranker.Rerank(s.ctx, "Search", []index.RerankCandidate{
+		{ID: "pkg/index.go::Index::Lookup", File: "pkg/index.go", Path: "Index::Lookup", Type: "src", Score: 0.5},
+		{ID: "pkg/index.go::Index::Search#part2", File: "pkg/index.go", Path: "Index::Search#part2", Type: "src", Score: 0.5},
+		{ID: "pkg/index.go::Index::0123456789abcdef-2", File: "pkg/index.go", Path: "Index::0123456789abcdef-2", Type: "src", Score: 0.5},
+	})
+	s.Require().NoError(err)
+	s.Require().Len(scores, 3)
+	s.Greater(scores[1], scores[0])
+	s.Less(scores[2], scores[0])
+}
+
+func (s *RerankerTestSuite) TestNewReranker() {
+	reranker, err := index.NewReranker(index.RerankerConfig{})
+	s.Require().NoError(err)
+	s.Nil(reranker)
+
+	_, err = index.NewReranker(index.RerankerConfig{Provider: "cross-encoder"})
+	s.Error(err)
+
+	_, err = index.NewReranker(index.RerankerConfig{Provider: "http"})
+	s.Error(err)
+}
+
+func TestRerankerTestSuite(t *testing.T) {
+	suite.Run(t, new(RerankerTestSuite))
+}
@@ -0,0 +1,114 @@
+package index
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+const (
+	// rrfK dampens the influence of top ranks in reciprocal rank fusion; 60 is
+	// the value from the original RRF paper and works well without tuning
+	rrfK = 60
+
+	// Results returned by searches and similarity lookups without a limit
+	DefaultSearchLimit  = 5
+	DefaultSimilarLimit = 10
+	// MaxLimit caps the results returned by one search to protect the
+	// caller's token budget
+	MaxLimit = 50
+	// maxDepth caps how far offset can page into a ranking
+	maxDepth = 200
+	// Each retriever contributes this many candidates per requested result
+	// to the fused ranking
+	candidatesPerResult = 4
+)
+
+// ErrDegraded is returned by Search along with keyword matches when the query
+// couldn't be embedded, e.g. while the embedding provider is down
+var ErrDegraded = errors.New("embedding provider unavailable, results are keyword matches only")
+
+// SearchMode selects which retrievers a search uses
+type SearchMode string
+
+const (
+	SearchSemantic SearchMode = "semantic" // vector similarity only
+	SearchLexical  SearchMode = "lexical"  // BM25 keyword matching only
+	SearchHybrid   SearchMode = "hybrid"   // both, fused by rank
+)
+
+// ParseSearchMode validates a search mode, defaulting to hybrid
+func ParseSearchMode(mode string) (SearchMode, error) {
+	switch SearchMode(mode) {
+	case "":
+		return SearchHybrid, nil
+	case SearchSemantic, SearchLexical, SearchHybrid:
+		return SearchMode(mode), nil
+	default:
+		return "", fmt.Errorf("unknown search mode %q", mode)
+	}
+}
+
+// SearchOptions controls what a search returns
+type SearchOptions struct {
+	FileTypes []string   // file types to search, defaults to src and docs
+	Mode      SearchMode // retrieval mode, defaults to hybrid
+	Limit     int        // results to return, capped at MaxLimit
+	Offset    int        // results to skip, to page through the ranking
+	Include   []string   // doublestar globs, chunk files must match one of them
+	Exclude   []string   // doublestar globs, chunk files must match none of them
+	Languages []string   // languages chunks must be written in
+	Directory string     // directory, relative to the workspace, chunk files must be in
+	// MinScore is the semantic similarity (0-1) a chunk needs to be returned,
+	// replacing the embedder's default cut-off. Keyword matches aren't
+	// affected by it.
+	MinScore float32
+	// Lambda re-ranks results with maximal marginal relevance when it's
+	// between 0 and 1: lower values favor diverse results over relevant ones
+	Lambda float32
+}
+
+// diversify reports whether lambda enables MMR re-ranking
+func diversify(lambda float32) bool {
+	return lambda > 0 && lambda < 1
+}
+
+// window returns the offset and limit to apply, using defaultLimit when no
+// limit was given and keeping both within the server-side caps
+func (o SearchOptions) window(defaultLimit int) (offset, limit int) {
+	limit = o.Limit
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+	limit = min(limit, MaxLimit)
+
+	offset = min(max(o.Offset, 0), maxDepth-limit)
+
+	return offset, limit
+}
+
+// fuseResults merges ranked result lists with reciprocal rank fusion:
+// each document scores the sum of 1/(rrfK + rank) over the lists it
+// appears in, so documents ranked well by both retrievers rise to the top
+func fuseResults(lists ...[]Result) []Result {
+	scores := map[string]float32{}
+	for _, results := range lists {
+		for rank, result := range results {
+			scores[result.ID] += 1 / float32(rrfK+rank+1)
+		}
+	}
+
+	fused := make([]Result, 0, len(scores))
+	for id, score := range scores {
+		fused = append(fused, Result{ID: id, Similarity: score})
+	}
+
+	sort.Slice(fused, func(i, j int) bool {
+		if fused[i].Similarity != fused[j].Similarity {
+			return fused[i].Similarity > fused[j].Similarity
+		}
+		return fused[i].ID < fused[j].ID
+	})
+
+	return fused
+}
@@ -0,0 +1,419 @@
+package index
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	_ "modernc.org/sqlite"
+)
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS collections (
+	name     TEXT PRIMARY KEY,
+	metadata TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS documents (
+	collection TEXT NOT NULL,
+	id         TEXT NOT NULL,
+	content    TEXT NOT NULL,
+	metadata   TEXT NOT NULL,
+	embedding  BLOB NOT NULL,
+	PRIMARY KEY (collection, id)
+);
+
+CREATE INDEX IF NOT EXISTS documents_file
+	ON documents (collection, json_extract(metadata, '$.file'));
+
+CREATE INDEX IF NOT EXISTS documents_path
+	ON documents (collection, json_extract(metadata, '$.path'));
+
+CREATE INDEX IF NOT EXISTS documents_parent
+	ON documents (collection, json_extract(metadata, '$.parent'));
+`
+
+// metadataKey restricts the metadata keys filtered on, since they're spliced
+// into queries as JSON paths (which lets SQLite use the indexes on them)
+var metadataKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// sqliteDB keeps vector stores in a single embedded SQLite database, one row
+// per document. Similarity search scans the store's embeddings like chromem
+// does, so it's meant for workspaces up to tens of thousands of chunks.
+//
+// A store's embeddings are quantized if its metadata sets "quantization".
+type sqliteDB struct {
+	db *sql.DB
+}
+
+func newSQLiteDB(dir string) (VectorDB, error) {
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(dir, "vectors.sqlite")
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(sqliteSchema)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("couldn't create sqlite schema: %w", err)
+	}
+
+	return &sqliteDB{db: db}, nil
+}
+
+func (d *sqliteDB) Open(name string, metadata map[string]string) (VectorStore, error) {
+	encoded, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = d.db.Exec(
+		"INSERT INTO collections (name, metadata) VALUES (?, ?) ON CONFLICT (name) DO NOTHING",
+		name, string(encoded),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't create collection: %w", err)
+	}
+
+	return d.store(name)
+}
+
+func (d *sqliteDB) Get(name string) VectorStore {
+	store, err := d.store(name)
+	if err != nil {
+		return nil
+	}
+
+	return store
+}
+
+// store opens an existing collection with the format it was created with
+func (d *sqliteDB) store(name string) (*sqliteStore, error) {
+	var encoded string
+	err := d.db.QueryRow("SELECT metadata FROM collections WHERE name = ?", name).Scan(&encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	var metadata map[string]string
+	err = json.Unmarshal([]byte(encoded), &metadata)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't decode collection metadata: %w", err)
+	}
+
+	quantizer, err := newQuantizer(metadata["quantization"])
+	if err != nil {
+		return nil, err
+	}
+
+	return &sqliteStore{db: d.db, name: name, quantizer: quantizer}, nil
+}
+
+func (d *sqliteDB) Names() []string {
+	rows, err := d.db.Query("SELECT name FROM collections")
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if rows.Scan(&name) == nil {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+func (d *sqliteDB) Drop(name string) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("DELETE FROM documents WHERE collection = ?", name)
+	if err != nil {
+		return fmt.Errorf("couldn't delete documents: %w", err)
+	}
+
+	_, err = tx.Exec("DELETE FROM collections WHERE name = ?", name)
+	if err != nil {
+		return fmt.Errorf("couldn't delete collection: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+func (d *sqliteDB) Close() error {
+	return d.db.Close()
+}
+
+// sqliteStore is a VectorStore kept in the documents table of a sqliteDB
+type sqliteStore struct {
+	db        *sql.DB
+	name      string
+	quantizer quantizer
+}
+
+func (s *sqliteStore) Name() string {
+	return s.name
+}
+
+func (s *sqliteStore) storeQuantizer() quantizer {
+	return s.quantizer
+}
+
+func (s *sqliteStore) Add(ctx context.Context, docs []Document) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT OR REPLACE INTO documents (collection, id, content, metadata, embedding)
+		VALUES (?, ?, ?, ?, ?)`,
+	)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, doc := range docs {
+		if len(doc.Embedding) == 0 {
+			return fmt.Errorf("couldn't add %s: %w", doc.ID, errNotEmbedded)
+		}
+
+		metadata, err := json.Marshal(doc.Metadata)
+		if err != nil {
+			return err
+		}
+
+		_, err = stmt.ExecContext(ctx, s.name, doc.ID, doc.Content, string(metadata), s.quantizer.encode(normalized(doc.Embedding)))
+		if err != nil {
+			return fmt.Errorf("couldn't add %s: %w", doc.ID, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s *sqliteStore) Delete(ctx context.Context, where map[string]string, ids ...string) error {
+	if len(ids) == 0 {
+		if len(where) == 0 {
+			return errors.New("delete needs ids or a where filter")
+		}
+
+		clause, args, err := whereClause(where)
+		if err != nil {
+			return err
+		}
+
+		_, err = s.db.ExecContext(ctx, "DELETE FROM documents WHERE collection = ?"+clause, append([]any{s.name}, args...)...)
+		return err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, id := range ids {
+		_, err = tx.ExecContext(ctx, "DELETE FROM documents WHERE collection = ? AND id = ?", s.name, id)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Query ranks every matching document by its stored code, then rescores the
+// best candidates with the full-precision query. Without quantization both
+// scores are exact.
+func (s *sqliteStore) Query(ctx context.Context, embedding []float32, n int, where map[string]string) ([]Result, error) {
+	clause, args, err := whereClause(where)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(
+		ctx,
+		"SELECT id, metadata, embedding FROM documents WHERE collection = ?"+clause,
+		append([]any{s.name}, args...)...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	type scored struct {
+		id       string
+		metadata string
+		code     []byte
+		score    float32
+	}
+
+	query := normalized(embedding)
+	coarse := s.quantizer.coarse(query)
+
+	var candidates []scored
+	for rows.Next() {
+		var c scored
+		err = rows.Scan(&c.id, &c.metadata, &c.code)
+		if err != nil {
+			return nil, err
+		}
+
+		// Documents whose code is malformed can't be scored
+		if s.quantizer.check(c.code) != nil {
+			continue
+		}
+
+		c.score = coarse(c.code)
+		candidates = append(candidates, c)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].score > candidates[j].score
+	})
+
+	candidates = candidates[:min(rescoreFactor*n, len(candidates))]
+	for i := range candidates {
+		candidates[i].score = s.quantizer.rescore(query, candidates[i].code)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].score > candidates[j].score
+	})
+
+	// Only decode the metadata of the results that are returned
+	results := make([]Result, 0, min(n, len(candidates)))
+	for _, c := range candidates[:min(n, len(candidates))] {
+		result := Result{ID: c.id, Similarity: c.score}
+		err = json.Unmarshal([]byte(c.metadata), &result.Metadata)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't decode metadata of %s: %w", c.id, err)
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+func (s *sqliteStore) Get(ctx context.Context, id string) (Document, error) {
+	docs, err := s.documents(ctx, " AND id = ?", id)
+	if err != nil {
+		return Document{}, err
+	}
+
+	if len(docs) == 0 {
+		return Document{}, fmt.Errorf("document with ID %q not found", id)
+	}
+
+	return *docs[0], nil
+}
+
+func (s *sqliteStore) GetWhere(ctx context.Context, where map[string]string) ([]*Document, error) {
+	clause, args, err := whereClause(where)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.documents(ctx, clause, args...)
+}
+
+func (s *sqliteStore) List(ctx context.Context) ([]*Document, error) {
+	return s.documents(ctx, "")
+}
+
+func (s *sqliteStore) Count() int {
+	var count int
+	err := s.db.QueryRow("SELECT COUNT(*) FROM documents WHERE collection = ?", s.name).Scan(&count)
+	if err != nil {
+		return 0
+	}
+
+	return count
+}
+
+// documents returns the store's documents matching an extra where clause
+func (s *sqliteStore) documents(ctx context.Context, clause string, args ...any) ([]*Document, error) {
+	rows, err := s.db.QueryContext(
+		ctx,
+		"SELECT id, content, metadata, embedding FROM documents WHERE collection = ?"+clause,
+		append([]any{s.name}, args...)...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var docs []*Document
+	for rows.Next() {
+		var doc Document
+		var metadata string
+		var blob []byte
+		err = rows.Scan(&doc.ID, &doc.Content, &metadata, &blob)
+		if err != nil {
+			return nil, err
+		}
+
+		err = json.Unmarshal([]byte(metadata), &doc.Metadata)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't decode metadata of %s: %w", doc.ID, err)
+		}
+
+		// Documents whose code is malformed are returned without an
+		// embedding, like ones that still need to be embedded
+		doc.Embedding, _ = s.quantizer.decode(blob)
+		docs = append(docs, &doc)
+	}
+
+	return docs, rows.Err()
+}
+
+// whereClause turns metadata equality filters into an SQL condition
+func whereClause(where map[string]string) (string, []any, error) {
+	keys := make([]string, 0, len(where))
+	for key := range where {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var clause strings.Builder
+	args := make([]any, 0, len(where))
+	for _, key := range keys {
+		if !metadataKey.MatchString(key) {
+			return "", nil, fmt.Errorf("unsupported metadata key %q", key)
+		}
+
+		fmt.Fprintf(&clause, " AND json_extract(metadata, '$.%s') = ?", key)
+		args = append(args, where[key])
+	}
+
+	return clause.String(), args, nil
+}
@@ -0,0 +1,157 @@
+package index
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+)
+
+// Document is a chunk as it's kept in a VectorStore
+type Document struct {
+	ID        string
+	Metadata  map[string]string
+	Embedding []float32
+	Content   string
+}
+
+// Result is a document matching a query
+type Result struct {
+	ID         string
+	Metadata   map[string]string
+	Similarity float32
+}
+
+// VectorStore holds the chunks embedded by one embedding model
+type VectorStore interface {
+	Name() string
+	// Add stores docs, replacing stored documents with the same IDs. Every
+	// document must already be embedded.
+	Add(ctx context.Context, docs []Document) error
+	// Delete removes the documents with the given IDs, or the ones matching
+	// where if no IDs are given
+	Delete(ctx context.Context, where map[string]string, ids ...string) error
+	// Query returns the n documents matching where that are most similar to
+	// an embedding, most similar first. Fewer are returned if fewer match.
+	Query(ctx context.Context, embedding []float32, n int, where map[string]string) ([]Result, error)
+	Get(ctx context.Context, id string) (Document, error)
+	// GetWhere returns the documents whose metadata has all of where's values
+	GetWhere(ctx context.Context, where map[string]string) ([]*Document, error)
+	// List returns every document. Embeddings may be shared with the store and
+	// must not be modified.
+	List(ctx context.Context) ([]*Document, error)
+	Count() int
+}
+
+// filteringStore is implemented by vector stores that can filter documents
+// on more than where while they search, instead of ranking every document
+// matching where for filters that look further
+type filteringStore interface {
+	// QueryMatching is Query restricted to the documents whose metadata
+	// also passes match
+	QueryMatching(
+		ctx context.Context,
+		embedding []float32,
+		n int,
+		where map[string]string,
+		match func(map[string]string) bool,
+	) ([]Result, error)
+}
+
+// VectorDB keeps a workspace's vector stores, one per embedding model
+type VectorDB interface {
+	// Open returns the named store, creating it if it doesn't exist
+	Open(name string, metadata map[string]string) (VectorStore, error)
+	// Get returns the named store, or nil if it doesn't exist
+	Get(name string) VectorStore
+	Names() []string
+	Drop(name string) error
+	Close() error
+}
+
+// VectorDBFactory opens the vector db kept in a workspace's .sourcerer dir
+type VectorDBFactory func(dir string) (VectorDB, error)
+
+type vectorDBBackend struct {
+	open VectorDBFactory
+	path string // what the backend keeps in the .sourcerer dir
+}
+
+var vectorDBs = map[string]vectorDBBackend{
+	"chromem": {open: newChromemDB, path: "db"},
+	"sqlite":  {open: newSQLiteDB, path: "vectors.sqlite"},
+}
+
+// NewVectorDB opens the named vector db backend, chromem if name is empty
+func NewVectorDB(name, dir string) (VectorDB, error) {
+	if name == "" {
+		name = "chromem"
+	}
+
+	backend, exists := vectorDBs[name]
+	if !exists {
+		return nil, fmt.Errorf("unknown vector store %q (available: chromem, sqlite)", name)
+	}
+
+	return backend.open(dir)
+}
+
+// otherVectorDBs opens the vector dbs of other backends that exist in a
+// workspace's .sourcerer dir, so their collections can be migrated
+func otherVectorDBs(name, dir string) []VectorDB {
+	var dbs []VectorDB
+	for other, backend := range vectorDBs {
+		if other == cmp.Or(name, "chromem") {
+			continue
+		}
+
+		_, err := os.Stat(filepath.Join(dir, backend.path))
+		if err != nil {
+			continue
+		}
+
+		db, err := backend.open(dir)
+		if err == nil {
+			dbs = append(dbs, db)
+		}
+	}
+
+	return dbs
+}
+
+// VectorStoreFromEnv reads the vector store backend from the environment
+func VectorStoreFromEnv() string {
+	return os.Getenv("SOURCERER_VECTOR_STORE")
+}
+
+// normalized scales a vector to unit length, so similarity is a dot product
+func normalized(v []float32) []float32 {
+	var norm float32
+	for _, value := range v {
+		norm += value * value
+	}
+
+	// Already normalized vectors are shared rather than copied
+	if norm == 0 || math.Abs(float64(norm)-1) < 1e-5 {
+		return v
+	}
+
+	norm = float32(math.Sqrt(float64(norm)))
+	result := make([]float32, len(v))
+	for i, value := range v {
+		result[i] = value / norm
+	}
+
+	return result
+}
+
+func dot(a, b []float32) float32 {
+	var sum float32
+	for i := range min(len(a), len(b)) {
+		sum += a[i] * b[i]
+	}
+
+	return sum
+}
@@ -0,0 +1,151 @@
+package index_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/st3v3nmw/sourcerer-mcp/internal/index"
+	"github.com/stretchr/testify/suite"
+)
+
+type VectorStoreTestSuite struct {
+	suite.Suite
+	ctx     context.Context
+	backend string
+	ann     bool
+	dir     string
+	db      index.VectorDB
+	store   index.VectorStore
+}
+
+func (s *VectorStoreTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.dir = s.T().TempDir()
+
+	var err error
+	s.db, err = index.NewVectorDB(s.backend, s.dir)
+	s.Require().NoError(err)
+	if s.ann {
+		s.db = index.WithANN(s.db, index.ANNConfig{Enabled: true})
+	}
+
+	s.store, err = s.db.Open("code", map[string]string{"model": "test"})
+	s.Require().NoError(err)
+
+	s.Require().NoError(s.store.Add(s.ctx, []index.Document{
+		{ID: "a.go::A", Content: "A", Embedding: []float32{1, 0, 0}, Metadata: map[string]string{"file": "a.go", "type": "function"}},
+		{ID: "a.go::B", Content: "B", Embedding: []float32{0.8, 0.6, 0}, Metadata: map[string]string{"file": "a.go", "type": "type"}},
+		{ID: "b.go::C", Content: "C", Embedding: []float32{0, 0, 1}, Metadata: map[string]string{"file": "b.go", "type": "function"}},
+	}))
+}
+
+func (s *VectorStoreTestSuite) TestGet() {
+	doc, err := s.store.Get(s.ctx, "a.go::B")
+	s.Require().NoError(err)
+	s.Equal("B", doc.Content)
+	s.Equal("type", doc.Metadata["type"])
+	s.Len(doc.Embedding, 3)
+
+	_, err = s.store.Get(s.ctx, "missing")
+	s.Error(err)
+}
+
+func (s *VectorStoreTestSuite) TestAddReplaces() {
+	s.Require().NoError(s.store.Add(s.ctx, []index.Document{
+		{ID: "a.go::A", Content: "A2", Embedding: []float32{1, 0, 0}, Metadata: map[string]string{"file": "a.go"}},
+	}))
+
+	doc, err := s.store.Get(s.ctx, "a.go::A")
+	s.Require().NoError(err)
+	s.Equal("A2", doc.Content)
+	s.Equal(3, s.store.Count())
+}
+
+func (s *VectorStoreTestSuite) TestAddRequiresEmbedding() {
+	err := s.store.Add(s.ctx, []index.Document{{ID: "c.go::D", Content: "D"}})
+	s.Error(err)
+}
+
+func (s *VectorStoreTestSuite) TestQuery() {
+	results, err := s.store.Query(s.ctx, []float32{2, 0, 0}, 10, nil)
+	s.Require().NoError(err)
+	s.Require().Len(results, 3)
+	s.Equal("a.go::A", results[0].ID)
+	s.Equal("a.go::B", results[1].ID)
+	s.InDelta(1, results[0].Similarity, 1e-5)
+	s.InDelta(0.8, results[1].Similarity, 1e-5)
+	s.Equal("a.go", results[0].Metadata["file"])
+
+	results, err = s.store.Query(s.ctx, []float32{1, 0, 0}, 10, map[string]string{"type": "function"})
+	s.Require().NoError(err)
+	s.Require().Len(results, 2)
+	s.Equal("a.go::A", results[0].ID)
+	s.Equal("b.go::C", results[1].ID)
+
+	results, err = s.store.Query(s.ctx, []float32{1, 0, 0}, 1, nil)
+	s.Require().NoError(err)
+	s.Len(results, 1)
+}
+
+func (s *VectorStoreTestSuite) TestGetWhere() {
+	docs, err := s.store.GetWhere(s.ctx, map[string]string{"file": "a.go"})
+	s.Require().NoError(err)
+	s.Len(docs, 2)
+
+	docs, err = s.store.GetWhere(s.ctx, map[string]string{"file": "a.go", "type": "type"})
+	s.Require().NoError(err)
+	s.Require().Len(docs, 1)
+	s.Equal("a.go::B", docs[0].ID)
+}
+
+func (s *VectorStoreTestSuite) TestDelete() {
+	s.Require().NoError(s.store.Delete(s.ctx, nil, "a.go::A"))
+	s.Equal(2, s.store.Count())
+
+	s.Require().NoError(s.store.Delete(s.ctx, map[string]string{"file": "a.go"}))
+	docs, err := s.store.List(s.ctx)
+	s.Require().NoError(err)
+	s.Require().Len(docs, 1)
+	s.Equal("b.go::C", docs[0].ID)
+
+	s.Error(s.store.Delete(s.ctx, nil))
+}
+
+func (s *VectorStoreTestSuite) TestStores() {
+	s.Contains(s.db.Names(), "code")
+	s.Nil(s.db.Get("other"))
+
+	other, err := s.db.Open("other", nil)
+	s.Require().NoError(err)
+	s.Equal(0, other.Count())
+	s.ElementsMatch([]string{"code", "other"}, s.db.Names())
+
+	s.Require().NoError(s.db.Drop("code"))
+	s.Nil(s.db.Get("code"))
+	s.Equal([]string{"other"}, s.db.Names())
+}
+
+func (s *VectorStoreTestSuite) TestPersistence() {
+	reopened, err := index.NewVectorDB(s.backend, s.dir)
+	s.Require().NoError(err)
+
+	store := reopened.Get("code")
+	s.Require().NotNil(store)
+	s.Equal(3, store.Count())
+
+	doc, err := store.Get(s.ctx, "b.go::C")
+	s.Require().NoError(err)
+	s.Equal("C", doc.Content)
+}
+
+func TestVectorStoreChromem(t *testing.T) {
+	suite.Run(t, &VectorStoreTestSuite{backend: "chromem"})
+}
+
+func TestVectorStoreSQLite(t *testing.T) {
+	suite.Run(t, &VectorStoreTestSuite{backend: "sqlite"})
+}
+
+func TestVectorStoreANN(t *testing.T) {
+	suite.Run(t, &VectorStoreTestSuite{backend: "sqlite", ann: true})
+}
@@ -0,0 +1,75 @@
+package index
+
+import (
+	"strings"
+	"unicode"
+)
+
+// identifiers extracts identifier-like words (letters, digits and underscores)
+// from text, keeping their original case so they can be split further
+func identifiers(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !(unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_')
+	})
+}
+
+// splitIdentifier breaks an identifier into lowercase subwords on
+// snake_case, camelCase, acronym and digit boundaries:
+// "parseHTTPResponse_v2" becomes [parse http response v 2]
+func splitIdentifier(identifier string) []string {
+	var words []string
+	runes := []rune(identifier)
+
+	start := 0
+	flush := func(end int) {
+		if end > start {
+			words = append(words, strings.ToLower(string(runes[start:end])))
+		}
+		start = end
+	}
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if r == '_' {
+			flush(i)
+			start = i + 1
+			continue
+		}
+
+		if i == start {
+			continue
+		}
+
+		prev := runes[i-1]
+		switch {
+		case unicode.IsDigit(r) != unicode.IsDigit(prev):
+			flush(i)
+		case unicode.IsUpper(r) && unicode.IsLower(prev):
+			flush(i)
+		case unicode.IsUpper(r) && unicode.IsUpper(prev) &&
+			i+1 < len(runes) && unicode.IsLower(runes[i+1]):
+			// End of an acronym: "HTTPResponse" splits before "Response"
+			flush(i)
+		}
+	}
+	flush(len(runes))
+
+	return words
+}
+
+// terms returns the lowercase search terms for text: each subword plus the
+// whole identifier when it has several subwords, so `FlushPending` is found
+// by both "flushpending" and "flush pending"
+func terms(text string) []string {
+	var result []string
+	for _, identifier := range identifiers(text) {
+		words := splitIdentifier(identifier)
+		result = append(result, words...)
+
+		if len(words) > 1 {
+			result = append(result, strings.ToLower(strings.ReplaceAll(identifier, "_", "")))
+		}
+	}
+
+	return result
+}
@@ -1,7 +1,9 @@
 package mcp
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -9,6 +11,7 @@ import (
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/st3v3nmw/sourcerer-mcp/internal/analyzer"
+	"github.com/st3v3nmw/sourcerer-mcp/internal/index"
 )
 
 type Server struct {
@@ -179,12 +182,31 @@ When you want to understand both the "what" (implementation) and "why"
 ✓ "error handling" with file_types: ['src', 'memory']
   → finds error handling code AND documented patterns/conventions
 
-AVOID SEMANTIC SEARCH FOR EXACT MATCHES:
-If you need to find specific names or exact text, use pattern-based tools
-like grep & glob instead:
+SEARCH MODES:
+semantic_search defaults to mode 'hybrid', which combines keyword (BM25) and
+semantic relevance so identifiers like FlushPending and error strings are
+found alongside conceptually related code. Use mode 'lexical' for pure
+identifier/exact-text lookups and 'semantic' for purely conceptual queries.
 
 Good: "authentication logic and session management"
-Avoid: "AuthService class definition" (use grep instead)
+Good: "AuthService" with mode: 'lexical'
+For exhaustive exact-text matches across all files, grep is still better.
+
+Results starting with DEGRADED come from keyword search only because the
+embedding provider is unavailable: search for identifiers and exact terms
+until it's back.
+
+PATH AND LANGUAGE FILTERING:
+semantic_search can be narrowed to part of the workspace with directory (e.g.
+'internal/index'), include/exclude globs (e.g. exclude: ['**/testdata/**'])
+and languages (e.g. ['go']).
+
+PAGING:
+semantic_search returns 5 results and find_similar_chunks 10 by default. Pass
+limit (max 50) for more per call, offset to get the next page of the same
+query, and min_score (0-1) to only keep close semantic matches. When results
+are near-duplicates of each other, pass mmr_lambda (e.g. 0.5) to trade some
+relevance for diversity.
 
 CHUNK IDs:
 Use chunk IDs to retrieve source code with surgical precision:
@@ -197,10 +219,19 @@ Chunk IDs are stable across minor edits but update when code structure
 changes (renames, moves, deletions). Use get_chunk_code with these precise
 ids to get exactly the code you need.
 
+Files are re-indexed chunk by chunk as they change. Call get_changed_chunks to
+see which chunks were added, modified, moved or removed since your last search
+before relying on chunk IDs or line numbers you got earlier.
+
 If you already know the specific function/class/method/struct/etc and file
 location from previous context, construct the chunk ID yourself and use
 get_chunk_code directly rather than semantic searching again.
 
+In C and C++, get_chunk_code lists the definitions of a declaration from a
+header (and the declaration of a definition) under "See also", with their
+chunk IDs. Out-of-line definitions share their declaration's path, e.g.
+src/shape.cpp::Shape::area.
+
 MARKDOWN CHUNKS:
 Markdown files are chunked by section (## headers). Each section becomes a
 searchable chunk. For example:
@@ -293,6 +324,27 @@ make informed decisions even after compaction events.
 				mcp.WithStringItems(),
 				mcp.Description("Filter by file type(s)"),
 			),
+			mcp.WithString("mode",
+				mcp.Enum(string(index.SearchHybrid), string(index.SearchSemantic), string(index.SearchLexical)),
+				mcp.Description("Retrieval mode: hybrid (default) combines keyword and semantic relevance, "+
+					"lexical matches identifiers and exact strings, semantic matches concepts"),
+			),
+			mcp.WithString("directory",
+				mcp.Description("Only search files under this workspace directory, e.g. 'internal/index'"),
+			),
+			mcp.WithArray("include",
+				mcp.WithStringItems(),
+				mcp.Description("Only search files matching one of these globs, e.g. 'internal/**/*.go'"),
+			),
+			mcp.WithArray("exclude",
+				mcp.WithStringItems(),
+				mcp.Description("Skip files matching any of these globs, e.g. '**/testdata/**'"),
+			),
+			mcp.WithArray("languages",
+				mcp.WithStringItems(),
+				mcp.Description("Only search files in these languages, e.g. 'go', 'python', 'typescript'"),
+			),
+			withResultOptions(index.DefaultSearchLimit),
 		),
 		s.semanticSearch,
 	)
@@ -304,6 +356,7 @@ make informed decisions even after compaction events.
 				mcp.Required(),
 				mcp.Description("The chunk ID to find similar code for"),
 			),
+			withResultOptions(index.DefaultSimilarLimit),
 		),
 		s.findSimilarChunks,
 	)
@@ -321,6 +374,13 @@ make informed decisions even after compaction events.
 		s.getChunkCode,
 	)
 
+	s.mcp.AddTool(
+		mcp.NewTool("get_changed_chunks",
+			mcp.WithDescription("List chunks that were added, modified, moved or removed since the last search"),
+		),
+		s.getChangedChunks,
+	)
+
 	s.mcp.AddTool(
 		mcp.NewTool("index_workspace",
 			mcp.WithDescription("Index all pending files in the workspace"),
@@ -350,6 +410,56 @@ make informed decisions even after compaction events.
 	return s, nil
 }
 
+// withResultOptions adds the paging and ranking params shared by the search
+// tools
+func withResultOptions(defaultLimit int) mcp.ToolOption {
+	return func(tool *mcp.Tool) {
+		mcp.WithNumber("limit",
+			mcp.Min(1),
+			mcp.Max(index.MaxLimit),
+			mcp.Description(fmt.Sprintf("Number of results to return (default %d, max %d)", defaultLimit, index.MaxLimit)),
+		)(tool)
+		mcp.WithNumber("offset",
+			mcp.Min(0),
+			mcp.Description("Number of results to skip, to get the next page of a previous search"),
+		)(tool)
+		mcp.WithNumber("min_score",
+			mcp.Min(0),
+			mcp.Max(1),
+			mcp.Description("Minimum semantic similarity (0-1) of results, raise it to only get close matches"),
+		)(tool)
+		mcp.WithNumber("mmr_lambda",
+			mcp.Min(0),
+			mcp.Max(1),
+			mcp.Description("Re-rank for diversity when between 0 and 1: lower values drop near-duplicate "+
+				"chunks in favor of different ones, e.g. 0.5 (default: off)"),
+		)(tool)
+	}
+}
+
+// resultOptions reads the paging and ranking params of a search tool request
+func resultOptions(request mcp.CallToolRequest) index.SearchOptions {
+	return index.SearchOptions{
+		Limit:    request.GetInt("limit", 0),
+		Offset:   request.GetInt("offset", 0),
+		MinScore: float32(request.GetFloat("min_score", 0)),
+		Lambda:   float32(request.GetFloat("mmr_lambda", 0)),
+	}
+}
+
+// formatResults joins search results, pointing at the next page when the
+// page is full
+func formatResults(results []string, opts index.SearchOptions, defaultLimit int) string {
+	limit := min(cmp.Or(opts.Limit, defaultLimit), index.MaxLimit)
+
+	content := strings.Join(results, "\n")
+	if len(results) == limit {
+		content += fmt.Sprintf("\n\nMore results may be available with offset: %d", opts.Offset+len(results))
+	}
+
+	return content
+}
+
 func (s *Server) Serve() error {
 	return server.ServeStdio(s.mcp)
 }
@@ -358,23 +468,48 @@ func (s *Server) semanticSearch(ctx context.Context, request mcp.CallToolRequest
 	query := request.GetString("query", "")
 	fileTypes := request.GetStringSlice("file_types", []string{"src", "docs"})
 
-	results, err := s.analyzer.SemanticSearch(ctx, query, fileTypes)
+	mode, err := index.ParseSearchMode(request.GetString("mode", ""))
 	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	opts := resultOptions(request)
+	opts.FileTypes = fileTypes
+	opts.Mode = mode
+	opts.Directory = request.GetString("directory", "")
+	opts.Include = request.GetStringSlice("include", nil)
+	opts.Exclude = request.GetStringSlice("exclude", nil)
+	opts.Languages = request.GetStringSlice("languages", nil)
+
+	results, err := s.analyzer.SemanticSearch(ctx, query, opts)
+	if err != nil && !errors.Is(err, index.ErrDegraded) {
 		return mcp.NewToolResultError(fmt.Sprintf("Search failed: %v", err)), nil
 	}
 
 	if len(results) == 0 {
-		return mcp.NewToolResultText("No matching chunks found."), nil
+		return mcp.NewToolResultText(degradedNote(err) + "No matching chunks found."), nil
 	}
 
-	content := strings.Join(results, "\n")
-	return mcp.NewToolResultText(content), nil
+	return mcp.NewToolResultText(degradedNote(err) + formatResults(results, opts, index.DefaultSearchLimit)), nil
+}
+
+// degradedNote warns that a search fell back to keyword matching, empty if
+// it didn't
+func degradedNote(err error) string {
+	if !errors.Is(err, index.ErrDegraded) {
+		return ""
+	}
+
+	return fmt.Sprintf("DEGRADED: %v. Semantic matches are missing, "+
+		"search for identifiers or exact terms until the provider is back.\n\n", err)
 }
 
 func (s *Server) findSimilarChunks(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	chunkID := request.GetString("id", "")
 
-	results, err := s.analyzer.FindSimilarChunks(ctx, chunkID)
+	opts := resultOptions(request)
+
+	results, err := s.analyzer.FindSimilarChunks(ctx, chunkID, opts)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Search failed: %v", err)), nil
 	}
@@ -383,8 +518,7 @@ func (s *Server) findSimilarChunks(ctx context.Context, request mcp.CallToolRequ
 		return mcp.NewToolResultText("No similar chunks found."), nil
 	}
 
-	content := strings.Join(results, "\n")
-	return mcp.NewToolResultText(content), nil
+	return mcp.NewToolResultText(formatResults(results, opts, index.DefaultSimilarLimit)), nil
 }
 
 func (s *Server) getChunkCode(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -395,6 +529,29 @@ func (s *Server) getChunkCode(ctx context.Context, request mcp.CallToolRequest)
 	return mcp.NewToolResultText(chunks), nil
 }
 
+func (s *Server) getChangedChunks(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	changes := s.analyzer.ChangesSinceLastSearch()
+	if len(changes) == 0 {
+		return mcp.NewToolResultText("No chunks changed since the last search."), nil
+	}
+
+	lines := make([]string, 0, len(changes))
+	for _, change := range changes {
+		line := fmt.Sprintf("%s %s", change.Kind, change.ID)
+		if change.Kind != index.ChunkRemoved {
+			if change.StartLine == change.EndLine {
+				line += fmt.Sprintf(" [line %d]", change.StartLine)
+			} else {
+				line += fmt.Sprintf(" [lines %d-%d]", change.StartLine, change.EndLine)
+			}
+		}
+
+		lines = append(lines, line)
+	}
+
+	return mcp.NewToolResultText(strings.Join(lines, "\n")), nil
+}
+
 func (s *Server) indexWorkspace(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	go s.analyzer.IndexWorkspace(ctx)
 
@@ -411,6 +568,18 @@ func (s *Server) getIndexStatus(ctx context.Context, request mcp.CallToolRequest
 		status += humanize.Time(lastIndexedAt)
 	}
 
+	if s.analyzer.Rebuilding() {
+		status += " (re-embedding chunks for a new embedding model, semantic results may be incomplete)"
+	}
+
+	backlog := s.analyzer.EmbeddingBacklog()
+	if backlog.Files > 0 {
+		status += fmt.Sprintf(
+			"\nWaiting for the embedder: %d files (%d chunks), retried in the background. Last error: %s",
+			backlog.Files, backlog.Chunks, backlog.LastError,
+		)
+	}
+
 	return mcp.NewToolResultText(status), nil
 }
 
@@ -419,17 +588,19 @@ func (s *Server) searchMemory(ctx context.Context, request mcp.CallToolRequest)
 	query := request.GetString("query", "")
 
 	// Search only memory file type
-	results, err := s.analyzer.SemanticSearch(ctx, query, []string{"memory"})
-	if err != nil {
+	results, err := s.analyzer.SemanticSearch(ctx, query, index.SearchOptions{
+		FileTypes: []string{"memory"},
+	})
+	if err != nil && !errors.Is(err, index.ErrDegraded) {
 		return mcp.NewToolResultError(fmt.Sprintf("Memory search failed: %v", err)), nil
 	}
 
 	if len(results) == 0 {
-		return mcp.NewToolResultText("No matching decisions or context found in project memory."), nil
+		return mcp.NewToolResultText(degradedNote(err) + "No matching decisions or context found in project memory."), nil
 	}
 
 	content := strings.Join(results, "\n")
-	return mcp.NewToolResultText(content), nil
+	return mcp.NewToolResultText(degradedNote(err) + content), nil
 }
 
 func (s *Server) Close() error {
@@ -0,0 +1,100 @@
+package parser
+
+import (
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+	tree_sitter_c "github.com/tree-sitter/tree-sitter-c/bindings/go"
+)
+
+// cFunctionName matches the name of a function declarator, including one
+// returning a pointer
+const cFunctionName = `
+	(function_declarator declarator: (identifier) @name)
+	(pointer_declarator declarator: (function_declarator declarator: (identifier) @name))
+	(pointer_declarator declarator: (pointer_declarator declarator: (function_declarator declarator: (identifier) @name)))`
+
+var CSpec = &LanguageSpec{
+	NamedChunks: map[string]NamedChunkExtractor{
+		"function_definition": {
+			NameQuery: `(function_definition declarator: [` + cFunctionName + `])`,
+		},
+		// Prototypes and global variables
+		"declaration": {
+			NameQuery: `
+				(declaration declarator: [
+					` + cFunctionName + `
+					(identifier) @name
+					(init_declarator declarator: (identifier) @name)
+					(pointer_declarator declarator: (identifier) @name)
+					(init_declarator declarator: (pointer_declarator declarator: (identifier) @name))])`,
+		},
+		"struct_specifier": {
+			NameQuery: `(struct_specifier name: (type_identifier) @name)`,
+		},
+		"union_specifier": {
+			NameQuery: `(union_specifier name: (type_identifier) @name)`,
+		},
+		"enum_specifier": {
+			NameQuery: `(enum_specifier name: (type_identifier) @name)`,
+		},
+		"type_definition": {
+			NameQuery: `
+				(type_definition declarator: [
+					(type_identifier) @name
+					(pointer_declarator declarator: (type_identifier) @name)
+					(function_declarator
+						declarator: (parenthesized_declarator
+							(pointer_declarator declarator: (type_identifier) @name)))])`,
+		},
+		"preproc_def": {
+			NameQuery: `(preproc_def name: (identifier) @name)`,
+		},
+		"preproc_function_def": {
+			NameQuery: `(preproc_function_def name: (identifier) @name)`,
+		},
+	},
+	ExtractChildrenIn: []string{
+		// Include guards and conditional compilation
+		"preproc_ifdef",
+		"preproc_if",
+		"preproc_else",
+		"preproc_elif",
+		// extern "C" blocks
+		"linkage_specification",
+		"declaration_list",
+	},
+	FoldIntoNextNode: []string{"comment"},
+	SkipTypes: []string{
+		// Includes pollute search results
+		"preproc_include",
+		// Skip preprocessor directives and conditions around declarations
+		"#if", "#ifdef", "#ifndef", "#else", "#elif", "#endif", "extern",
+		"identifier",
+		"preproc_defined",
+		"string_literal",
+		// Skip punctuation tokens
+		"{", "}", ";",
+		// Skip container nodes (but still extract their children)
+		"preproc_ifdef",
+		"preproc_if",
+		"preproc_else",
+		"preproc_elif",
+		"linkage_specification",
+		"declaration_list",
+	},
+	FileTypeRules: []FileTypeRule{
+		{Pattern: "**/*_test.c", Type: FileTypeTests},
+		{Pattern: "build/**", Type: FileTypeIgnore},
+	},
+	RootedQueries: true,
+}
+
+func NewCParser(workspaceRoot string) (*Parser, error) {
+	parser := tree_sitter.NewParser()
+	parser.SetLanguage(tree_sitter.NewLanguage(tree_sitter_c.Language()))
+
+	return &Parser{
+		workspaceRoot: workspaceRoot,
+		parser:        parser,
+		spec:          CSpec,
+	}, nil
+}
@@ -0,0 +1,208 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/st3v3nmw/sourcerer-mcp/internal/parser"
+	"github.com/stretchr/testify/suite"
+)
+
+type CParserTestSuite struct {
+	ParserBaseTestSuite
+}
+
+func (s *CParserTestSuite) SetupSuite() {
+	s.ParserBaseTestSuite.SetupSuite()
+
+	var err error
+	s.parser, err = parser.NewCParser(s.workspaceRoot)
+	s.Require().NoError(err)
+}
+
+func (s *CParserTestSuite) TestHeaderParsing() {
+	chunks := s.getChunks("c/list.h")
+
+	tests := []struct {
+		name      string
+		path      string
+		kind      string
+		summary   string
+		startLine int
+		endLine   int
+	}{
+		{
+			name:      "Include Guard",
+			path:      "LIST_H",
+			kind:      "preproc_def",
+			summary:   "#define LIST_H",
+			startLine: 2,
+			endLine:   3,
+		},
+		{
+			name:      "Macro",
+			path:      "LIST_EMPTY",
+			kind:      "preproc_function_def",
+			summary:   "#define LIST_EMPTY(l) ((l)->len == 0)",
+			startLine: 7,
+			endLine:   8,
+		},
+		{
+			name:      "Struct",
+			path:      "node",
+			kind:      "struct_specifier",
+			summary:   "struct node {",
+			startLine: 9,
+			endLine:   13,
+		},
+		{
+			name:      "Union",
+			path:      "number",
+			kind:      "union_specifier",
+			summary:   "union number {",
+			startLine: 15,
+			endLine:   18,
+		},
+		{
+			name:      "Enum",
+			path:      "color",
+			kind:      "enum_specifier",
+			summary:   "enum color { RED, GREEN, BLUE }",
+			startLine: 20,
+			endLine:   20,
+		},
+		{
+			name:      "Typedef",
+			path:      "list",
+			kind:      "type_definition",
+			summary:   "typedef struct {",
+			startLine: 22,
+			endLine:   25,
+		},
+		{
+			name:      "Function Pointer Typedef",
+			path:      "compare_fn",
+			kind:      "type_definition",
+			summary:   "typedef int (*compare_fn)(int, int);",
+			startLine: 27,
+			endLine:   27,
+		},
+		{
+			name:      "Prototype",
+			path:      "list_push",
+			kind:      "declaration",
+			summary:   "int list_push(list *l, int value);",
+			startLine: 29,
+			endLine:   30,
+		},
+		{
+			name:      "Pointer Returning Prototype",
+			path:      "list_new",
+			kind:      "declaration",
+			summary:   "list *list_new(void);",
+			startLine: 32,
+			endLine:   32,
+		},
+		{
+			name:      "Extern Variable",
+			path:      "list_count",
+			kind:      "declaration",
+			summary:   "extern int list_count;",
+			startLine: 34,
+			endLine:   34,
+		},
+	}
+
+	for _, test := range tests {
+		s.Run(test.name, func() {
+			chunk, exists := chunks[test.path]
+			s.Require().True(exists, "chunk %s not found", test.path)
+			s.Require().NotNil(chunk)
+
+			s.Equal("src", chunk.Type)
+			s.Equal(test.kind, chunk.Kind)
+			s.Equal(test.summary, chunk.Summary)
+			s.Equal(test.startLine, int(chunk.StartLine))
+			s.Equal(test.endLine, int(chunk.EndLine))
+			s.Equal("c/list.h::"+test.path, chunk.ID())
+		})
+	}
+
+	s.Run("Doc Comments", func() {
+		s.Equal("/* A node of a singly linked list. */", chunks["node"].Doc)
+		s.Equal("// Appends a value to the list.", chunks["list_push"].Doc)
+	})
+
+	s.Run("Includes Skipped", func() {
+		for _, chunk := range chunks {
+			s.NotContains(chunk.Source, "#include")
+		}
+	})
+}
+
+func (s *CParserTestSuite) TestSourceParsing() {
+	chunks := s.getChunks("c/list.c")
+
+	tests := []struct {
+		name      string
+		path      string
+		summary   string
+		startLine int
+		endLine   int
+	}{
+		{
+			name:      "Static Function",
+			path:      "compare",
+			summary:   "static int compare(int a, int b) {",
+			startLine: 6,
+			endLine:   8,
+		},
+		{
+			name:      "Function",
+			path:      "list_push",
+			summary:   "int list_push(list *l, int value) {",
+			startLine: 10,
+			endLine:   21,
+		},
+		{
+			name:      "Pointer Returning Function",
+			path:      "list_new",
+			summary:   "list *list_new(void) {",
+			startLine: 23,
+			endLine:   26,
+		},
+	}
+
+	for _, test := range tests {
+		s.Run(test.name, func() {
+			chunk, exists := chunks[test.path]
+			s.Require().True(exists, "chunk %s not found", test.path)
+			s.Require().NotNil(chunk)
+
+			s.Equal("function_definition", chunk.Kind)
+			s.Equal(test.summary, chunk.Summary)
+			s.Equal(test.startLine, int(chunk.StartLine))
+			s.Equal(test.endLine, int(chunk.EndLine))
+		})
+	}
+
+	s.Run("Global Variable", func() {
+		chunk, exists := chunks["list_count"]
+		s.Require().True(exists)
+		s.Equal("int list_count = 0;", chunk.Summary)
+	})
+}
+
+func (s *CParserTestSuite) TestBuildIgnored() {
+	_, err := s.parser.Chunk("build/generated.c")
+	s.ErrorContains(err, "marked as ignore")
+}
+
+func (s *CParserTestSuite) TearDownSuite() {
+	if s.parser != nil {
+		s.parser.Close()
+	}
+}
+
+func TestCParserTestSuite(t *testing.T) {
+	suite.Run(t, new(CParserTestSuite))
+}
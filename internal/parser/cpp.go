@@ -0,0 +1,144 @@
+package parser
+
+import (
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+	tree_sitter_cpp "github.com/tree-sitter/tree-sitter-cpp/bindings/go"
+)
+
+// cppFunctionName matches the name of a function declarator. Out-of-line
+// definitions are named by their qualified name, e.g. Foo::bar, so they get
+// the same path as their declaration in the class.
+const cppFunctionName = `
+	(function_declarator declarator: [
+		(identifier) (field_identifier) (qualified_identifier) (destructor_name) (operator_name)] @name)
+	(pointer_declarator declarator: (function_declarator declarator: [
+		(identifier) (field_identifier) (qualified_identifier) (operator_name)] @name))
+	(reference_declarator (function_declarator declarator: [
+		(identifier) (field_identifier) (qualified_identifier) (operator_name)] @name))`
+
+var CppSpec = &LanguageSpec{
+	NamedChunks: map[string]NamedChunkExtractor{
+		"function_definition": {
+			NameQuery: `(function_definition declarator: [` + cppFunctionName + `])`,
+		},
+		// Prototypes, constructor declarations and global variables
+		"declaration": {
+			NameQuery: `
+				(declaration declarator: [
+					` + cppFunctionName + `
+					(identifier) @name
+					(init_declarator declarator: (identifier) @name)
+					(pointer_declarator declarator: (identifier) @name)])`,
+		},
+		// Method declarations and data members
+		"field_declaration": {
+			NameQuery: `
+				(field_declaration declarator: [
+					` + cppFunctionName + `
+					(field_identifier) @name
+					(pointer_declarator declarator: (field_identifier) @name)])`,
+		},
+		"class_specifier": {
+			NameQuery: `(class_specifier name: (type_identifier) @name)`,
+		},
+		"struct_specifier": {
+			NameQuery: `(struct_specifier name: (type_identifier) @name)`,
+		},
+		"union_specifier": {
+			NameQuery: `(union_specifier name: (type_identifier) @name)`,
+		},
+		"enum_specifier": {
+			NameQuery: `(enum_specifier name: (type_identifier) @name)`,
+		},
+		"namespace_definition": {
+			NameQuery: `(namespace_definition name: [(namespace_identifier) (nested_namespace_specifier)] @name)`,
+		},
+		"type_definition": {
+			NameQuery: `
+				(type_definition declarator: [
+					(type_identifier) @name
+					(pointer_declarator declarator: (type_identifier) @name)
+					(function_declarator
+						declarator: (parenthesized_declarator
+							(pointer_declarator declarator: (type_identifier) @name)))])`,
+		},
+		"alias_declaration": {
+			NameQuery: `(alias_declaration name: (type_identifier) @name)`,
+		},
+		"concept_definition": {
+			NameQuery: `(concept_definition name: (identifier) @name)`,
+		},
+		"preproc_def": {
+			NameQuery: `(preproc_def name: (identifier) @name)`,
+		},
+		"preproc_function_def": {
+			NameQuery: `(preproc_function_def name: (identifier) @name)`,
+		},
+	},
+	ExtractChildrenIn: []string{
+		"namespace_definition",
+		"class_specifier",
+		"struct_specifier",
+		"union_specifier",
+		"field_declaration_list",
+		"declaration_list",
+		// The template header is folded into the declaration it applies to
+		"template_declaration",
+		// Include guards and conditional compilation
+		"preproc_ifdef",
+		"preproc_if",
+		"preproc_else",
+		"preproc_elif",
+		// extern "C" blocks
+		"linkage_specification",
+	},
+	FoldIntoNextNode: []string{"comment", "template", "template_parameter_list"},
+	SkipTypes: []string{
+		// Includes pollute search results
+		"preproc_include",
+		"using_declaration",
+		// Skip preprocessor directives and conditions around declarations
+		"#if", "#ifdef", "#ifndef", "#else", "#elif", "#endif", "extern",
+		"preproc_call",
+		"preproc_defined",
+		"string_literal",
+		// Skip the keywords, names and bases of classes and namespaces
+		"namespace", "class", "struct", "union",
+		"identifier",
+		"namespace_identifier",
+		"nested_namespace_specifier",
+		"type_identifier",
+		"base_class_clause",
+		"access_specifier",
+		"virtual_specifier",
+		// Skip punctuation tokens
+		"{", "}", ";", ":",
+		// Skip container nodes (but still extract their children)
+		"field_declaration_list",
+		"declaration_list",
+		"template_declaration",
+		"preproc_ifdef",
+		"preproc_if",
+		"preproc_else",
+		"preproc_elif",
+		"linkage_specification",
+	},
+	FileTypeRules: []FileTypeRule{
+		{Pattern: "**/*_test.{cc,cpp}", Type: FileTypeTests},
+		{Pattern: "**/*_unittest.{cc,cpp}", Type: FileTypeTests},
+		{Pattern: "build/**", Type: FileTypeIgnore},
+		{Pattern: "cmake-build-*/**", Type: FileTypeIgnore},
+	},
+	RootedQueries: true,
+}
+
+func NewCppParser(workspaceRoot string) (*Parser, error) {
+	parser := tree_sitter.NewParser()
+	parser.SetLanguage(tree_sitter.NewLanguage(tree_sitter_cpp.Language()))
+
+	return &Parser{
+		workspaceRoot: workspaceRoot,
+		parser:        parser,
+		spec:          CppSpec,
+	}, nil
+}
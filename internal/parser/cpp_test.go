@@ -0,0 +1,281 @@
+package parser_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/st3v3nmw/sourcerer-mcp/internal/parser"
+	"github.com/stretchr/testify/suite"
+)
+
+type CppParserTestSuite struct {
+	ParserBaseTestSuite
+}
+
+func (s *CppParserTestSuite) SetupSuite() {
+	s.ParserBaseTestSuite.SetupSuite()
+
+	var err error
+	s.parser, err = parser.NewCppParser(s.workspaceRoot)
+	s.Require().NoError(err)
+}
+
+func (s *CppParserTestSuite) TestHeaderParsing() {
+	chunks := s.getChunks("cpp/shapes.hpp")
+
+	tests := []struct {
+		name      string
+		path      string
+		kind      string
+		summary   string
+		startLine int
+		endLine   int
+	}{
+		{
+			name:      "Namespace",
+			path:      "geo",
+			kind:      "namespace_definition",
+			summary:   "namespace geo {",
+			startLine: 6,
+			endLine:   55,
+		},
+		{
+			name:      "Struct",
+			path:      "geo::Point",
+			kind:      "struct_specifier",
+			summary:   "struct Point {",
+			startLine: 8,
+			endLine:   12,
+		},
+		{
+			name:      "Data Member",
+			path:      "geo::Point::x",
+			kind:      "field_declaration",
+			summary:   "double x;",
+			startLine: 10,
+			endLine:   10,
+		},
+		{
+			name:      "Class",
+			path:      "geo::Shape",
+			kind:      "class_specifier",
+			summary:   "class Shape {",
+			startLine: 14,
+			endLine:   20,
+		},
+		{
+			name:      "Destructor",
+			path:      "geo::Shape::~Shape",
+			kind:      "function_definition",
+			summary:   "virtual ~Shape() = default;",
+			startLine: 17,
+			endLine:   17,
+		},
+		{
+			name:      "Pure Virtual Method",
+			path:      "geo::Shape::area",
+			kind:      "field_declaration",
+			summary:   "virtual double area() const = 0;",
+			startLine: 18,
+			endLine:   18,
+		},
+		{
+			name:      "Inline Method",
+			path:      "geo::Shape::name",
+			kind:      "function_definition",
+			summary:   `std::string name() const { return "shape"; }`,
+			startLine: 19,
+			endLine:   19,
+		},
+		{
+			name:      "Derived Class",
+			path:      "geo::Circle",
+			kind:      "class_specifier",
+			summary:   "class Circle : public Shape {",
+			startLine: 22,
+			endLine:   29,
+		},
+		{
+			name:      "Constructor Declaration",
+			path:      "geo::Circle::Circle",
+			kind:      "declaration",
+			summary:   "explicit Circle(double radius);",
+			startLine: 24,
+			endLine:   24,
+		},
+		{
+			name:      "Method Declaration",
+			path:      "geo::Circle::area",
+			kind:      "field_declaration",
+			summary:   "double area() const override;",
+			startLine: 25,
+			endLine:   25,
+		},
+		{
+			name:      "Class Template",
+			path:      "geo::Stack",
+			kind:      "class_specifier",
+			summary:   "class Stack {",
+			startLine: 31,
+			endLine:   38,
+		},
+		{
+			name:      "Class Template Method",
+			path:      "geo::Stack::push",
+			kind:      "function_definition",
+			summary:   "void push(const T& value) { items_.push_back(value); }",
+			startLine: 34,
+			endLine:   34,
+		},
+		{
+			name:      "Function Template",
+			path:      "geo::largest",
+			kind:      "function_definition",
+			summary:   "T largest(const T& a, const T& b) {",
+			startLine: 40,
+			endLine:   43,
+		},
+		{
+			name:      "Type Alias",
+			path:      "geo::Points",
+			kind:      "alias_declaration",
+			summary:   "using Points = std::vector<Point>;",
+			startLine: 45,
+			endLine:   45,
+		},
+		{
+			name:      "Scoped Enum",
+			path:      "geo::Unit",
+			kind:      "enum_specifier",
+			summary:   "enum class Unit { Meters, Feet }",
+			startLine: 47,
+			endLine:   47,
+		},
+		{
+			name:      "Reference Returning Prototype",
+			path:      "geo::origin",
+			kind:      "declaration",
+			summary:   "const Point& origin();",
+			startLine: 51,
+			endLine:   51,
+		},
+		{
+			name:      "Pointer Returning Prototype",
+			path:      "geo::make_circle",
+			kind:      "declaration",
+			summary:   "Shape* make_circle(double radius);",
+			startLine: 53,
+			endLine:   53,
+		},
+	}
+
+	for _, test := range tests {
+		s.Run(test.name, func() {
+			chunk, exists := chunks[test.path]
+			s.Require().True(exists, "chunk %s not found", test.path)
+			s.Require().NotNil(chunk)
+
+			s.Equal("src", chunk.Type)
+			s.Equal(test.kind, chunk.Kind)
+			s.Equal(test.summary, chunk.Summary)
+			s.Equal(test.startLine, int(chunk.StartLine))
+			s.Equal(test.endLine, int(chunk.EndLine))
+			s.Equal("cpp/shapes.hpp::"+test.path, chunk.ID())
+		})
+	}
+
+	s.Run("Template Header Folded", func() {
+		s.True(strings.HasPrefix(chunks["geo::Stack"].Source, "template <typename T>\nclass Stack {"))
+	})
+
+	s.Run("Doc Comments", func() {
+		s.Equal("/// A point in the plane.", chunks["geo::Point"].Doc)
+	})
+
+	s.Run("Includes Skipped", func() {
+		for _, chunk := range chunks {
+			s.NotContains(chunk.Source, "#include")
+		}
+	})
+}
+
+func (s *CppParserTestSuite) TestOutOfLineDefinitions() {
+	header := s.getChunks("cpp/shapes.hpp")
+	chunks := s.getChunks("cpp/shapes.cpp")
+
+	tests := []struct {
+		name      string
+		path      string
+		summary   string
+		startLine int
+		endLine   int
+	}{
+		{
+			name:      "Constructor",
+			path:      "geo::Circle::Circle",
+			summary:   "Circle::Circle(double radius) : radius_(radius) {}",
+			startLine: 5,
+			endLine:   5,
+		},
+		{
+			name:      "Method",
+			path:      "geo::Circle::area",
+			summary:   "double Circle::area() const {",
+			startLine: 7,
+			endLine:   10,
+		},
+		{
+			name:      "Function",
+			path:      "geo::total_area",
+			summary:   "double total_area(const std::vector<Shape*>& shapes) {",
+			startLine: 12,
+			endLine:   18,
+		},
+		{
+			name:      "Reference Returning Function",
+			path:      "geo::origin",
+			summary:   "const Point& origin() {",
+			startLine: 20,
+			endLine:   23,
+		},
+	}
+
+	for _, test := range tests {
+		s.Run(test.name, func() {
+			chunk, exists := chunks[test.path]
+			s.Require().True(exists, "chunk %s not found", test.path)
+			s.Require().NotNil(chunk)
+
+			s.Equal("function_definition", chunk.Kind)
+			s.Equal(test.summary, chunk.Summary)
+			s.Equal(test.startLine, int(chunk.StartLine))
+			s.Equal(test.endLine, int(chunk.EndLine))
+
+			// Definitions share their declaration's path
+			_, declared := header[test.path]
+			s.True(declared, "declaration %s not found", test.path)
+		})
+	}
+
+	s.Run("Anonymous Namespace", func() {
+		_, exists := chunks["helper"]
+		s.True(exists)
+	})
+}
+
+func (s *CppParserTestSuite) TestBuildDirsIgnored() {
+	for _, filePath := range []string{"build/gen.cpp", "cmake-build-debug/gen.cpp"} {
+		_, err := s.parser.Chunk(filePath)
+		s.ErrorContains(err, "marked as ignore")
+	}
+}
+
+func (s *CppParserTestSuite) TearDownSuite() {
+	if s.parser != nil {
+		s.parser.Close()
+	}
+}
+
+func TestCppParserTestSuite(t *testing.T) {
+	suite.Run(t, new(CppParserTestSuite))
+}
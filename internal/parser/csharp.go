@@ -0,0 +1,105 @@
+package parser
+
+import (
+	"regexp"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+	tree_sitter_csharp "github.com/tree-sitter/tree-sitter-c-sharp/bindings/go"
+)
+
+// Namespaces are left out of chunk paths, like Java packages, so paths are the
+// same whether a file uses a block or a file-scoped namespace
+var CSharpSpec = &LanguageSpec{
+	NamedChunks: map[string]NamedChunkExtractor{
+		"class_declaration": {
+			NameQuery: `(class_declaration name: (identifier) @name)`,
+		},
+		"record_declaration": {
+			NameQuery: `(record_declaration name: (identifier) @name)`,
+		},
+		"struct_declaration": {
+			NameQuery: `(struct_declaration name: (identifier) @name)`,
+		},
+		"interface_declaration": {
+			NameQuery: `(interface_declaration name: (identifier) @name)`,
+		},
+		"enum_declaration": {
+			NameQuery: `(enum_declaration name: (identifier) @name)`,
+		},
+		"delegate_declaration": {
+			NameQuery: `(delegate_declaration name: (identifier) @name)`,
+		},
+		"constructor_declaration": {
+			NameQuery: `(constructor_declaration name: (identifier) @name)`,
+		},
+		"destructor_declaration": {
+			NameQuery: `(destructor_declaration name: (identifier) @name)`,
+		},
+		"method_declaration": {
+			NameQuery: `(method_declaration name: (identifier) @name)`,
+		},
+		"property_declaration": {
+			NameQuery: `(property_declaration name: (identifier) @name)`,
+		},
+		"event_declaration": {
+			NameQuery: `(event_declaration name: (identifier) @name)`,
+		},
+		// Declarations of several variables are named after the first one
+		"event_field_declaration": {
+			NameQuery: `(event_field_declaration (variable_declaration type: (_) . (variable_declarator name: (identifier) @name)))`,
+		},
+		"field_declaration": {
+			NameQuery: `(field_declaration (variable_declaration type: (_) . (variable_declarator name: (identifier) @name)))`,
+		},
+	},
+	ExtractChildrenIn: []string{
+		"namespace_declaration",
+		"class_declaration",
+		"record_declaration",
+		"struct_declaration",
+		"interface_declaration",
+		"declaration_list",
+	},
+	// XML doc comments and other comments
+	FoldIntoNextNode: []string{"comment"},
+	SkipTypes: []string{
+		// Usings and namespace clauses pollute search results
+		"using_directive",
+		"file_scoped_namespace_declaration",
+		// Skip the modifiers, names and supertypes of type declarations
+		"namespace", "class", "record", "struct", "interface",
+		"modifier",
+		"attribute_list",
+		"identifier",
+		"qualified_name",
+		"type_parameter_list",
+		"type_parameter_constraints_clause",
+		"base_list",
+		"parameter_list",
+		// Skip punctuation tokens
+		"{", "}", ";",
+		// Skip container nodes (but still extract their children)
+		"namespace_declaration",
+		"declaration_list",
+	},
+	FileTypeRules: []FileTypeRule{
+		{Pattern: "**/*.Tests/**", Type: FileTypeTests},
+		// Every project has its own build output
+		{Pattern: "**/bin/**", Type: FileTypeIgnore},
+		{Pattern: "**/obj/**", Type: FileTypeIgnore},
+	},
+	// Attributes on their own line, e.g. [Serializable]
+	SummarySkipLines: regexp.MustCompile(`^\[.*\]$`),
+	RootedQueries:    true,
+}
+
+func NewCSharpParser(workspaceRoot string) (*Parser, error) {
+	parser := tree_sitter.NewParser()
+	parser.SetLanguage(tree_sitter.NewLanguage(tree_sitter_csharp.Language()))
+
+	return &Parser{
+		workspaceRoot: workspaceRoot,
+		parser:        parser,
+		spec:          CSharpSpec,
+	}, nil
+}
@@ -0,0 +1,209 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/st3v3nmw/sourcerer-mcp/internal/parser"
+	"github.com/stretchr/testify/suite"
+)
+
+type CSharpParserTestSuite struct {
+	ParserBaseTestSuite
+}
+
+func (s *CSharpParserTestSuite) SetupSuite() {
+	s.ParserBaseTestSuite.SetupSuite()
+
+	var err error
+	s.parser, err = parser.NewCSharpParser(s.workspaceRoot)
+	s.Require().NoError(err)
+}
+
+func (s *CSharpParserTestSuite) TestDeclarationParsing() {
+	chunks := s.getChunks("csharp/Library/Models/Book.cs")
+
+	tests := []struct {
+		name      string
+		path      string
+		kind      string
+		summary   string
+		startLine int
+		endLine   int
+	}{
+		{
+			name:      "Class With Attribute",
+			path:      "Book",
+			kind:      "class_declaration",
+			summary:   "public class Book : IComparable<Book>",
+			startLine: 6,
+			endLine:   42,
+		},
+		{
+			name:      "Constant",
+			path:      "Book::MaxTitleLength",
+			kind:      "field_declaration",
+			summary:   "public const int MaxTitleLength = 200;",
+			startLine: 12,
+			endLine:   12,
+		},
+		{
+			name:      "Constructor",
+			path:      "Book::Book",
+			kind:      "constructor_declaration",
+			summary:   "public Book(string title)",
+			startLine: 16,
+			endLine:   20,
+		},
+		{
+			name:      "Auto Property",
+			path:      "Book::Title",
+			kind:      "property_declaration",
+			summary:   "public string Title { get; set; }",
+			startLine: 22,
+			endLine:   22,
+		},
+		{
+			name:      "Event",
+			path:      "Book::CheckedOut",
+			kind:      "event_field_declaration",
+			summary:   "public event EventHandler? CheckedOut;",
+			startLine: 26,
+			endLine:   26,
+		},
+		{
+			name:      "Method",
+			path:      "Book::Checkout",
+			kind:      "method_declaration",
+			summary:   "public void Checkout(string patron)",
+			startLine: 28,
+			endLine:   34,
+		},
+		{
+			name:      "Expression Bodied Method",
+			path:      "Book::CompareTo",
+			kind:      "method_declaration",
+			summary:   "public int CompareTo(Book? other) => string.Compare(Title, other?.Title);",
+			startLine: 36,
+			endLine:   36,
+		},
+		{
+			name:      "Nested Class Method",
+			path:      "Book::Builder::Build",
+			kind:      "method_declaration",
+			summary:   `public Book Build() => new("untitled");`,
+			startLine: 40,
+			endLine:   40,
+		},
+		{
+			name:      "Positional Record",
+			path:      "Patron",
+			kind:      "record_declaration",
+			summary:   "public record Patron(string Name, int Loans);",
+			startLine: 44,
+			endLine:   44,
+		},
+		{
+			name:      "Struct",
+			path:      "ShelfLocation",
+			kind:      "struct_declaration",
+			summary:   "public struct ShelfLocation",
+			startLine: 46,
+			endLine:   50,
+		},
+		{
+			name:      "Interface Method",
+			path:      "ICatalog::Find",
+			kind:      "method_declaration",
+			summary:   "Book? Find(string title);",
+			startLine: 54,
+			endLine:   54,
+		},
+		{
+			name:      "Enum",
+			path:      "Genre",
+			kind:      "enum_declaration",
+			summary:   "public enum Genre",
+			startLine: 57,
+			endLine:   61,
+		},
+		{
+			name:      "Field With Several Variables",
+			path:      "Order::quantity",
+			kind:      "field_declaration",
+			summary:   "private int quantity, total;",
+			startLine: 65,
+			endLine:   65,
+		},
+		{
+			name:      "Event With Several Variables",
+			path:      "Order::Placed",
+			kind:      "event_field_declaration",
+			summary:   "public event EventHandler? Placed, Shipped;",
+			startLine: 67,
+			endLine:   67,
+		},
+	}
+
+	for _, test := range tests {
+		s.Run(test.name, func() {
+			chunk, exists := chunks[test.path]
+			s.Require().True(exists, "chunk %s not found", test.path)
+			s.Require().NotNil(chunk)
+
+			s.Equal("src", chunk.Type)
+			s.Equal(test.kind, chunk.Kind)
+			s.Equal(test.summary, chunk.Summary)
+			s.Equal(test.startLine, int(chunk.StartLine))
+			s.Equal(test.endLine, int(chunk.EndLine))
+			s.Equal("csharp/Library/Models/Book.cs::"+test.path, chunk.ID())
+		})
+	}
+
+	s.Run("XML Doc Comments", func() {
+		s.Equal("/// <summary>\n/// A book on the shelf.\n/// </summary>", chunks["Book"].Doc)
+		s.Equal(`/// <summary>Marks the book as checked out.</summary>
+/// <param name="patron">Who borrowed it.</param>`, chunks["Book::Checkout"].Doc)
+	})
+
+	s.Run("Usings Skipped", func() {
+		for _, chunk := range chunks {
+			s.NotContains(chunk.Source, "using System;")
+			s.NotContains(chunk.Source, "namespace Library.Models;")
+		}
+	})
+}
+
+func (s *CSharpParserTestSuite) TestBlockNamespaces() {
+	chunks := s.getChunks("csharp/Library/Catalog.cs")
+
+	for _, path := range []string{"Catalog", "Catalog::Create", "Notify"} {
+		_, exists := chunks[path]
+		s.True(exists, "chunk %s not found", path)
+	}
+}
+
+func (s *CSharpParserTestSuite) TestTestProjects() {
+	chunks := s.getChunks("csharp/Library.Tests/BookTests.cs")
+
+	chunk, exists := chunks["BookTests::CheckoutMarksBookUnavailable"]
+	s.Require().True(exists)
+	s.Equal("tests", chunk.Type)
+	s.Equal("public void CheckoutMarksBookUnavailable()", chunk.Summary)
+}
+
+func (s *CSharpParserTestSuite) TestBuildOutputIgnored() {
+	for _, filePath := range []string{"Library/bin/Debug/net8.0/Gen.cs", "Library/obj/Debug/net8.0/AssemblyInfo.cs"} {
+		_, err := s.parser.Chunk(filePath)
+		s.ErrorContains(err, "marked as ignore")
+	}
+}
+
+func (s *CSharpParserTestSuite) TearDownSuite() {
+	if s.parser != nil {
+		s.parser.Close()
+	}
+}
+
+func TestCSharpParserTestSuite(t *testing.T) {
+	suite.Run(t, new(CSharpParserTestSuite))
+}
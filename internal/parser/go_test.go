@@ -1,6 +1,7 @@
 package parser_test
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/st3v3nmw/sourcerer-mcp/internal/parser"
@@ -490,25 +491,19 @@ const DefaultTimeout = 30`,
 			endLine:   61,
 		},
 		{
-			name:    "Vars Block Hashing",
-			path:    "3a12064c73be460c",
+			name:    "Small Var Declarations Merging",
+			path:    "af024de94b86d24b",
 			summary: "var (",
 			source: `// Variables for testing var parsing
 var (
 	GlobalCounter int
 	SystemReady   bool = true
 	ConfigPath    string
-)`,
-			startLine: 63,
-			endLine:   68,
-		},
-		{
-			name:    "Another Multi Var Declaration",
-			path:    "b9303a3de4b66c8b",
-			summary: "var x, y string",
-			source: `// Another multi var declaration
+)
+
+// Another multi var declaration
 var x, y string`,
-			startLine: 70,
+			startLine: 63,
 			endLine:   71,
 		},
 		{
@@ -551,7 +546,10 @@ func (s *GoParserTestSuite) TestTestFileParsing() {
 
 	s.Equal("tests", chunk.Type)
 	s.Equal("TestSimple", chunk.Path)
+	s.Equal("function_declaration", chunk.Kind)
 	s.Equal("func TestSimple(t *testing.T) {", chunk.Summary)
+	s.Equal("// TestSimple is a basic test function", chunk.Doc)
+	s.Equal(len(chunk.Doc), int(chunk.CodeStart))
 	s.Equal(`// TestSimple is a basic test function
 func TestSimple(t *testing.T) {
 	if 1+1 != 2 {
@@ -563,6 +561,63 @@ func TestSimple(t *testing.T) {
 	s.Equal("go/tests_test.go::TestSimple", chunk.ID())
 }
 
+func (s *GoParserTestSuite) TestOversizedChunkSplitting() {
+	chunks := s.getChunks("go/large.go")
+
+	_, exists := chunks["Large"]
+	s.False(exists, "oversized chunk Large wasn't split")
+
+	small, exists := chunks["Small"]
+	s.Require().True(exists, "chunk %s not found", "Small")
+	s.Empty(small.Parent)
+	s.Equal("go/large.go::Small", small.ParentID())
+
+	var parts []*parser.Chunk
+	for i := 1; ; i++ {
+		part, exists := chunks[fmt.Sprintf("Large#part%d", i)]
+		if !exists {
+			break
+		}
+		parts = append(parts, part)
+	}
+	s.Require().Greater(len(parts), 2)
+
+	s.Equal("// Large builds a report long enough to be split into parts", parts[0].Doc)
+	s.Equal(5, int(parts[0].StartLine))
+	s.Equal(130, int(parts[len(parts)-1].EndLine))
+
+	for i, part := range parts {
+		s.Equal("Large", part.Parent)
+		s.Equal("go/large.go::Large", part.ParentID())
+		s.Equal("func Large() []string {", part.Summary)
+		s.LessOrEqual(len(part.Source), 4000)
+
+		if i > 0 {
+			s.Empty(part.Doc)
+			// Consecutive parts overlap without skipping any lines
+			s.Less(part.StartLine, parts[i-1].EndLine)
+			s.Greater(part.StartLine, parts[i-1].StartLine)
+		}
+	}
+}
+
+func (s *GoParserTestSuite) TestTinyChunkMerging() {
+	chunks := s.getChunks("go/types.go")
+
+	chunk, exists := chunks["af024de94b86d24b"]
+	s.Require().True(exists, "chunk %s not found", "af024de94b86d24b")
+
+	s.Equal("composite", chunk.Kind)
+	s.Equal([]parser.LineRange{{Start: 63, End: 68}, {Start: 70, End: 71}}, chunk.Ranges)
+	s.Equal("lines 63-68, 70-71", chunk.Lines())
+
+	// Named chunks are never merged, however small
+	constant, exists := chunks["DefaultTimeout"]
+	s.Require().True(exists, "chunk %s not found", "DefaultTimeout")
+	s.Empty(constant.Ranges)
+	s.Equal("lines 60-61", constant.Lines())
+}
+
 func TestGoParserTestSuite(t *testing.T) {
 	suite.Run(t, new(GoParserTestSuite))
 }
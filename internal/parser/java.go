@@ -0,0 +1,105 @@
+package parser
+
+import (
+	"regexp"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+	tree_sitter_java "github.com/tree-sitter/tree-sitter-java/bindings/go"
+)
+
+var JavaSpec = &LanguageSpec{
+	NamedChunks: map[string]NamedChunkExtractor{
+		"class_declaration": {
+			NameQuery: `(class_declaration name: (identifier) @name)`,
+		},
+		"interface_declaration": {
+			NameQuery: `(interface_declaration name: (identifier) @name)`,
+		},
+		"enum_declaration": {
+			NameQuery: `(enum_declaration name: (identifier) @name)`,
+		},
+		"record_declaration": {
+			NameQuery: `(record_declaration name: (identifier) @name)`,
+		},
+		"annotation_type_declaration": {
+			NameQuery: `(annotation_type_declaration name: (identifier) @name)`,
+		},
+		"annotation_type_element_declaration": {
+			NameQuery: `(annotation_type_element_declaration name: (identifier) @name)`,
+		},
+		"constructor_declaration": {
+			NameQuery: `(constructor_declaration name: (identifier) @name)`,
+		},
+		"compact_constructor_declaration": {
+			NameQuery: `(compact_constructor_declaration name: (identifier) @name)`,
+		},
+		"method_declaration": {
+			NameQuery: `(method_declaration name: (identifier) @name)`,
+		},
+		// Declarations of several variables are named after the first one
+		"field_declaration": {
+			NameQuery: `(field_declaration type: (_) . declarator: (variable_declarator name: (identifier) @name))`,
+		},
+		"constant_declaration": {
+			NameQuery: `(constant_declaration type: (_) . declarator: (variable_declarator name: (identifier) @name))`,
+		},
+	},
+	ExtractChildrenIn: []string{
+		"class_declaration",
+		"interface_declaration",
+		"enum_declaration",
+		"record_declaration",
+		"annotation_type_declaration",
+		"class_body",
+		"interface_body",
+		"enum_body",
+		"enum_body_declarations",
+		"annotation_type_body",
+	},
+	// Javadoc and other comments
+	FoldIntoNextNode: []string{"line_comment", "block_comment"},
+	SkipTypes: []string{
+		// Imports and the package clause pollute search results
+		"package_declaration",
+		"import_declaration",
+		// Skip the modifiers, names and supertypes of type declarations
+		"modifiers",
+		"class", "interface", "enum", "record", "@interface",
+		"identifier",
+		"type_parameters",
+		"superclass",
+		"super_interfaces",
+		"extends_interfaces",
+		"permits",
+		"formal_parameters",
+		// Enum constants are part of their enum's chunk
+		"enum_constant",
+		// Skip punctuation tokens
+		"{", "}", ";", ",",
+		// Skip container nodes (but still extract their children)
+		"class_body",
+		"interface_body",
+		"enum_body",
+		"enum_body_declarations",
+		"annotation_type_body",
+	},
+	FileTypeRules: []FileTypeRule{
+		{Pattern: "**/src/test/java/**", Type: FileTypeTests},
+		{Pattern: "target/**", Type: FileTypeIgnore},
+		{Pattern: "build/**", Type: FileTypeIgnore},
+	},
+	// Annotations on their own line, e.g. @Override
+	SummarySkipLines: regexp.MustCompile(`^@[\w.]+(\(.*\))?$`),
+	RootedQueries:    true,
+}
+
+func NewJavaParser(workspaceRoot string) (*Parser, error) {
+	parser := tree_sitter.NewParser()
+	parser.SetLanguage(tree_sitter.NewLanguage(tree_sitter_java.Language()))
+
+	return &Parser{
+		workspaceRoot: workspaceRoot,
+		parser:        parser,
+		spec:          JavaSpec,
+	}, nil
+}
@@ -0,0 +1,238 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/st3v3nmw/sourcerer-mcp/internal/parser"
+	"github.com/stretchr/testify/suite"
+)
+
+type JavaParserTestSuite struct {
+	ParserBaseTestSuite
+}
+
+func (s *JavaParserTestSuite) SetupSuite() {
+	s.ParserBaseTestSuite.SetupSuite()
+
+	var err error
+	s.parser, err = parser.NewJavaParser(s.workspaceRoot)
+	s.Require().NoError(err)
+}
+
+func (s *JavaParserTestSuite) TestDeclarationParsing() {
+	chunks := s.getChunks("java/src/main/java/com/example/Library.java")
+
+	tests := []struct {
+		name      string
+		path      string
+		kind      string
+		summary   string
+		startLine int
+		endLine   int
+	}{
+		{
+			name:      "Class With Javadoc",
+			path:      "Library",
+			kind:      "class_declaration",
+			summary:   "public class Library {",
+			startLine: 6,
+			endLine:   51,
+		},
+		{
+			name:      "Constant Field",
+			path:      "Library::CAPACITY",
+			kind:      "field_declaration",
+			summary:   "public static final int CAPACITY = 100;",
+			startLine: 10,
+			endLine:   11,
+		},
+		{
+			name:      "Field",
+			path:      "Library::books",
+			kind:      "field_declaration",
+			summary:   "private final List<Book> books = new ArrayList<>();",
+			startLine: 13,
+			endLine:   13,
+		},
+		{
+			name:      "Constructor",
+			path:      "Library::Library",
+			kind:      "constructor_declaration",
+			summary:   "public Library() {",
+			startLine: 15,
+			endLine:   19,
+		},
+		{
+			name:      "Annotated Method",
+			path:      "Library::add",
+			kind:      "method_declaration",
+			summary:   "public boolean add(Book book) {",
+			startLine: 21,
+			endLine:   28,
+		},
+		{
+			name:      "Method With Anonymous Class",
+			path:      "Library::cataloguer",
+			kind:      "method_declaration",
+			summary:   "public Runnable cataloguer() {",
+			startLine: 30,
+			endLine:   37,
+		},
+		{
+			name:      "Nested Class",
+			path:      "Library::Book",
+			kind:      "class_declaration",
+			summary:   "public static class Book {",
+			startLine: 39,
+			endLine:   50,
+		},
+		{
+			name:      "Nested Class Method",
+			path:      "Library::Book::getTitle",
+			kind:      "method_declaration",
+			summary:   "public String getTitle() {",
+			startLine: 47,
+			endLine:   49,
+		},
+		{
+			name:      "Interface",
+			path:      "Shelf",
+			kind:      "interface_declaration",
+			summary:   "interface Shelf {",
+			startLine: 53,
+			endLine:   57,
+		},
+		{
+			name:      "Interface Constant",
+			path:      "Shelf::SIZE",
+			kind:      "constant_declaration",
+			summary:   "int SIZE = 10;",
+			startLine: 54,
+			endLine:   54,
+		},
+		{
+			name:      "Interface Method",
+			path:      "Shelf::place",
+			kind:      "method_declaration",
+			summary:   "void place(Library.Book book);",
+			startLine: 56,
+			endLine:   56,
+		},
+		{
+			name:      "Enum",
+			path:      "Genre",
+			kind:      "enum_declaration",
+			summary:   "enum Genre {",
+			startLine: 59,
+			endLine:   66,
+		},
+		{
+			name:      "Enum Method",
+			path:      "Genre::label",
+			kind:      "method_declaration",
+			summary:   "public String label() {",
+			startLine: 63,
+			endLine:   65,
+		},
+		{
+			name:      "Record",
+			path:      "Loan",
+			kind:      "record_declaration",
+			summary:   "record Loan(String member, Library.Book book) {",
+			startLine: 68,
+			endLine:   72,
+		},
+		{
+			name:      "Compact Constructor",
+			path:      "Loan::Loan",
+			kind:      "compact_constructor_declaration",
+			summary:   "Loan {",
+			startLine: 69,
+			endLine:   71,
+		},
+		{
+			name:      "Annotation",
+			path:      "Catalogued",
+			kind:      "annotation_type_declaration",
+			summary:   "@interface Catalogued {",
+			startLine: 74,
+			endLine:   76,
+		},
+		{
+			name:      "Annotation Element",
+			path:      "Catalogued::section",
+			kind:      "annotation_type_element_declaration",
+			summary:   `String section() default "general";`,
+			startLine: 75,
+			endLine:   75,
+		},
+		{
+			name:      "Field With Several Variables",
+			path:      "Order::quantity",
+			kind:      "field_declaration",
+			summary:   "private int quantity, total;",
+			startLine: 79,
+			endLine:   79,
+		},
+		{
+			name:      "Constant With Several Variables",
+			path:      "Limits::MIN",
+			kind:      "constant_declaration",
+			summary:   "int MIN = 1, MAX = 10;",
+			startLine: 83,
+			endLine:   83,
+		},
+	}
+
+	for _, test := range tests {
+		s.Run(test.name, func() {
+			chunk, exists := chunks[test.path]
+			s.Require().True(exists, "chunk %s not found", test.path)
+			s.Require().NotNil(chunk)
+
+			s.Equal("src", chunk.Type)
+			s.Equal(test.kind, chunk.Kind)
+			s.Equal(test.summary, chunk.Summary)
+			s.Equal(test.startLine, int(chunk.StartLine))
+			s.Equal(test.endLine, int(chunk.EndLine))
+			s.Equal("java/src/main/java/com/example/Library.java::"+test.path, chunk.ID())
+		})
+	}
+
+	s.Run("Javadoc Folded", func() {
+		s.Equal("/**\n     * Creates an empty library.\n     */", chunks["Library::Library"].Doc)
+		s.Equal("// Adds a book unless the library is full", chunks["Library::add"].Doc)
+	})
+
+	s.Run("Imports Skipped", func() {
+		for _, chunk := range chunks {
+			s.NotContains(chunk.Source, "import java.util")
+		}
+	})
+}
+
+func (s *JavaParserTestSuite) TestTestFileParsing() {
+	chunks := s.getChunks("java/src/test/java/com/example/LibraryTest.java")
+
+	chunk, exists := chunks["LibraryTest::addsBooks"]
+	s.Require().True(exists)
+	s.Equal("tests", chunk.Type)
+	s.Equal("void addsBooks() {", chunk.Summary)
+}
+
+func (s *JavaParserTestSuite) TestBuildOutputIgnored() {
+	for _, filePath := range []string{"target/classes/Library.java", "build/generated/Library.java"} {
+		_, err := s.parser.Chunk(filePath)
+		s.ErrorContains(err, "marked as ignore")
+	}
+}
+
+func (s *JavaParserTestSuite) TearDownSuite() {
+	if s.parser != nil {
+		s.parser.Close()
+	}
+}
+
+func TestJavaParserTestSuite(t *testing.T) {
+	suite.Run(t, new(JavaParserTestSuite))
+}
@@ -1,6 +1,8 @@
 package parser_test
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/st3v3nmw/sourcerer-mcp/internal/parser"
@@ -721,43 +723,24 @@ const simple_arrow = () => "simple";`,
 			endLine:   32,
 		},
 		{
-			name:    "Arrow With Body",
-			path:    "2370e5908d333e83",
+			name:    "Small Declarations Merging",
+			path:    "12fbc222b7793a90",
 			summary: "const arrow_with_body = (x) => {",
 			source: `const arrow_with_body = (x) => {
     const result = x * 2;
     return result;
-};`,
-			startLine: 33,
-			endLine:   36,
-		},
-		{
-			name:    "Async Arrow Func",
-			path:    "7d9a98184af91c01",
-			summary: "const async_arrow_func = async (data) => {",
-			source: `// Async arrow function
+};
+
+// Async arrow function
 const async_arrow_func = async (data) => {
     const response = await fetch(data);
     return response;
-};`,
-			startLine: 38,
-			endLine:   42,
-		},
-		{
-			name:    "Destructuring Assignment",
-			path:    "69d42be7a90295c0",
-			summary: "let {name, age} = person;",
-			source: `// Complex variable declarations
-let {name, age} = person;`,
-			startLine: 44,
-			endLine:   45,
-		},
-		{
-			name:      "Array Destructuring Assignment",
-			path:      "d7688b14f2c5cde",
-			summary:   "const [first, ...rest] = numbers;",
-			source:    `const [first, ...rest] = numbers;`,
-			startLine: 46,
+};
+
+// Complex variable declarations
+let {name, age} = person;
+const [first, ...rest] = numbers;`,
+			startLine: 33,
 			endLine:   46,
 		},
 		{
@@ -818,6 +801,40 @@ func (s *JavaScriptParserTestSuite) TearDownSuite() {
 	}
 }
 
+func (s *JavaScriptParserTestSuite) TestLongLineSplitting() {
+	chunks := s.getChunks("javascript/minified.js")
+
+	_, exists := chunks["lookupTable"]
+	s.False(exists, "oversized chunk lookupTable wasn't split")
+
+	var parts []*parser.Chunk
+	for i := 1; ; i++ {
+		part, exists := chunks[fmt.Sprintf("lookupTable#part%d", i)]
+		if !exists {
+			break
+		}
+		parts = append(parts, part)
+	}
+	s.Require().Greater(len(parts), 2)
+
+	s.Equal("// Lookup table generated at build time", parts[0].Doc)
+	s.True(strings.HasPrefix(parts[0].Source, "// Lookup table generated at build time\nfunction lookupTable() {"))
+	s.True(strings.HasSuffix(parts[len(parts)-1].Source, "]; }"))
+
+	for i, part := range parts {
+		s.Equal("lookupTable", part.Parent)
+		s.LessOrEqual(len(part.Source), 4000)
+
+		if i > 0 {
+			// The 10k character line is split into overlapping spans
+			s.Equal(2, int(part.StartLine))
+			s.Equal(2, int(part.EndLine))
+			s.Less(part.StartColumn, parts[i-1].EndColumn)
+			s.Greater(part.StartColumn, parts[i-1].StartColumn)
+		}
+	}
+}
+
 func TestJavaScriptParserTestSuite(t *testing.T) {
 	suite.Run(t, new(JavaScriptParserTestSuite))
 }
@@ -0,0 +1,126 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cespare/xxhash"
+)
+
+const (
+	// tinyChunkChars is the size below which unnamed chunks are merged with
+	// their unnamed neighbours, alone they embed to low-information vectors
+	tinyChunkChars = 200
+	// maxCompositeChars caps the size of a composite chunk
+	maxCompositeChars = 1000
+)
+
+// LineRange is a span of lines in a file
+type LineRange struct {
+	Start uint
+	End   uint
+}
+
+func (r LineRange) String() string {
+	if r.Start == r.End {
+		return fmt.Sprintf("%d", r.Start)
+	}
+
+	return fmt.Sprintf("%d-%d", r.Start, r.End)
+}
+
+// Lines describes the lines a chunk spans, listing the ranges of the chunks
+// it's made of for composite chunks
+func (c *Chunk) Lines() string {
+	if len(c.Ranges) > 0 {
+		ranges := make([]string, len(c.Ranges))
+		for i, r := range c.Ranges {
+			ranges[i] = r.String()
+		}
+
+		return "lines " + strings.Join(ranges, ", ")
+	}
+
+	if c.StartLine == c.EndLine {
+		return fmt.Sprintf("line %d", c.StartLine)
+	}
+
+	return fmt.Sprintf("lines %d-%d", c.StartLine, c.EndLine)
+}
+
+// mergeTinyChunks merges runs of adjacent tiny unnamed chunks into composite
+// chunks of up to maxCompositeChars. Chunks are only adjacent when nothing
+// but whitespace separates them, so a composite's source is still exactly
+// what's in the file.
+func mergeTinyChunks(chunks []*Chunk, source []byte, usedPaths map[string]bool) []*Chunk {
+	var result []*Chunk
+	var run []*Chunk
+
+	flush := func() {
+		if len(run) == 1 {
+			result = append(result, run[0])
+		} else if len(run) > 1 {
+			result = append(result, newCompositeChunk(run, source, usedPaths))
+		}
+		run = nil
+	}
+
+	for _, chunk := range chunks {
+		if !chunk.hashed || len(chunk.Source) >= tinyChunkChars {
+			flush()
+			result = append(result, chunk)
+			continue
+		}
+
+		if len(run) > 0 {
+			first, last := run[0], run[len(run)-1]
+			adjacent := chunk.startByte >= last.endByte &&
+				strings.TrimSpace(string(source[last.endByte:chunk.startByte])) == ""
+			if !adjacent || int(chunk.endByte-first.startByte) > maxCompositeChars {
+				flush()
+			}
+		}
+
+		run = append(run, chunk)
+	}
+	flush()
+
+	return result
+}
+
+// newCompositeChunk merges adjacent chunks into one, named by its content
+// hash like the chunks it's made of
+func newCompositeChunk(run []*Chunk, source []byte, usedPaths map[string]bool) *Chunk {
+	first, last := run[0], run[len(run)-1]
+	text := string(source[first.startByte:last.endByte])
+
+	var ranges []LineRange
+	for _, chunk := range run {
+		delete(usedPaths, chunk.Path)
+
+		if len(chunk.Ranges) > 0 {
+			ranges = append(ranges, chunk.Ranges...)
+		} else {
+			ranges = append(ranges, LineRange{Start: chunk.StartLine, End: chunk.EndLine})
+		}
+	}
+
+	hash := fmt.Sprintf("%x", xxhash.Sum64String(text))
+
+	return &Chunk{
+		Path:        resolvePath(hash, usedPaths),
+		Type:        first.Type,
+		Kind:        "composite",
+		Summary:     first.Summary,
+		Source:      text,
+		StartLine:   first.StartLine,
+		StartColumn: first.StartColumn,
+		EndLine:     last.EndLine,
+		EndColumn:   last.EndColumn,
+		ParsedAt:    first.ParsedAt,
+		Ranges:      ranges,
+		startByte:   first.startByte,
+		endByte:     last.endByte,
+		hashed:      true,
+	}
+}
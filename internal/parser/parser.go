@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"regexp"
 	"slices"
 	"strings"
 	"time"
@@ -33,9 +34,10 @@ const (
 
 // File represents a parsed source file with its extracted semantic chunks
 type File struct {
-	Path   string // path within workspace
-	Chunks []*Chunk
-	Source []byte
+	Path     string // path within workspace
+	Language string // language name, as registered with the analyzer
+	Chunks   []*Chunk
+	Source   []byte
 
 	tree *tree_sitter.Tree
 }
@@ -45,13 +47,22 @@ type Chunk struct {
 	File        string // file path within workspace
 	Type        string
 	Path        string // path within file
+	Parent      string // path of the oversized chunk this is a part of, if any
+	Kind        string // tree-sitter node type, e.g. method_declaration
 	Summary     string
+	Doc         string // comments preceding the chunk, also part of Source
 	Source      string
+	CodeStart   uint // offset in Source of the code following Doc
 	StartLine   uint
 	StartColumn uint
 	EndLine     uint
 	EndColumn   uint
 	ParsedAt    int64
+	Ranges      []LineRange // lines of the chunks merged into a composite chunk, if any
+
+	startByte uint
+	endByte   uint
+	hashed    bool // named by a content hash rather than by a declaration
 }
 
 // ID returns a unique identifier for this chunk in the format "file::path"
@@ -59,6 +70,16 @@ func (c *Chunk) ID() string {
 	return c.File + "::" + c.Path
 }
 
+// ParentID returns the ID of the chunk this one is a part of, or its own ID
+// if it wasn't split
+func (c *Chunk) ParentID() string {
+	if c.Parent == "" {
+		return c.ID()
+	}
+
+	return c.File + "::" + c.Parent
+}
+
 // newChunk creates a new Chunk from related tree-sitter nodes
 func (p *Parser) newChunk(
 	node *tree_sitter.Node,
@@ -82,19 +103,25 @@ func (p *Parser) newChunk(
 		}
 	}
 
-	summaryText := summaryNode.Utf8Text(source)
+	summaryText := skipLeadingLines(summaryNode.Utf8Text(source), p.spec.SummarySkipLines)
 	fullText := source[startByte:endByte]
 
 	return &Chunk{
 		Path:        finalPath,
 		Type:        string(fileType),
+		Kind:        node.Kind(),
 		Summary:     summarize(summaryText),
+		Doc:         docComment(folded, source),
 		Source:      string(fullText),
+		CodeStart:   docEnd(folded, startByte),
 		StartLine:   startPos.Row + 1,
 		StartColumn: startPos.Column + 1,
 		EndLine:     endPos.Row + 1,
 		EndColumn:   endPos.Column + 1,
 		ParsedAt:    time.Now().Unix(),
+		startByte:   startByte,
+		endByte:     endByte,
+		hashed:      extractor == nil,
 	}
 }
 
@@ -135,6 +162,50 @@ func calculateChunkBounds(node *tree_sitter.Node, folded []*tree_sitter.Node) (
 	return startPos, startByte, endPos, endByte
 }
 
+// docComment joins the comments folded into a chunk, skipping other folded
+// nodes such as export keywords
+func docComment(folded []*tree_sitter.Node, source []byte) string {
+	var comments []string
+	for _, node := range folded {
+		if strings.Contains(node.Kind(), "comment") {
+			// Some grammars, e.g. Rust's, include the newline in line comments
+			comments = append(comments, strings.TrimRight(node.Utf8Text(source), "\r\n"))
+		}
+	}
+
+	return strings.Join(comments, "\n")
+}
+
+// docEnd returns the offset of the end of the last comment in folded from
+// the start of the chunk, 0 if there are none
+func docEnd(folded []*tree_sitter.Node, startByte uint) uint {
+	var end uint
+	for _, node := range folded {
+		if strings.Contains(node.Kind(), "comment") {
+			end = max(end, node.EndByte()-startByte)
+		}
+	}
+
+	return end
+}
+
+// skipLeadingLines drops the lines text starts with that match pattern,
+// unless that leaves nothing
+func skipLeadingLines(text string, pattern *regexp.Regexp) string {
+	if pattern == nil {
+		return text
+	}
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if !pattern.MatchString(strings.TrimSpace(line)) {
+			return strings.Join(lines[i:], "\n")
+		}
+	}
+
+	return text
+}
+
 // summarize creates a concise summary from source code, truncating at word boundaries
 // when the first line exceeds the maximum character limit
 func summarize(source string) string {
@@ -165,6 +236,12 @@ type LanguageSpec struct {
 	FoldIntoNextNode  []string                       // node types to fold into next node, e.g., comments
 	SkipTypes         []string                       // node types to completely skip
 	FileTypeRules     []FileTypeRule                 // language-specific file type classification rules
+	TestChunkNames    []string                       // names of chunks holding tests, e.g. Rust's tests modules
+	TestChunkMarkers  []string                       // folded nodes marking the next chunk as holding tests, e.g. #[cfg(test)]
+	SummarySkipLines  *regexp.Regexp                 // leading lines left out of summaries, e.g. annotations
+	// RootedQueries only matches extractor queries at the node being chunked,
+	// not at declarations nested in it such as inner or anonymous classes
+	RootedQueries bool
 }
 
 // NamedChunkExtractor defines tree-sitter queries for extracting named code entities
@@ -172,6 +249,9 @@ type NamedChunkExtractor struct {
 	NameQuery        string // query to extract the entity name
 	ParentNameQuery  string // optional query to extract parent entity name for hierarchical paths
 	SummaryNodeQuery string // optional query to extract a specific node for the summary instead of the main node
+	ChildrenQuery    string // optional query for nodes whose children are extracted too, e.g. RSpec describe blocks
+	Separator        string // optional separator between the parent path and the name instead of "::"
+	TrimPrefix       string // optional prefix trimmed off the name, e.g. the colon of a Ruby symbol
 }
 
 // FileTypeRule defines a pattern-based rule for classifying file types
@@ -236,7 +316,7 @@ func (p *Parser) Chunk(filePath string) (*File, error) {
 		return nil, err
 	}
 
-	file.Chunks = p.extractChunks(file.tree.RootNode(), file.Source, "", fileType, nil)
+	file.Chunks = splitOversized(p.extractChunks(file.tree.RootNode(), file.Source, "", fileType, nil))
 	for i := range len(file.Chunks) {
 		file.Chunks[i].File = file.Path
 	}
@@ -316,6 +396,12 @@ func (p *Parser) extractChunks(
 		}
 
 		chunk, path := p.createChunkFromNode(child, source, parentPath, fileType, usedPaths, folded)
+		childType := fileType
+		if chunk != nil && p.holdsTests(chunk, folded, source) {
+			chunk.Type = string(FileTypeTests)
+			childType = FileTypeTests
+		}
+
 		if chunk != nil {
 			chunks = append(chunks, chunk)
 			folded = nil
@@ -323,11 +409,17 @@ func (p *Parser) extractChunks(
 
 		// Recursively process children if specified
 		if slices.Contains(p.spec.ExtractChildrenIn, kind) {
-			childChunks := p.extractChunks(child, source, path, fileType, folded)
+			childChunks := p.extractChunks(child, source, path, childType, folded)
 			chunks = append(chunks, childChunks...)
 			folded = nil
 		}
 
+		if chunk != nil && !chunk.hashed {
+			for _, container := range p.childContainers(child, source) {
+				chunks = append(chunks, p.extractChunks(container, source, path, childType, nil)...)
+			}
+		}
+
 		for _, foldedNode := range folded {
 			chunks = append(chunks, p.extractHashedNode(foldedNode, source, usedPaths, fileType, nil))
 		}
@@ -340,7 +432,45 @@ func (p *Parser) extractChunks(
 	}
 	folded = nil
 
-	return chunks
+	return mergeTinyChunks(chunks, source, usedPaths)
+}
+
+// holdsTests reports whether a chunk, along with the chunks nested in it, is
+// test code going by its name or the nodes folded into it
+func (p *Parser) holdsTests(chunk *Chunk, folded []*tree_sitter.Node, source []byte) bool {
+	if chunk.hashed {
+		return false
+	}
+
+	names := strings.Split(chunk.Path, "::")
+	if slices.Contains(p.spec.TestChunkNames, names[len(names)-1]) {
+		return true
+	}
+
+	for _, node := range folded {
+		marker := strings.Join(strings.Fields(node.Utf8Text(source)), "")
+		if slices.Contains(p.spec.TestChunkMarkers, marker) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// childContainers returns the nodes a named chunk's children are extracted
+// from, going by its extractor's ChildrenQuery
+func (p *Parser) childContainers(node *tree_sitter.Node, source []byte) []*tree_sitter.Node {
+	extractor, exists := p.spec.NamedChunks[node.Kind()]
+	if !exists || extractor.ChildrenQuery == "" {
+		return nil
+	}
+
+	containers, err := p.executeQuery(extractor.ChildrenQuery, node, source)
+	if err != nil {
+		return nil
+	}
+
+	return containers
 }
 
 // createChunkFromNode creates a chunk from a code node, attempting named extraction first
@@ -354,12 +484,13 @@ func (p *Parser) createChunkFromNode(
 	folded []*tree_sitter.Node,
 ) (*Chunk, string) {
 	kind := node.Kind()
+	extractor, exists := p.spec.NamedChunks[kind]
 
-	if slices.Contains(p.spec.SkipTypes, kind) {
+	// Keyword tokens can share their kind with the node they start, e.g. Ruby's class
+	if slices.Contains(p.spec.SkipTypes, kind) && !(exists && node.IsNamed()) {
 		return nil, parentPath
 	}
 
-	extractor, exists := p.spec.NamedChunks[kind]
 	if exists {
 		chunkPath, err := p.buildChunkPath(extractor, node, source, parentPath)
 		if err == nil {
@@ -397,6 +528,7 @@ func (p *Parser) buildChunkPath(
 	if err != nil {
 		return "", err
 	}
+	path = strings.TrimPrefix(path, extractor.TrimPrefix)
 
 	if extractor.ParentNameQuery != "" {
 		parentName, err := p.getNamedNodePath(extractor.ParentNameQuery, child, source)
@@ -407,7 +539,11 @@ func (p *Parser) buildChunkPath(
 	}
 
 	if parentPath != "" {
-		path = parentPath + "::" + path
+		separator := "::"
+		if extractor.Separator != "" {
+			separator = extractor.Separator
+		}
+		path = parentPath + separator + path
 	}
 
 	return path, nil
@@ -449,10 +585,21 @@ func (p *Parser) executeQuery(
 	cursor := tree_sitter.NewQueryCursor()
 	defer cursor.Close()
 
+	if p.spec.RootedQueries {
+		rootOnly := uint(0)
+		cursor.SetMaxStartDepth(&rootOnly)
+	}
+
 	var results []*tree_sitter.Node
+	captureNames := query.CaptureNames()
 	matches := cursor.Matches(query, node, source)
 	for match := matches.Next(); match != nil; match = matches.Next() {
 		for _, capture := range match.Captures {
+			// Captures like @_method are only there for predicates
+			if strings.HasPrefix(captureNames[capture.Index], "_") {
+				continue
+			}
+
 			results = append(results, &capture.Node)
 		}
 	}
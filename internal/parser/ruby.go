@@ -0,0 +1,95 @@
+package parser
+
+import (
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+	tree_sitter_ruby "github.com/tree-sitter/tree-sitter-ruby/bindings/go"
+)
+
+// rspecGroup matches the RSpec calls taking a block, which are named by their
+// description, e.g. describe "#checkout" do ... end
+const rspecGroup = `
+	(call
+		method: (identifier) @_method
+		arguments: (argument_list .
+			[(string (string_content) @name) (constant) @name (scope_resolution) @name])
+		block: [(do_block) (block)]
+		(#match? @_method "^(describe|context|it|specify)$"))`
+
+var RubySpec = &LanguageSpec{
+	NamedChunks: map[string]NamedChunkExtractor{
+		"module": {
+			NameQuery: `(module name: [(constant) (scope_resolution)] @name)`,
+		},
+		"class": {
+			NameQuery: `(class name: [(constant) (scope_resolution)] @name)`,
+		},
+		"method": {
+			NameQuery: `(method name: (_) @name)`,
+		},
+		// Methods defined on self are pathed the way Ruby docs write them,
+		// e.g. Book.find, so they don't clash with instance methods
+		"singleton_method": {
+			NameQuery: `(singleton_method name: (_) @name)`,
+			Separator: ".",
+		},
+		"assignment": {
+			NameQuery: `(assignment left: (constant) @name)`,
+		},
+		// attr_reader and friends, named after their first attribute, and
+		// RSpec example groups and examples
+		"call": {
+			NameQuery: `
+				(call
+					!receiver
+					method: (identifier) @_method
+					arguments: (argument_list .
+						[(simple_symbol) @name (string (string_content) @name)])
+					(#match? @_method "^attr_(reader|writer|accessor)$"))
+				` + rspecGroup,
+			TrimPrefix: ":",
+			ChildrenQuery: `
+				(call
+					method: (identifier) @_method
+					block: [
+						(do_block body: (body_statement) @body)
+						(block body: (block_body) @body)]
+					(#match? @_method "^(describe|context)$"))`,
+		},
+	},
+	ExtractChildrenIn: []string{
+		"module",
+		"class",
+		"singleton_class",
+		"body_statement",
+	},
+	FoldIntoNextNode: []string{"comment"},
+	SkipTypes: []string{
+		// Skip keyword tokens of modules and classes
+		"module", "class", "end", "<<", "self",
+		// Skip the names and superclasses of modules and classes
+		"constant",
+		"scope_resolution",
+		"superclass",
+		// Skip visibility modifiers, e.g. private
+		"identifier",
+		// Skip container nodes (but still extract their children)
+		"singleton_class",
+		"body_statement",
+	},
+	FileTypeRules: []FileTypeRule{
+		{Pattern: "**/spec/**", Type: FileTypeTests},
+		{Pattern: "vendor/bundle/**", Type: FileTypeIgnore},
+	},
+	RootedQueries: true,
+}
+
+func NewRubyParser(workspaceRoot string) (*Parser, error) {
+	parser := tree_sitter.NewParser()
+	parser.SetLanguage(tree_sitter.NewLanguage(tree_sitter_ruby.Language()))
+
+	return &Parser{
+		workspaceRoot: workspaceRoot,
+		parser:        parser,
+		spec:          RubySpec,
+	}, nil
+}
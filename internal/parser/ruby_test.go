@@ -0,0 +1,286 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/st3v3nmw/sourcerer-mcp/internal/parser"
+	"github.com/stretchr/testify/suite"
+)
+
+type RubyParserTestSuite struct {
+	ParserBaseTestSuite
+}
+
+func (s *RubyParserTestSuite) SetupSuite() {
+	s.ParserBaseTestSuite.SetupSuite()
+
+	var err error
+	s.parser, err = parser.NewRubyParser(s.workspaceRoot)
+	s.Require().NoError(err)
+}
+
+func (s *RubyParserTestSuite) TestDefinitionParsing() {
+	chunks := s.getChunks("ruby/lib/library.rb")
+
+	tests := []struct {
+		name      string
+		path      string
+		kind      string
+		summary   string
+		startLine int
+		endLine   int
+	}{
+		{
+			name:      "Module",
+			path:      "Library",
+			kind:      "module",
+			summary:   "module Library",
+			startLine: 3,
+			endLine:   57,
+		},
+		{
+			name:      "Constant",
+			path:      "Library::MAX_LOANS",
+			kind:      "assignment",
+			summary:   "MAX_LOANS = 5",
+			startLine: 5,
+			endLine:   5,
+		},
+		{
+			name:      "Class",
+			path:      "Library::Book",
+			kind:      "class",
+			summary:   "class Book",
+			startLine: 7,
+			endLine:   38,
+		},
+		{
+			name:      "Attribute Reader",
+			path:      "Library::Book::title",
+			kind:      "call",
+			summary:   "attr_reader :title, :author",
+			startLine: 11,
+			endLine:   11,
+		},
+		{
+			name:      "Attribute Accessor",
+			path:      "Library::Book::available",
+			kind:      "call",
+			summary:   "attr_accessor :available",
+			startLine: 12,
+			endLine:   12,
+		},
+		{
+			name:      "Method",
+			path:      "Library::Book::initialize",
+			kind:      "method",
+			summary:   "def initialize(title, author)",
+			startLine: 14,
+			endLine:   18,
+		},
+		{
+			name:      "Singleton Method",
+			path:      "Library::Book.find",
+			kind:      "singleton_method",
+			summary:   "def self.find(title)",
+			startLine: 20,
+			endLine:   23,
+		},
+		{
+			name:      "Operator Method",
+			path:      "Library::Book::<=>",
+			kind:      "method",
+			summary:   "def <=>(other)",
+			startLine: 25,
+			endLine:   27,
+		},
+		{
+			name:      "Predicate Method",
+			path:      "Library::Book::available?",
+			kind:      "method",
+			summary:   "def available?",
+			startLine: 29,
+			endLine:   31,
+		},
+		{
+			name:      "Private Method",
+			path:      "Library::Book::reset!",
+			kind:      "method",
+			summary:   "def reset!",
+			startLine: 35,
+			endLine:   37,
+		},
+		{
+			name:      "Subclass",
+			path:      "Library::Patron",
+			kind:      "class",
+			summary:   "class Patron < Struct.new(:name)",
+			startLine: 40,
+			endLine:   50,
+		},
+		{
+			name:      "Method In Singleton Class",
+			path:      "Library::Patron::anonymous",
+			kind:      "method",
+			summary:   "def anonymous",
+			startLine: 42,
+			endLine:   44,
+		},
+		{
+			name:      "Scoped Module",
+			path:      "Library::Loans::Policy",
+			kind:      "module",
+			summary:   "module Loans::Policy",
+			startLine: 52,
+			endLine:   56,
+		},
+		{
+			name:      "Scoped Module Singleton Method",
+			path:      "Library::Loans::Policy.limit",
+			kind:      "singleton_method",
+			summary:   "def self.limit",
+			startLine: 53,
+			endLine:   55,
+		},
+	}
+
+	for _, test := range tests {
+		s.Run(test.name, func() {
+			chunk, exists := chunks[test.path]
+			s.Require().True(exists, "chunk %s not found", test.path)
+			s.Require().NotNil(chunk)
+
+			s.Equal("src", chunk.Type)
+			s.Equal(test.kind, chunk.Kind)
+			s.Equal(test.summary, chunk.Summary)
+			s.Equal(test.startLine, int(chunk.StartLine))
+			s.Equal(test.endLine, int(chunk.EndLine))
+			s.Equal("ruby/lib/library.rb::"+test.path, chunk.ID())
+		})
+	}
+
+	s.Run("Singleton And Instance Methods", func() {
+		singleton, exists := chunks["Catalog.search"]
+		s.Require().True(exists)
+		s.Equal("def self.search(query)", singleton.Summary)
+
+		instance, exists := chunks["Catalog::search"]
+		s.Require().True(exists)
+		s.Equal("def search(query)", instance.Summary)
+	})
+
+	s.Run("Attributes Named By First Symbol", func() {
+		books, exists := chunks["Catalog::books"]
+		s.Require().True(exists)
+		s.Equal("attr_reader :books", books.Summary)
+
+		shelves, exists := chunks["Catalog::shelves"]
+		s.Require().True(exists)
+		s.Equal("attr_reader :shelves", shelves.Summary)
+	})
+
+	s.Run("Doc Comments", func() {
+		s.Equal("# Books and the patrons borrowing them.", chunks["Library"].Doc)
+		s.Equal("# Finds a book by its title.", chunks["Library::Book.find"].Doc)
+	})
+
+	s.Run("Visibility Modifiers Skipped", func() {
+		for _, chunk := range chunks {
+			s.NotEqual("private", chunk.Source)
+		}
+	})
+}
+
+func (s *RubyParserTestSuite) TestRSpecParsing() {
+	chunks := s.getChunks("ruby/spec/library_spec.rb")
+
+	tests := []struct {
+		name      string
+		path      string
+		summary   string
+		startLine int
+		endLine   int
+	}{
+		{
+			name:      "Example Group",
+			path:      "Library::Book",
+			summary:   "RSpec.describe Library::Book do",
+			startLine: 3,
+			endLine:   18,
+		},
+		{
+			name:      "Nested Example Group",
+			path:      "Library::Book::#available?",
+			summary:   `describe "#available?" do`,
+			startLine: 6,
+			endLine:   17,
+		},
+		{
+			name:      "Example",
+			path:      "Library::Book::#available?::is true for new books",
+			summary:   `it "is true for new books" do`,
+			startLine: 7,
+			endLine:   9,
+		},
+		{
+			name:      "Context",
+			path:      "Library::Book::#available?::when checked out",
+			summary:   `context "when checked out" do`,
+			startLine: 11,
+			endLine:   16,
+		},
+		{
+			name:      "Example In Context",
+			path:      "Library::Book::#available?::when checked out::is false",
+			summary:   `it "is false" do`,
+			startLine: 12,
+			endLine:   15,
+		},
+	}
+
+	for _, test := range tests {
+		s.Run(test.name, func() {
+			chunk, exists := chunks[test.path]
+			s.Require().True(exists, "chunk %s not found", test.path)
+			s.Require().NotNil(chunk)
+
+			s.Equal("tests", chunk.Type)
+			s.Equal("call", chunk.Kind)
+			s.Equal(test.summary, chunk.Summary)
+			s.Equal(test.startLine, int(chunk.StartLine))
+			s.Equal(test.endLine, int(chunk.EndLine))
+		})
+	}
+
+	s.Run("Example Bodies Not Chunked", func() {
+		for _, chunk := range chunks {
+			if chunk.StartLine > 7 && chunk.EndLine < 9 {
+				s.Fail("chunk inside an example", chunk.ID())
+			}
+		}
+	})
+}
+
+func (s *RubyParserTestSuite) TestRakefileParsing() {
+	chunks := s.getChunks("ruby/Rakefile")
+
+	chunk, exists := chunks["version"]
+	s.Require().True(exists)
+	s.Equal("method", chunk.Kind)
+	s.Equal("src", chunk.Type)
+}
+
+func (s *RubyParserTestSuite) TestVendoredGemsIgnored() {
+	_, err := s.parser.Chunk("vendor/bundle/ruby/3.3.0/gems/rake-13.2.1/lib/rake.rb")
+	s.ErrorContains(err, "marked as ignore")
+}
+
+func (s *RubyParserTestSuite) TearDownSuite() {
+	if s.parser != nil {
+		s.parser.Close()
+	}
+}
+
+func TestRubyParserTestSuite(t *testing.T) {
+	suite.Run(t, new(RubyParserTestSuite))
+}
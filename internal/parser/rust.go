@@ -0,0 +1,104 @@
+package parser
+
+import (
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+	tree_sitter_rust "github.com/tree-sitter/tree-sitter-rust/bindings/go"
+)
+
+var RustSpec = &LanguageSpec{
+	NamedChunks: map[string]NamedChunkExtractor{
+		"function_item": {
+			NameQuery: `(function_item name: (identifier) @name)`,
+		},
+		"function_signature_item": {
+			NameQuery: `(function_signature_item name: (identifier) @name)`,
+		},
+		"struct_item": {
+			NameQuery: `(struct_item name: (type_identifier) @name)`,
+		},
+		"enum_item": {
+			NameQuery: `(enum_item name: (type_identifier) @name)`,
+		},
+		"union_item": {
+			NameQuery: `(union_item name: (type_identifier) @name)`,
+		},
+		"trait_item": {
+			NameQuery: `(trait_item name: (type_identifier) @name)`,
+		},
+		// Methods are pathed under the implementing type, trait impls included
+		"impl_item": {
+			NameQuery: `
+				(impl_item
+					type: [
+						(type_identifier) @name
+						(generic_type
+							type: (type_identifier) @name)
+						(scoped_type_identifier
+							name: (type_identifier) @name)
+						(generic_type
+							type: (scoped_type_identifier
+								name: (type_identifier) @name))
+						(reference_type
+							type: (type_identifier) @name)])`,
+		},
+		"type_item": {
+			NameQuery: `(type_item name: (type_identifier) @name)`,
+		},
+		"const_item": {
+			NameQuery: `(const_item name: (identifier) @name)`,
+		},
+		"static_item": {
+			NameQuery: `(static_item name: (identifier) @name)`,
+		},
+		"macro_definition": {
+			NameQuery: `(macro_definition name: (identifier) @name)`,
+		},
+		"mod_item": {
+			NameQuery: `(mod_item name: (identifier) @name)`,
+		},
+	},
+	ExtractChildrenIn: []string{
+		"impl_item",
+		"trait_item",
+		"mod_item",
+		"declaration_list",
+	},
+	FoldIntoNextNode: []string{"line_comment", "block_comment", "attribute_item"},
+	SkipTypes: []string{
+		// Imports pollute search results
+		"use_declaration",
+		"extern_crate_declaration",
+		// Crate-level attributes, e.g. #![allow(dead_code)]
+		"inner_attribute_item",
+		// Skip keyword and punctuation tokens of impls, traits and modules
+		"impl", "trait", "mod", "for", "unsafe", "{", "}", ";",
+		// Skip the names, generics and bounds of impls, traits and modules
+		"visibility_modifier",
+		"identifier",
+		"type_identifier",
+		"generic_type",
+		"scoped_type_identifier",
+		"reference_type",
+		"type_parameters",
+		"trait_bounds",
+		"where_clause",
+		// Skip container nodes (but still extract their children)
+		"declaration_list",
+	},
+	FileTypeRules: []FileTypeRule{
+		{Pattern: "target/**", Type: FileTypeIgnore},
+	},
+	TestChunkNames:   []string{"tests"},
+	TestChunkMarkers: []string{"#[cfg(test)]", "#[test]"},
+}
+
+func NewRustParser(workspaceRoot string) (*Parser, error) {
+	parser := tree_sitter.NewParser()
+	parser.SetLanguage(tree_sitter.NewLanguage(tree_sitter_rust.Language()))
+
+	return &Parser{
+		workspaceRoot: workspaceRoot,
+		parser:        parser,
+		spec:          RustSpec,
+	}, nil
+}
@@ -0,0 +1,223 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/st3v3nmw/sourcerer-mcp/internal/parser"
+	"github.com/stretchr/testify/suite"
+)
+
+type RustParserTestSuite struct {
+	ParserBaseTestSuite
+}
+
+func (s *RustParserTestSuite) SetupSuite() {
+	s.ParserBaseTestSuite.SetupSuite()
+
+	var err error
+	s.parser, err = parser.NewRustParser(s.workspaceRoot)
+	s.Require().NoError(err)
+}
+
+func (s *RustParserTestSuite) TestItemParsing() {
+	chunks := s.getChunks("rust/lib.rs")
+
+	tests := []struct {
+		name      string
+		path      string
+		kind      string
+		summary   string
+		startLine int
+		endLine   int
+		fileType  string
+	}{
+		{
+			name:      "Const",
+			path:      "MAX_SIDE",
+			kind:      "const_item",
+			summary:   "pub const MAX_SIDE: f64 = 1000.0;",
+			startLine: 6,
+			endLine:   7,
+			fileType:  "src",
+		},
+		{
+			name:      "Struct With Attributes",
+			path:      "Rectangle",
+			kind:      "struct_item",
+			summary:   "pub struct Rectangle {",
+			startLine: 11,
+			endLine:   16,
+			fileType:  "src",
+		},
+		{
+			name:      "Enum",
+			path:      "Shape",
+			kind:      "enum_item",
+			summary:   "pub enum Shape {",
+			startLine: 18,
+			endLine:   21,
+			fileType:  "src",
+		},
+		{
+			name:      "Type Alias",
+			path:      "Area",
+			kind:      "type_item",
+			summary:   "pub type Area = f64;",
+			startLine: 23,
+			endLine:   23,
+			fileType:  "src",
+		},
+		{
+			name:      "Trait",
+			path:      "HasArea",
+			kind:      "trait_item",
+			summary:   "pub trait HasArea {",
+			startLine: 25,
+			endLine:   32,
+			fileType:  "src",
+		},
+		{
+			name:      "Trait Method Signature",
+			path:      "HasArea::area",
+			kind:      "function_signature_item",
+			summary:   "fn area(&self) -> Area;",
+			startLine: 27,
+			endLine:   27,
+			fileType:  "src",
+		},
+		{
+			name:      "Trait Default Method",
+			path:      "HasArea::describe",
+			kind:      "function_item",
+			summary:   "fn describe(&self) -> String {",
+			startLine: 29,
+			endLine:   31,
+			fileType:  "src",
+		},
+		{
+			name:      "Inherent Impl",
+			path:      "Rectangle-2",
+			kind:      "impl_item",
+			summary:   "impl Rectangle {",
+			startLine: 34,
+			endLine:   43,
+			fileType:  "src",
+		},
+		{
+			name:      "Associated Function",
+			path:      "Rectangle::new",
+			kind:      "function_item",
+			summary:   "pub fn new(width: f64, height: f64) -> Self {",
+			startLine: 35,
+			endLine:   38,
+			fileType:  "src",
+		},
+		{
+			name:      "Trait Impl Method",
+			path:      "Rectangle::area",
+			kind:      "function_item",
+			summary:   "fn area(&self) -> Area {",
+			startLine: 46,
+			endLine:   48,
+			fileType:  "src",
+		},
+		{
+			name:      "Scoped Trait Impl Method",
+			path:      "Rectangle::fmt",
+			kind:      "function_item",
+			summary:   "fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result {",
+			startLine: 52,
+			endLine:   54,
+			fileType:  "src",
+		},
+		{
+			name:      "Generic Impl Method",
+			path:      "Vec::area",
+			kind:      "function_item",
+			summary:   "fn area(&self) -> Area {",
+			startLine: 58,
+			endLine:   60,
+			fileType:  "src",
+		},
+		{
+			name:      "Macro",
+			path:      "square",
+			kind:      "macro_definition",
+			summary:   "macro_rules! square {",
+			startLine: 63,
+			endLine:   67,
+			fileType:  "src",
+		},
+		{
+			name:      "Module Function",
+			path:      "units::to_cm",
+			kind:      "function_item",
+			summary:   "pub fn to_cm(inches: f64) -> f64 {",
+			startLine: 70,
+			endLine:   72,
+			fileType:  "src",
+		},
+		{
+			name:      "Test Module",
+			path:      "tests",
+			kind:      "mod_item",
+			summary:   "mod tests {",
+			startLine: 79,
+			endLine:   87,
+			fileType:  "tests",
+		},
+		{
+			name:      "Test Function",
+			path:      "tests::square_is_square",
+			kind:      "function_item",
+			summary:   "fn square_is_square() {",
+			startLine: 83,
+			endLine:   86,
+			fileType:  "tests",
+		},
+	}
+
+	for _, test := range tests {
+		s.Run(test.name, func() {
+			chunk, exists := chunks[test.path]
+			s.Require().True(exists, "chunk %s not found", test.path)
+			s.Require().NotNil(chunk)
+
+			s.Equal(test.fileType, chunk.Type)
+			s.Equal(test.kind, chunk.Kind)
+			s.Equal(test.summary, chunk.Summary)
+			s.Equal(test.startLine, int(chunk.StartLine))
+			s.Equal(test.endLine, int(chunk.EndLine))
+			s.Equal("rust/lib.rs::"+test.path, chunk.ID())
+		})
+	}
+
+	s.Run("Doc Comments", func() {
+		s.Equal("/// Creates a rectangle.", chunks["Rectangle::new"].Doc)
+		s.Equal(`/// Creates a rectangle.
+    pub fn new(width: f64, height: f64) -> Self {
+        Rectangle { width, height }
+    }`, chunks["Rectangle::new"].Source)
+	})
+
+	s.Run("Imports Skipped", func() {
+		for _, chunk := range chunks {
+			s.NotContains(chunk.Source, "use std::fmt;")
+		}
+	})
+}
+
+func (s *RustParserTestSuite) TestTargetIgnored() {
+	_, err := s.parser.Chunk("target/debug/build/out.rs")
+	s.ErrorContains(err, "marked as ignore")
+}
+
+func (s *RustParserTestSuite) TearDownSuite() {
+	if s.parser != nil {
+		s.parser.Close()
+	}
+}
+
+func TestRustParserTestSuite(t *testing.T) {
+	suite.Run(t, new(RustParserTestSuite))
+}
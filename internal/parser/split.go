@@ -0,0 +1,160 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// maxChunkChars is the largest chunk embedded as a whole, about 1000
+	// tokens, which leaves room for the context the index adds to it
+	maxChunkChars = 4000
+	// chunkOverlapChars is how much source consecutive parts of a split chunk
+	// share, so code near a split point is still embedded with its context
+	chunkOverlapChars = 400
+)
+
+// PartSeparator separates a chunk's path from its part number, e.g.
+// file.go::Func#part2
+const PartSeparator = "#part"
+
+// splitOversized replaces chunks larger than maxChunkChars with overlapping
+// parts of whole lines, or of spans of overlong lines, that each fit the budget
+func splitOversized(chunks []*Chunk) []*Chunk {
+	var result []*Chunk
+	for _, chunk := range chunks {
+		if len(chunk.Source) <= maxChunkChars {
+			result = append(result, chunk)
+			continue
+		}
+
+		result = append(result, splitChunk(chunk)...)
+	}
+
+	return result
+}
+
+// splitChunk breaks a chunk into parts. Every part keeps the chunk's summary
+// so it reads as the chunk it belongs to, and only the first one its doc
+// comments.
+func splitChunk(chunk *Chunk) []*Chunk {
+	// Pieces of overlong lines are small enough for parts to share some
+	pieces := splitLines(chunk.Source, maxChunkChars, chunkOverlapChars/2)
+	windows := lineWindows(pieces, maxChunkChars, chunkOverlapChars)
+
+	parts := make([]*Chunk, 0, len(windows))
+	for i, window := range windows {
+		start, end := window[0], window[1]
+		first, last := pieces[start], pieces[end-1]
+
+		var source strings.Builder
+		for j := start; j < end; j++ {
+			if j > start && pieces[j].line != pieces[j-1].line {
+				source.WriteByte('\n')
+			}
+			source.WriteString(pieces[j].text)
+		}
+
+		part := &Chunk{
+			File:        chunk.File,
+			Type:        chunk.Type,
+			Path:        fmt.Sprintf("%s%s%d", chunk.Path, PartSeparator, i+1),
+			Parent:      chunk.Path,
+			Kind:        chunk.Kind,
+			Summary:     chunk.Summary,
+			Source:      source.String(),
+			StartLine:   chunk.StartLine + uint(first.line),
+			StartColumn: chunk.column(first.line, first.offset),
+			EndLine:     chunk.StartLine + uint(last.line),
+			EndColumn:   chunk.column(last.line, last.offset+len(last.text)),
+			ParsedAt:    chunk.ParsedAt,
+		}
+
+		if start == 0 {
+			part.Doc = chunk.Doc
+			part.CodeStart = min(chunk.CodeStart, uint(len(part.Source)))
+		}
+
+		if end == len(pieces) {
+			part.EndColumn = chunk.EndColumn
+		}
+
+		parts = append(parts, part)
+	}
+
+	return parts
+}
+
+// column returns the column of a byte offset in one of the chunk's lines
+func (c *Chunk) column(line, offset int) uint {
+	if line == 0 {
+		return c.StartColumn + uint(offset)
+	}
+
+	return uint(offset) + 1
+}
+
+// linePiece is a line of a chunk's source, or a span of it if the line is too
+// long to fit in a part
+type linePiece struct {
+	line   int // index of the line in the chunk
+	offset int // byte offset of the piece in the line
+	text   string
+}
+
+// splitLines splits source into lines, and lines longer than budget into
+// pieces of at most size bytes that don't cut through a UTF-8 character, so
+// minified code and long literals still fit in parts
+func splitLines(source string, budget, size int) []linePiece {
+	var pieces []linePiece
+	for i, line := range strings.Split(source, "\n") {
+		if len(line) <= budget {
+			pieces = append(pieces, linePiece{line: i, text: line})
+			continue
+		}
+
+		for offset := 0; offset < len(line); {
+			end := min(offset+size, len(line))
+			for end < len(line) && end > offset+1 && !utf8.RuneStart(line[end]) {
+				end--
+			}
+
+			pieces = append(pieces, linePiece{line: i, offset: offset, text: line[offset:end]})
+			offset = end
+		}
+	}
+
+	return pieces
+}
+
+// lineWindows splits pieces into [start, end) windows of at most budget
+// characters, each starting up to overlap characters before the previous one
+// ended
+func lineWindows(pieces []linePiece, budget, overlap int) [][2]int {
+	var windows [][2]int
+
+	start := 0
+	for start < len(pieces) {
+		end, size := start, 0
+		for end < len(pieces) && (end == start || size+len(pieces[end].text)+1 <= budget) {
+			size += len(pieces[end].text) + 1
+			end++
+		}
+
+		windows = append(windows, [2]int{start, end})
+		if end == len(pieces) {
+			break
+		}
+
+		next, shared := end, 0
+		for next > start+1 && shared+len(pieces[next-1].text)+1 <= overlap {
+			shared += len(pieces[next-1].text) + 1
+			next--
+		}
+
+		start = next
+	}
+
+	return windows
+}
@@ -1380,8 +1380,8 @@ namespace UtilityNamespace {
 			endLine:   40,
 		},
 		{
-			name:    "External Library",
-			path:    "e55a503d7227a55b",
+			name:    "Small Namespaces Merging",
+			path:    "b67907e1964e810d",
 			summary: "declare namespace ExternalLibrary {",
 			source: `// Module declaration (ambient namespace)
 declare namespace ExternalLibrary {
@@ -1390,44 +1390,26 @@ declare namespace ExternalLibrary {
     }
 
     function initialize(options: Options): void;
-}`,
-			startLine: 42,
-			endLine:   49,
-		},
-		{
-			name:    "Global Namespace",
-			path:    "25f16e2c0e5708b4",
-			summary: "declare global {",
-			source: `// Global augmentation
+}
+
+// Global augmentation
 declare global {
     namespace NodeJS {
         interface ProcessEnv {
             CUSTOM_VAR: string;
         }
     }
-}`,
-			startLine: 51,
-			endLine:   58,
-		},
-		{
-			name:    "Merged Namespace 1",
-			path:    "68f0b782ae122c7",
-			summary: "namespace MergedNamespace {",
-			source: `// Namespace merging
+}
+
+// Namespace merging
 namespace MergedNamespace {
     export const first = "first";
-}`,
-			startLine: 60,
-			endLine:   63,
-		},
-		{
-			name:    "Merged Namespace 2",
-			path:    "ba7f8fd919fbf0a",
-			summary: "namespace MergedNamespace {",
-			source: `namespace MergedNamespace {
+}
+
+namespace MergedNamespace {
     export const second = "second";
 }`,
-			startLine: 65,
+			startLine: 42,
 			endLine:   67,
 		},
 		{
@@ -1742,26 +1724,20 @@ const simple_arrow = (): string => "simple";`,
 			endLine:   34,
 		},
 		{
-			name:    "Arrow With Body",
-			path:    "767114ac2a5c8a3f",
-			summary: `const arrow_with_body = (x: number): number => {`,
+			name:    "Small Arrow Functions Merging",
+			path:    "b37b9b94be046465",
+			summary: "const arrow_with_body = (x: number): number => {",
 			source: `const arrow_with_body = (x: number): number => {
     const result: number = x * 2;
     return result;
-};`,
-			startLine: 35,
-			endLine:   38,
-		},
-		{
-			name:    "Async Arrow Func",
-			path:    "b17a6b2599fdca36",
-			summary: `const async_arrow_func = async (data: string): Promise<Response> => {`,
-			source: `// Async arrow function with types
+};
+
+// Async arrow function with types
 const async_arrow_func = async (data: string): Promise<Response> => {
     const response: Response = await fetch(data);
     return response;
 };`,
-			startLine: 40,
+			startLine: 35,
 			endLine:   44,
 		},
 		{
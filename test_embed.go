@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/st3v3nmw/sourcerer-mcp/internal/analyzer"
+	"github.com/st3v3nmw/sourcerer-mcp/internal/index"
 )
 
 func main() {
@@ -17,7 +18,7 @@ func main() {
 	fmt.Println("Now triggering a search to force embedding creation...")
 	
 	// This will actually call the embedding function
-	results, err := a.SemanticSearch(context.Background(), "test query", []string{"memory"})
+	results, err := a.SemanticSearch(context.Background(), "test query", index.SearchOptions{FileTypes: []string{"memory"}})
 	if err != nil {
 		fmt.Printf("Search error: %v\n", err)
 		return
@@ -6,6 +6,7 @@ import (
 	"os"
 
 	"github.com/st3v3nmw/sourcerer-mcp/internal/analyzer"
+	"github.com/st3v3nmw/sourcerer-mcp/internal/index"
 )
 
 func main() {
@@ -20,7 +21,7 @@ func main() {
 	}
 
 	fmt.Println("1. Searching for 'python' in memory files...")
-	memResults, err := a.SemanticSearch(context.Background(), "python", []string{"memory"})
+	memResults, err := a.SemanticSearch(context.Background(), "python", index.SearchOptions{FileTypes: []string{"memory"}})
 	if err != nil {
 		fmt.Printf("   Error: %v\n", err)
 	} else {
@@ -31,7 +32,7 @@ func main() {
 	}
 
 	fmt.Println("\n2. Searching for 'fuzzer' in all types...")
-	allResults, err := a.SemanticSearch(context.Background(), "fuzzer", []string{"src", "docs", "memory"})
+	allResults, err := a.SemanticSearch(context.Background(), "fuzzer", index.SearchOptions{FileTypes: []string{"src", "docs", "memory"}})
 	if err != nil {
 		fmt.Printf("   Error: %v\n", err)
 	} else {
@@ -42,7 +43,7 @@ func main() {
 	}
 
 	fmt.Println("\n3. Searching for 'function' in src files...")
-	srcResults, err := a.SemanticSearch(context.Background(), "function", []string{"src"})
+	srcResults, err := a.SemanticSearch(context.Background(), "function", index.SearchOptions{FileTypes: []string{"src"}})
 	if err != nil {
 		fmt.Printf("   Error: %v\n", err)
 	} else {
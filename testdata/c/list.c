@@ -0,0 +1,26 @@
+#include <stdlib.h>
+#include "list.h"
+
+int list_count = 0;
+
+static int compare(int a, int b) {
+    return a - b;
+}
+
+// Appends a value to the list.
+int list_push(list *l, int value) {
+    struct node *n = malloc(sizeof(*n));
+    if (n == NULL) {
+        return -1;
+    }
+    n->value = value;
+    n->next = l->head;
+    l->head = n;
+    l->len++;
+    return 0;
+}
+
+list *list_new(void) {
+    list_count++;
+    return calloc(1, sizeof(list));
+}
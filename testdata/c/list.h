@@ -0,0 +1,36 @@
+#ifndef LIST_H
+#define LIST_H
+
+#include <stddef.h>
+
+#define LIST_MAX 64
+#define LIST_EMPTY(l) ((l)->len == 0)
+
+/* A node of a singly linked list. */
+struct node {
+    int value;
+    struct node *next;
+};
+
+union number {
+    int i;
+    double d;
+};
+
+enum color { RED, GREEN, BLUE };
+
+typedef struct {
+    struct node *head;
+    size_t len;
+} list;
+
+typedef int (*compare_fn)(int, int);
+
+// Appends a value to the list.
+int list_push(list *l, int value);
+
+list *list_new(void);
+
+extern int list_count;
+
+#endif
@@ -0,0 +1,39 @@
+#include "shapes.hpp"
+
+namespace geo {
+
+Circle::Circle(double radius) : radius_(radius) {}
+
+// Pi times the radius squared.
+double Circle::area() const {
+    return 3.14159 * radius_ * radius_;
+}
+
+double total_area(const std::vector<Shape*>& shapes) {
+    double total = 0;
+    for (auto* shape : shapes) {
+        total += shape->area();
+    }
+    return total;
+}
+
+const Point& origin() {
+    static Point point{0, 0};
+    return point;
+}
+
+Shape* make_circle(double radius) {
+    return new Circle(radius);
+}
+
+}  // namespace geo
+
+namespace {
+
+int helper() { return 1; }
+
+}  // namespace
+
+int main() {
+    return geo::largest(1, helper());
+}
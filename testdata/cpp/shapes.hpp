@@ -0,0 +1,55 @@
+#pragma once
+
+#include <string>
+#include <vector>
+
+namespace geo {
+
+/// A point in the plane.
+struct Point {
+    double x;
+    double y;
+};
+
+// A shape with an area.
+class Shape {
+public:
+    virtual ~Shape() = default;
+    virtual double area() const = 0;
+    std::string name() const { return "shape"; }
+};
+
+class Circle : public Shape {
+public:
+    explicit Circle(double radius);
+    double area() const override;
+
+private:
+    double radius_;
+};
+
+template <typename T>
+class Stack {
+public:
+    void push(const T& value) { items_.push_back(value); }
+
+private:
+    std::vector<T> items_;
+};
+
+template <typename T>
+T largest(const T& a, const T& b) {
+    return a > b ? a : b;
+}
+
+using Points = std::vector<Point>;
+
+enum class Unit { Meters, Feet };
+
+double total_area(const std::vector<Shape*>& shapes);
+
+const Point& origin();
+
+Shape* make_circle(double radius);
+
+}  // namespace geo
@@ -0,0 +1,15 @@
+using Library.Models;
+using Xunit;
+
+namespace Library.Tests;
+
+public class BookTests
+{
+    [Fact]
+    public void CheckoutMarksBookUnavailable()
+    {
+        var book = new Book("Dune");
+        book.Checkout("ann");
+        Assert.False(book.Available);
+    }
+}
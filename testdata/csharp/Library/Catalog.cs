@@ -0,0 +1,14 @@
+namespace Library
+{
+    using Library.Models;
+
+    public static class Catalog
+    {
+        public static Book Create(string title) => new(title);
+    }
+
+    namespace Internal
+    {
+        internal delegate void Notify(string message);
+    }
+}
@@ -0,0 +1,68 @@
+using System;
+using System.Collections.Generic;
+
+namespace Library.Models;
+
+/// <summary>
+/// A book on the shelf.
+/// </summary>
+[Serializable]
+public class Book : IComparable<Book>
+{
+    public const int MaxTitleLength = 200;
+
+    private readonly List<string> _authors = new();
+
+    /// <summary>Creates a book.</summary>
+    public Book(string title)
+    {
+        Title = title;
+    }
+
+    public string Title { get; set; }
+
+    public bool Available { get; private set; } = true;
+
+    public event EventHandler? CheckedOut;
+
+    /// <summary>Marks the book as checked out.</summary>
+    /// <param name="patron">Who borrowed it.</param>
+    public void Checkout(string patron)
+    {
+        Available = false;
+        CheckedOut?.Invoke(this, EventArgs.Empty);
+    }
+
+    public int CompareTo(Book? other) => string.Compare(Title, other?.Title);
+
+    public class Builder
+    {
+        public Book Build() => new("untitled");
+    }
+}
+
+public record Patron(string Name, int Loans);
+
+public struct ShelfLocation
+{
+    public int Row;
+    public int Column;
+}
+
+public interface ICatalog
+{
+    Book? Find(string title);
+}
+
+public enum Genre
+{
+    Fiction,
+    NonFiction,
+}
+
+public class Order
+{
+    private int quantity, total;
+
+    public event EventHandler? Placed, Shipped;
+}
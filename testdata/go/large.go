@@ -0,0 +1,133 @@
+package testdata
+
+import "fmt"
+
+// Large builds a report long enough to be split into parts
+func Large() []string {
+	report := []string{}
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 1, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 2, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 3, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 4, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 5, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 6, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 7, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 8, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 9, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 10, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 11, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 12, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 13, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 14, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 15, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 16, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 17, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 18, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 19, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 20, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 21, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 22, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 23, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 24, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 25, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 26, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 27, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 28, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 29, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 30, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 31, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 32, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 33, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 34, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 35, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 36, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 37, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 38, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 39, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 40, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 41, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 42, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 43, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 44, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 45, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 46, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 47, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 48, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 49, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 50, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 51, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 52, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 53, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 54, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 55, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 56, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 57, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 58, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 59, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 60, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 61, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 62, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 63, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 64, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 65, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 66, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 67, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 68, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 69, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 70, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 71, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 72, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 73, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 74, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 75, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 76, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 77, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 78, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 79, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 80, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 81, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 82, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 83, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 84, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 85, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 86, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 87, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 88, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 89, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 90, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 91, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 92, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 93, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 94, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 95, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 96, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 97, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 98, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 99, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 100, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 101, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 102, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 103, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 104, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 105, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 106, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 107, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 108, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 109, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 110, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 111, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 112, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 113, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 114, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 115, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 116, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 117, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 118, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 119, "lorem ipsum"))
+	report = append(report, fmt.Sprintf("line %03d of the generated report: %s", 120, "lorem ipsum"))
+
+	return report
+}
+
+// Small stays a single chunk
+func Small() {}
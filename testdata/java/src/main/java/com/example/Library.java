@@ -0,0 +1,84 @@
+package com.example;
+
+import java.util.ArrayList;
+import java.util.List;
+
+/**
+ * A library of books.
+ */
+public class Library {
+    /** Most books a library holds. */
+    public static final int CAPACITY = 100;
+
+    private final List<Book> books = new ArrayList<>();
+
+    /**
+     * Creates an empty library.
+     */
+    public Library() {
+    }
+
+    // Adds a book unless the library is full
+    @Override
+    public boolean add(Book book) {
+        if (books.size() >= CAPACITY) {
+            return false;
+        }
+        return books.add(book);
+    }
+
+    public Runnable cataloguer() {
+        return new Runnable() {
+            @Override
+            public void run() {
+                books.sort(null);
+            }
+        };
+    }
+
+    /** A book on a shelf. */
+    public static class Book {
+        private final String title;
+
+        public Book(String title) {
+            this.title = title;
+        }
+
+        public String getTitle() {
+            return title;
+        }
+    }
+}
+
+interface Shelf {
+    int SIZE = 10;
+
+    void place(Library.Book book);
+}
+
+enum Genre {
+    FICTION,
+    HISTORY;
+
+    public String label() {
+        return name().toLowerCase();
+    }
+}
+
+record Loan(String member, Library.Book book) {
+    Loan {
+        java.util.Objects.requireNonNull(member);
+    }
+}
+
+@interface Catalogued {
+    String section() default "general";
+}
+
+class Order {
+    private int quantity, total;
+}
+
+interface Limits {
+    int MIN = 1, MAX = 10;
+}
@@ -0,0 +1,11 @@
+package com.example;
+
+import org.junit.jupiter.api.Test;
+
+class LibraryTest {
+    @Test
+    void addsBooks() {
+        Library library = new Library();
+        assert library.add(new Library.Book("Dune"));
+    }
+}
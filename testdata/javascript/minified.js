@@ -0,0 +1,2 @@
+// Lookup table generated at build time
+function lookupTable() { return [0,7919,15838,23757,31676,39595,47514,55433,63352,71271,79190,87109,95028,2947,10866,18785,26704,34623,42542,50461,58380,66299,74218,82137,90056,97975,5894,13813,21732,29651,37570,45489,53408,61327,69246,77165,85084,93003,922,8841,16760,24679,32598,40517,48436,56355,64274,72193,80112,88031,95950,3869,11788,19707,27626,35545,43464,51383,59302,67221,75140,83059,90978,98897,6816,14735,22654,30573,38492,46411,54330,62249,70168,78087,86006,93925,1844,9763,17682,25601,33520,41439,49358,57277,65196,73115,81034,88953,96872,4791,12710,20629,28548,36467,44386,52305,60224,68143,76062,83981,91900,99819,7738,15657,23576,31495,39414,47333,55252,63171,71090,79009,86928,94847,2766,10685,18604,26523,34442,42361,50280,58199,66118,74037,81956,89875,97794,5713,13632,21551,29470,37389,45308,53227,61146,69065,76984,84903,92822,741,8660,16579,24498,32417,40336,48255,56174,64093,72012,79931,87850,95769,3688,11607,19526,27445,35364,43283,51202,59121,67040,74959,82878,90797,98716,6635,14554,22473,30392,38311,46230,54149,62068,69987,77906,85825,93744,1663,9582,17501,25420,33339,41258,49177,57096,65015,72934,80853,88772,96691,4610,12529,20448,28367,36286,44205,52124,60043,67962,75881,83800,91719,99638,7557,15476,23395,31314,39233,47152,55071,62990,70909,78828,86747,94666,2585,10504,18423,26342,34261,42180,50099,58018,65937,73856,81775,89694,97613,5532,13451,21370,29289,37208,45127,53046,60965,68884,76803,84722,92641,560,8479,16398,24317,32236,40155,48074,55993,63912,71831,79750,87669,95588,3507,11426,19345,27264,35183,43102,51021,58940,66859,74778,82697,90616,98535,6454,14373,22292,30211,38130,46049,53968,61887,69806,77725,85644,93563,1482,9401,17320,25239,33158,41077,48996,56915,64834,72753,80672,88591,96510,4429,12348,20267,28186,36105,44024,51943,59862,67781,75700,83619,91538,99457,7376,15295,23214,31133,39052,46971,54890,62809,70728,78647,86566,94485,2404,10323,18242,26161,34080,41999,49918,57837,65756,73675,81594,89513,97432,5351,13270,21189,29108,37027,44946,52865,60784,68703,76622,84541,92460,379,8298,16217,24136,32055,39974,47893,55812,63731,71650,79569,87488,95407,3326,11245,19164,27083,35002,42921,50840,58759,66678,74597,82516,90435,98354,6273,14192,22111,30030,37949,45868,53787,61706,69625,77544,85463,93382,1301,9220,17139,25058,32977,40896,48815,56734,64653,72572,80491,88410,96329,4248,12167,20086,28005,35924,43843,51762,59681,67600,75519,83438,91357,99276,7195,15114,23033,30952,38871,46790,54709,62628,70547,78466,86385,94304,2223,10142,18061,25980,33899,41818,49737,57656,65575,73494,81413,89332,97251,5170,13089,21008,28927,36846,44765,52684,60603,68522,76441,84360,92279,198,8117,16036,23955,31874,39793,47712,55631,63550,71469,79388,87307,95226,3145,11064,18983,26902,34821,42740,50659,58578,66497,74416,82335,90254,98173,6092,14011,21930,29849,37768,45687,53606,61525,69444,77363,85282,93201,1120,9039,16958,24877,32796,40715,48634,56553,64472,72391,80310,88229,96148,4067,11986,19905,27824,35743,43662,51581,59500,67419,75338,83257,91176,99095,7014,14933,22852,30771,38690,46609,54528,62447,70366,78285,86204,94123,2042,9961,17880,25799,33718,41637,49556,57475,65394,73313,81232,89151,97070,4989,12908,20827,28746,36665,44584,52503,60422,68341,76260,84179,92098,17,7936,15855,23774,31693,39612,47531,55450,63369,71288,79207,87126,95045,2964,10883,18802,26721,34640,42559,50478,58397,66316,74235,82154,90073,97992,5911,13830,21749,29668,37587,45506,53425,61344,69263,77182,85101,93020,939,8858,16777,24696,32615,40534,48453,56372,64291,72210,80129,88048,95967,3886,11805,19724,27643,35562,43481,51400,59319,67238,75157,83076,90995,98914,6833,14752,22671,30590,38509,46428,54347,62266,70185,78104,86023,93942,1861,9780,17699,25618,33537,41456,49375,57294,65213,73132,81051,88970,96889,4808,12727,20646,28565,36484,44403,52322,60241,68160,76079,83998,91917,99836,7755,15674,23593,31512,39431,47350,55269,63188,71107,79026,86945,94864,2783,10702,18621,26540,34459,42378,50297,58216,66135,74054,81973,89892,97811,5730,13649,21568,29487,37406,45325,53244,61163,69082,77001,84920,92839,758,8677,16596,24515,32434,40353,48272,56191,64110,72029,79948,87867,95786,3705,11624,19543,27462,35381,43300,51219,59138,67057,74976,82895,90814,98733,6652,14571,22490,30409,38328,46247,54166,62085,70004,77923,85842,93761,1680,9599,17518,25437,33356,41275,49194,57113,65032,72951,80870,88789,96708,4627,12546,20465,28384,36303,44222,52141,60060,67979,75898,83817,91736,99655,7574,15493,23412,31331,39250,47169,55088,63007,70926,78845,86764,94683,2602,10521,18440,26359,34278,42197,50116,58035,65954,73873,81792,89711,97630,5549,13468,21387,29306,37225,45144,53063,60982,68901,76820,84739,92658,577,8496,16415,24334,32253,40172,48091,56010,63929,71848,79767,87686,95605,3524,11443,19362,27281,35200,43119,51038,58957,66876,74795,82714,90633,98552,6471,14390,22309,30228,38147,46066,53985,61904,69823,77742,85661,93580,1499,9418,17337,25256,33175,41094,49013,56932,64851,72770,80689,88608,96527,4446,12365,20284,28203,36122,44041,51960,59879,67798,75717,83636,91555,99474,7393,15312,23231,31150,39069,46988,54907,62826,70745,78664,86583,94502,2421,10340,18259,26178,34097,42016,49935,57854,65773,73692,81611,89530,97449,5368,13287,21206,29125,37044,44963,52882,60801,68720,76639,84558,92477,396,8315,16234,24153,32072,39991,47910,55829,63748,71667,79586,87505,95424,3343,11262,19181,27100,35019,42938,50857,58776,66695,74614,82533,90452,98371,6290,14209,22128,30047,37966,45885,53804,61723,69642,77561,85480,93399,1318,9237,17156,25075,32994,40913,48832,56751,64670,72589,80508,88427,96346,4265,12184,20103,28022,35941,43860,51779,59698,67617,75536,83455,91374,99293,7212,15131,23050,30969,38888,46807,54726,62645,70564,78483,86402,94321,2240,10159,18078,25997,33916,41835,49754,57673,65592,73511,81430,89349,97268,5187,13106,21025,28944,36863,44782,52701,60620,68539,76458,84377,92296,215,8134,16053,23972,31891,39810,47729,55648,63567,71486,79405,87324,95243,3162,11081,19000,26919,34838,42757,50676,58595,66514,74433,82352,90271,98190,6109,14028,21947,29866,37785,45704,53623,61542,69461,77380,85299,93218,1137,9056,16975,24894,32813,40732,48651,56570,64489,72408,80327,88246,96165,4084,12003,19922,27841,35760,43679,51598,59517,67436,75355,83274,91193,99112,7031,14950,22869,30788,38707,46626,54545,62464,70383,78302,86221,94140,2059,9978,17897,25816,33735,41654,49573,57492,65411,73330,81249,89168,97087,5006,12925,20844,28763,36682,44601,52520,60439,68358,76277,84196,92115,34,7953,15872,23791,31710,39629,47548,55467,63386,71305,79224,87143,95062,2981,10900,18819,26738,34657,42576,50495,58414,66333,74252,82171,90090,98009,5928,13847,21766,29685,37604,45523,53442,61361,69280,77199,85118,93037,956,8875,16794,24713,32632,40551,48470,56389,64308,72227,80146,88065,95984,3903,11822,19741,27660,35579,43498,51417,59336,67255,75174,83093,91012,98931,6850,14769,22688,30607,38526,46445,54364,62283,70202,78121,86040,93959,1878,9797,17716,25635,33554,41473,49392,57311,65230,73149,81068,88987,96906,4825,12744,20663,28582,36501,44420,52339,60258,68177,76096,84015,91934,99853,7772,15691,23610,31529,39448,47367,55286,63205,71124,79043,86962,94881,2800,10719,18638,26557,34476,42395,50314,58233,66152,74071,81990,89909,97828,5747,13666,21585,29504,37423,45342,53261,61180,69099,77018,84937,92856,775,8694,16613,24532,32451,40370,48289,56208,64127,72046,79965,87884,95803,3722,11641,19560,27479,35398,43317,51236,59155,67074,74993,82912,90831,98750,6669,14588,22507,30426,38345,46264,54183,62102,70021,77940,85859,93778,1697,9616,17535,25454,33373,41292,49211,57130,65049,72968,80887,88806,96725,4644,12563,20482,28401,36320,44239,52158,60077,67996,75915,83834,91753,99672,7591,15510,23429,31348,39267,47186,55105,63024,70943,78862,86781,94700,2619,10538,18457,26376,34295,42214,50133,58052,65971,73890,81809,89728,97647,5566,13485,21404,29323,37242,45161,53080,60999,68918,76837,84756,92675,594,8513,16432,24351,32270,40189,48108,56027,63946,71865,79784,87703,95622,3541,11460,19379,27298,35217,43136,51055,58974,66893,74812,82731,90650,98569,6488,14407,22326,30245,38164,46083,54002,61921,69840,77759,85678,93597,1516,9435,17354,25273,33192,41111,49030,56949,64868,72787,80706,88625,96544,4463,12382,20301,28220,36139,44058,51977,59896,67815,75734,83653,91572,99491,7410,15329,23248,31167,39086,47005,54924,62843,70762,78681,86600,94519,2438,10357,18276,26195,34114,42033,49952,57871,65790,73709,81628,89547,97466,5385,13304,21223,29142,37061,44980,52899,60818,68737,76656,84575,92494,413,8332,16251,24170,32089,40008,47927,55846,63765,71684,79603,87522,95441,3360,11279,19198,27117,35036,42955,50874,58793,66712,74631,82550,90469,98388,6307,14226,22145,30064,37983,45902,53821,61740,69659,77578,85497,93416,1335,9254,17173,25092,33011,40930,48849,56768,64687,72606,80525,88444,96363,4282,12201,20120,28039,35958,43877,51796,59715,67634,75553,83472,91391,99310,7229,15148,23067,30986,38905,46824,54743,62662,70581,78500,86419,94338,2257,10176,18095,26014,33933,41852,49771,57690,65609,73528,81447,89366,97285,5204,13123,21042,28961,36880,44799,52718,60637,68556,76475,84394,92313,232,8151,16070,23989,31908,39827,47746,55665,63584,71503,79422,87341,95260,3179,11098,19017,26936,34855,42774,50693,58612,66531,74450,82369,90288,98207,6126,14045,21964,29883,37802,45721,53640,61559,69478,77397,85316,93235,1154,9073,16992,24911,32830,40749,48668,56587,64506,72425,80344,88263,96182,4101,12020,19939,27858,35777,43696,51615,59534,67453,75372,83291,91210,99129,7048,14967,22886,30805,38724,46643,54562,62481,70400,78319,86238,94157,2076,9995,17914,25833,33752,41671,49590,57509,65428,73347,81266,89185,97104,5023,12942,20861,28780,36699,44618,52537,60456,68375,76294,84213,92132,51,7970,15889,23808,31727,39646,47565,55484,63403,71322,79241,87160,95079,2998,10917,18836,26755,34674,42593,50512,58431,66350,74269,82188,90107,98026,5945,13864,21783,29702,37621,45540,53459,61378,69297,77216,85135,93054,973,8892,16811,24730,32649,40568,48487,56406,64325,72244,80163,88082,96001,3920,11839,19758,27677,35596,43515,51434,59353,67272,75191,83110,91029,98948,6867,14786,22705,30624,38543,46462,54381]; }
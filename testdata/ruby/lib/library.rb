@@ -0,0 +1,70 @@
+require "set"
+
+# Books and the patrons borrowing them.
+module Library
+  MAX_LOANS = 5
+
+  # A book on the shelf.
+  class Book
+    include Comparable
+
+    attr_reader :title, :author
+    attr_accessor :available
+
+    def initialize(title, author)
+      @title = title
+      @author = author
+      @available = true
+    end
+
+    # Finds a book by its title.
+    def self.find(title)
+      catalog.detect { |book| book.title == title }
+    end
+
+    def <=>(other)
+      title <=> other.title
+    end
+
+    def available?
+      @available
+    end
+
+    private
+
+    def reset!
+      @available = true
+    end
+  end
+
+  class Patron < Struct.new(:name)
+    class << self
+      def anonymous
+        new("anonymous")
+      end
+    end
+
+    def checkout(book)
+      book.available = false
+    end
+  end
+
+  module Loans::Policy
+    def self.limit
+      MAX_LOANS
+    end
+  end
+end
+
+class Catalog
+  attr_reader :books
+  attr_reader :shelves
+
+  def self.search(query)
+    new.search(query)
+  end
+
+  def search(query)
+    books.select { |book| book.title.include?(query) }
+  end
+end
@@ -0,0 +1,18 @@
+require "library"
+
+RSpec.describe Library::Book do
+  let(:book) { Library::Book.new("Dune", "Herbert") }
+
+  describe "#available?" do
+    it "is true for new books" do
+      expect(book.available?).to be(true)
+    end
+
+    context "when checked out" do
+      it "is false" do
+        Library::Patron.new("ann").checkout(book)
+        expect(book.available?).to be(false)
+      end
+    end
+  end
+end
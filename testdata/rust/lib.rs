@@ -0,0 +1,87 @@
+//! Shapes and their areas.
+#![allow(dead_code)]
+
+use std::fmt;
+
+/// Largest side a shape can have.
+pub const MAX_SIDE: f64 = 1000.0;
+
+static mut COUNTER: u32 = 0;
+
+/// A rectangle.
+#[derive(Debug, Clone)]
+pub struct Rectangle {
+    pub width: f64,
+    pub height: f64,
+}
+
+pub enum Shape {
+    Circle(f64),
+    Square(f64),
+}
+
+pub type Area = f64;
+
+/// Something with an area.
+pub trait HasArea {
+    fn area(&self) -> Area;
+
+    fn describe(&self) -> String {
+        format!("area {}", self.area())
+    }
+}
+
+impl Rectangle {
+    /// Creates a rectangle.
+    pub fn new(width: f64, height: f64) -> Self {
+        Rectangle { width, height }
+    }
+
+    pub fn is_square(&self) -> bool {
+        self.width == self.height
+    }
+}
+
+impl HasArea for Rectangle {
+    fn area(&self) -> Area {
+        self.width * self.height
+    }
+}
+
+impl fmt::Display for Rectangle {
+    fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result {
+        write!(f, "{}x{}", self.width, self.height)
+    }
+}
+
+impl<T: HasArea> HasArea for Vec<T> {
+    fn area(&self) -> Area {
+        self.iter().map(|s| s.area()).sum()
+    }
+}
+
+macro_rules! square {
+    ($side:expr) => {
+        Rectangle::new($side, $side)
+    };
+}
+
+pub mod units {
+    pub fn to_cm(inches: f64) -> f64 {
+        inches * 2.54
+    }
+}
+
+pub fn total_area(shapes: &[Box<dyn HasArea>]) -> Area {
+    shapes.iter().map(|s| s.area()).sum()
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn square_is_square() {
+        assert!(square!(2.0).is_square());
+    }
+}